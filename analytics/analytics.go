@@ -0,0 +1,282 @@
+package analytics
+
+import (
+	"ares/model"
+	"ares/units"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Interval string
+
+const (
+	DAY   Interval = "DAY"
+	WEEK  Interval = "WEEK"
+	MONTH Interval = "MONTH"
+)
+
+// Volume is the training volume of a single period. Tonnage is stored in
+// kilograms, distance in meters and time in milliseconds
+type Volume struct {
+	Period   time.Time `json:"period" bson:"period"`
+	Sessions int       `json:"sessions" bson:"sessions"`
+	Sets     int       `json:"sets" bson:"sets"`
+	Reps     int       `json:"reps" bson:"reps"`
+	Tonnage  float64   `json:"tonnage" bson:"tonnage"`
+	Distance float64   `json:"distance" bson:"distance"`
+	Time     int64     `json:"time" bson:"time"`
+}
+
+// MuscleGroupVolume is the number of working sets and tonnage attributed to a
+// muscle group. Exercises without a matching exercise info document are
+// grouped under UNCATEGORIZED
+type MuscleGroupVolume struct {
+	MuscleGroup string  `json:"muscleGroup" bson:"muscleGroup"`
+	Sets        int     `json:"sets" bson:"sets"`
+	Tonnage     float64 `json:"tonnage" bson:"tonnage"`
+}
+
+// Frequency is the number of sessions completed in a single period
+type Frequency struct {
+	Period   time.Time `json:"period" bson:"period"`
+	Sessions int       `json:"sessions" bson:"sessions"`
+}
+
+// Streak is the number of consecutive periods with at least one session
+type Streak struct {
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+}
+
+// Progression is the best and total performance of a single exercise within
+// one session
+type Progression struct {
+	Session            primitive.ObjectID `json:"session" bson:"session"`
+	Timestamp          time.Time          `json:"timestamp" bson:"timestamp"`
+	Sets               int                `json:"sets" bson:"sets"`
+	Reps               int                `json:"reps" bson:"reps"`
+	MaxReps            int                `json:"maxReps" bson:"maxReps"`
+	TopWeight          float64            `json:"topWeight" bson:"topWeight"`
+	EstimatedOneRepMax float64            `json:"estimatedOneRepMax" bson:"estimatedOneRepMax"`
+	Tonnage            float64            `json:"tonnage" bson:"tonnage"`
+	Distance           float64            `json:"distance" bson:"distance"`
+	Time               int64              `json:"time" bson:"time"`
+}
+
+// pounds and miles convert the kilograms and meters returned by the
+// pipelines for accounts that prefer imperial units
+func pounds(kilograms float64) float64 {
+	return units.Round(units.FromKilograms(kilograms, model.IMPERIAL))
+}
+
+func miles(meters float64) float64 {
+	return units.Round(units.FromMeters(meters, model.MILE))
+}
+
+// Convert returns the volume in the provided system, imperial volumes are in
+// pounds and miles
+func (volume Volume) Convert(system model.MeasurementSystem) Volume {
+	if system == model.IMPERIAL {
+		volume.Tonnage = pounds(volume.Tonnage)
+		volume.Distance = miles(volume.Distance)
+	}
+
+	return volume
+}
+
+// Convert returns the muscle group volume in the provided system
+func (volume MuscleGroupVolume) Convert(system model.MeasurementSystem) MuscleGroupVolume {
+	if system == model.IMPERIAL {
+		volume.Tonnage = pounds(volume.Tonnage)
+	}
+
+	return volume
+}
+
+// Convert returns the progression in the provided system, imperial
+// progressions are in pounds and miles
+func (progression Progression) Convert(system model.MeasurementSystem) Progression {
+	if system == model.IMPERIAL {
+		progression.TopWeight = pounds(progression.TopWeight)
+		progression.EstimatedOneRepMax = pounds(progression.EstimatedOneRepMax)
+		progression.Tonnage = pounds(progression.Tonnage)
+		progression.Distance = miles(progression.Distance)
+	}
+
+	return progression
+}
+
+// VolumePipeline builds the pipeline returning a Volume for every period in
+// which the account trained
+func VolumePipeline(query Query, interval Interval) bson.A {
+	pipeline := bson.A{matchSessions(query)}
+	for _, stage := range flattenSets() {
+		pipeline = append(pipeline, stage)
+	}
+
+	return append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":      periodStart(interval, query.Timezone),
+			"sessions": bson.M{"$addToSet": "$session"},
+			"sets":     bson.M{"$sum": 1},
+			"reps":     bson.M{"$sum": bson.M{"$ifNull": bson.A{"$set.reps", 0}}},
+			"tonnage":  bson.M{"$sum": tonnage()},
+			"distance": bson.M{"$sum": meters()},
+			"time":     bson.M{"$sum": bson.M{"$ifNull": bson.A{"$set.time.timeValue", 0}}},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":      0,
+			"period":   "$_id",
+			"sessions": bson.M{"$size": "$sessions"},
+			"sets":     1,
+			"reps":     1,
+			"tonnage":  1,
+			"distance": 1,
+			"time":     1,
+		}}},
+		bson.D{{Key: "$sort", Value: bson.M{"period": 1}}},
+	)
+}
+
+// MuscleGroupPipeline builds the pipeline returning a MuscleGroupVolume for
+// every muscle group trained, joined through the exercise info collection
+func MuscleGroupPipeline(query Query, exerciseInfoCollection string) bson.A {
+	pipeline := bson.A{matchSessions(query)}
+	for _, stage := range flattenSets() {
+		pipeline = append(pipeline, stage)
+	}
+
+	return append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":     "$exerciseName",
+			"sets":    bson.M{"$sum": 1},
+			"tonnage": bson.M{"$sum": tonnage()},
+		}}},
+		bson.D{{Key: "$lookup", Value: bson.M{
+			"from":         exerciseInfoCollection,
+			"localField":   "_id",
+			"foreignField": "name",
+			"as":           "info",
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"sets":         1,
+			"tonnage":      1,
+			"muscleGroups": bson.M{"$arrayElemAt": bson.A{"$info.muscleGroups", 0}},
+		}}},
+		bson.D{{Key: "$unwind", Value: bson.M{"path": "$muscleGroups", "preserveNullAndEmptyArrays": true}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":     bson.M{"$ifNull": bson.A{"$muscleGroups", "UNCATEGORIZED"}},
+			"sets":    bson.M{"$sum": "$sets"},
+			"tonnage": bson.M{"$sum": "$tonnage"},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":         0,
+			"muscleGroup": "$_id",
+			"sets":        1,
+			"tonnage":     1,
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "sets", Value: -1}, {Key: "muscleGroup", Value: 1}}}},
+	)
+}
+
+// FrequencyPipeline builds the pipeline returning a Frequency for every period
+// in which the account trained
+func FrequencyPipeline(query Query, interval Interval) bson.A {
+	return bson.A{
+		matchSessions(query),
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":      periodStart(interval, query.Timezone),
+			"sessions": bson.M{"$sum": 1},
+		}}},
+		bson.D{{Key: "$project", Value: bson.M{
+			"_id":      0,
+			"period":   "$_id",
+			"sessions": 1,
+		}}},
+		bson.D{{Key: "$sort", Value: bson.M{"period": 1}}},
+	}
+}
+
+// ProgressionPipeline builds the pipeline returning a Progression for every
+// session in which the exercise was performed
+func ProgressionPipeline(query Query, exerciseName string) bson.A {
+	pipeline := bson.A{
+		matchSessions(query),
+		bson.D{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"exercises.exerciseName": exerciseName},
+			bson.M{"exercises.additionalExercises.exerciseName": exerciseName},
+		}}}},
+	}
+
+	for _, stage := range flattenSets() {
+		pipeline = append(pipeline, stage)
+	}
+
+	return append(pipeline,
+		bson.D{{Key: "$match", Value: bson.M{"exerciseName": exerciseName}}},
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":                "$session",
+			"timestamp":          bson.M{"$first": "$timestamp"},
+			"sets":               bson.M{"$sum": 1},
+			"reps":               bson.M{"$sum": bson.M{"$ifNull": bson.A{"$set.reps", 0}}},
+			"maxReps":            bson.M{"$max": bson.M{"$ifNull": bson.A{"$set.reps", 0}}},
+			"topWeight":          bson.M{"$max": kilograms()},
+			"estimatedOneRepMax": bson.M{"$max": estimatedOneRepMax()},
+			"tonnage":            bson.M{"$sum": tonnage()},
+			"distance":           bson.M{"$sum": meters()},
+			"time":               bson.M{"$sum": bson.M{"$ifNull": bson.A{"$set.time.timeValue", 0}}},
+		}}},
+		bson.D{{Key: "$addFields", Value: bson.M{"session": "$_id"}}},
+		bson.D{{Key: "$project", Value: bson.M{"_id": 0}}},
+		bson.D{{Key: "$sort", Value: bson.M{"timestamp": 1}}},
+	)
+}
+
+// next returns the start of the period following the provided period start.
+// The period has to be in the location it was computed in, so days are
+// stepped across daylight saving changes
+func next(period time.Time, interval Interval) time.Time {
+	switch interval {
+	case WEEK:
+		return period.AddDate(0, 0, 7)
+	case MONTH:
+		return period.AddDate(0, 1, 0)
+	}
+
+	return period.AddDate(0, 0, 1)
+}
+
+// Streaks computes the current and longest run of consecutive periods with at
+// least one session. The frequencies must be sorted by period, as returned by
+// FrequencyPipeline, with periods computed in the provided location. The
+// current streak is still considered active if the latest session happened in
+// the period before the one containing now
+func Streaks(frequencies []Frequency, interval Interval, location *time.Location, now time.Time) Streak {
+	var streak Streak
+
+	run := 0
+	for i, frequency := range frequencies {
+		if i > 0 && next(frequencies[i-1].Period.In(location), interval).Equal(frequency.Period) {
+			run++
+		} else {
+			run = 1
+		}
+
+		if run > streak.Longest {
+			streak.Longest = run
+		}
+	}
+
+	if len(frequencies) == 0 {
+		return streak
+	}
+
+	latest := frequencies[len(frequencies)-1].Period.In(location)
+	if now.Before(next(next(latest, interval), interval)) {
+		streak.Current = run
+	}
+
+	return streak
+}
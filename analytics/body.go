@@ -0,0 +1,160 @@
+package analytics
+
+import (
+	"ares/model"
+	"ares/units"
+	"math"
+	"sort"
+	"time"
+)
+
+// startOfDay returns midnight of the day the time falls on in the location
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// startOfWeek returns midnight of the Monday starting the ISO week of the day
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// BodyTrend calculates the trend of a metric from body measurements stored in
+// kilograms and centimeters, returning values in the provided system. Days
+// with several measurements use their average and the moving average covers
+// the days with measurements in the window of days ending on each day.
+// Measurements before from only count towards the moving average and
+// measurements without the metric are ignored
+func BodyTrend(
+	measurements []model.BodyMeasurement,
+	metric model.BodyMetric,
+	window int,
+	from time.Time,
+	system model.MeasurementSystem,
+	loc *time.Location,
+) model.BodyTrend {
+	trend := model.BodyTrend{
+		Metric: metric,
+		Window: window,
+		Points: []model.BodyTrendPoint{},
+		Weeks:  []model.BodyTrendWeek{},
+	}
+
+	totals := map[time.Time]float64{}
+	counts := map[time.Time]int{}
+	var days []time.Time
+
+	for _, measurement := range measurements {
+		value, _ := measurement.Value(metric)
+		if value == 0 {
+			continue
+		}
+
+		day := startOfDay(measurement.MeasuredAt, loc)
+		if counts[day] == 0 {
+			days = append(days, day)
+		}
+
+		totals[day] += units.ConvertBodyMetric(value, metric, system)
+		counts[day]++
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	values := make([]float64, len(days))
+	for i, day := range days {
+		values[i] = totals[day] / float64(counts[day])
+	}
+
+	first := len(days)
+
+	for i, day := range days {
+		if day.Before(from) {
+			continue
+		}
+
+		if first == len(days) {
+			first = i
+		}
+
+		windowStart := day.AddDate(0, 0, -window)
+
+		var sum float64
+		var n int
+		for j := i; j >= 0 && days[j].After(windowStart); j-- {
+			sum += values[j]
+			n++
+		}
+
+		trend.Points = append(trend.Points, model.BodyTrendPoint{
+			Date:          day,
+			Value:         units.Round(values[i]),
+			MovingAverage: units.Round(sum / float64(n)),
+		})
+	}
+
+	days, values = days[first:], values[first:]
+
+	var weekTotal float64
+	var weekCount int
+
+	closeWeek := func(week time.Time) {
+		average := weekTotal / float64(weekCount)
+		entry := model.BodyTrendWeek{Week: week, Average: units.Round(average)}
+
+		if len(trend.Weeks) > 0 {
+			previous := trend.Weeks[len(trend.Weeks)-1]
+			weeks := math.Round(week.Sub(previous.Week).Hours() / (24 * 7))
+			entry.Change = units.Round((average - previous.Average) / weeks)
+		}
+
+		trend.Weeks = append(trend.Weeks, entry)
+	}
+
+	for i, day := range days {
+		week := startOfWeek(day)
+
+		if i > 0 && !startOfWeek(days[i-1]).Equal(week) {
+			closeWeek(startOfWeek(days[i-1]))
+			weekTotal, weekCount = 0, 0
+		}
+
+		weekTotal += values[i]
+		weekCount++
+
+		if i == len(days)-1 {
+			closeWeek(week)
+		}
+	}
+
+	trend.RatePerWeek = units.Round(slope(days, values) * 7)
+
+	return trend
+}
+
+// slope returns the change per day of the least squares line through the
+// daily values, or zero with fewer than two days
+func slope(days []time.Time, values []float64) float64 {
+	if len(days) < 2 {
+		return 0
+	}
+
+	var sumX, sumY, sumXY, sumXX float64
+	n := float64(len(days))
+
+	for i, day := range days {
+		x := day.Sub(days[0]).Hours() / 24
+		sumX += x
+		sumY += values[i]
+		sumXY += x * values[i]
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+
+	return (n*sumXY - sumX*sumY) / denominator
+}
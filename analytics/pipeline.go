@@ -0,0 +1,197 @@
+package analytics
+
+import (
+	"ares/model"
+	"ares/units"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Query narrows the sessions an aggregation is computed over. Only completed
+// sessions authored by the account are ever included
+type Query struct {
+	Account  primitive.ObjectID
+	From     time.Time
+	To       time.Time
+	Timezone string
+}
+
+// Location returns the location of the query timezone, UTC if the query has
+// no timezone or it can't be loaded
+func (query Query) Location() *time.Location {
+	if query.Timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(query.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// matchSessions returns the stage selecting the completed sessions covered by
+// the query
+func matchSessions(query Query) bson.D {
+	filter := bson.M{
+		"author": query.Account,
+		"status": model.COMPLETED,
+	}
+
+	timestamp := bson.M{}
+	if !query.From.IsZero() {
+		timestamp["$gte"] = query.From
+	}
+
+	if !query.To.IsZero() {
+		timestamp["$lte"] = query.To
+	}
+
+	if len(timestamp) > 0 {
+		filter["timestamp"] = timestamp
+	}
+
+	return bson.D{{Key: "$match", Value: filter}}
+}
+
+// flattenSets returns the stages turning each session in to one document per
+// working set, shaped as {session, timestamp, exerciseName, type, set}.
+// Sets of additional exercises are attributed to their own exercise name so
+// that supersets count towards the right exercise, warmup sets are dropped
+func flattenSets() []bson.D {
+	return []bson.D{
+		{{Key: "$unwind", Value: "$exercises"}},
+		{{Key: "$project", Value: bson.M{
+			"timestamp": 1,
+			"entries": bson.M{"$concatArrays": bson.A{
+				bson.A{bson.M{
+					"exerciseName": "$exercises.exerciseName",
+					"type":         "$exercises.type",
+					"sets":         "$exercises.sets",
+				}},
+				bson.M{"$map": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$exercises.additionalExercises", bson.A{}}},
+					"in": bson.M{
+						"exerciseName": "$$this.exerciseName",
+						"type":         "$exercises.type",
+						"sets":         "$$this.sets",
+					},
+				}},
+			}},
+		}}},
+		{{Key: "$unwind", Value: "$entries"}},
+		{{Key: "$unwind", Value: "$entries.sets"}},
+		{{Key: "$match", Value: bson.M{"entries.sets.warmup": bson.M{"$ne": true}}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":          0,
+			"session":      "$_id",
+			"timestamp":    1,
+			"exerciseName": "$entries.exerciseName",
+			"type":         "$entries.type",
+			"set":          "$entries.sets",
+		}}},
+	}
+}
+
+// kilograms returns an expression for the weight of the flattened set in
+// kilograms. The canonical value is preferred, sets written before it was
+// stored are converted in the pipeline the same way units.ToKilograms does
+func kilograms() bson.M {
+	return bson.M{"$ifNull": bson.A{"$set.weight.kilograms", bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{"$set.weight.weightMeasurementSystem", model.IMPERIAL}},
+		bson.M{"$multiply": bson.A{bson.M{"$ifNull": bson.A{"$set.weight.weightValue", 0}}, units.KilogramsPerPound}},
+		bson.M{"$ifNull": bson.A{"$set.weight.weightValue", 0}},
+	}}}}
+}
+
+// meters returns an expression for the distance of the flattened set in
+// meters. The canonical value is preferred, sets written before it was stored
+// are converted in the pipeline the same way units.ToMeters does
+func meters() bson.M {
+	value := bson.M{"$ifNull": bson.A{"$set.distance.distanceValue", 0}}
+
+	return bson.M{"$ifNull": bson.A{"$set.distance.meters", bson.M{"$switch": bson.M{
+		"branches": bson.A{
+			bson.M{
+				"case": bson.M{"$eq": bson.A{"$set.distance.distanceMeasurementSystem", model.KILOMETER}},
+				"then": bson.M{"$multiply": bson.A{value, units.MetersPerKilometer}},
+			},
+			bson.M{
+				"case": bson.M{"$eq": bson.A{"$set.distance.distanceMeasurementSystem", model.MILE}},
+				"then": bson.M{"$multiply": bson.A{value, units.MetersPerMile}},
+			},
+			bson.M{
+				"case": bson.M{"$eq": bson.A{"$set.distance.distanceMeasurementSystem", model.FEET}},
+				"then": bson.M{"$multiply": bson.A{value, units.MetersPerFoot}},
+			},
+		},
+		"default": value,
+	}}}}
+}
+
+// tonnage returns an expression for the weight moved by the flattened set
+func tonnage() bson.M {
+	return bson.M{"$multiply": bson.A{bson.M{"$ifNull": bson.A{"$set.reps", 0}}, kilograms()}}
+}
+
+// estimatedOneRepMax mirrors records.EstimateOneRepMax, using Brzycki for sets
+// of ten reps or fewer and Epley above that
+func estimatedOneRepMax() bson.M {
+	reps := bson.M{"$ifNull": bson.A{"$set.reps", 0}}
+
+	return bson.M{"$switch": bson.M{
+		"branches": bson.A{
+			bson.M{
+				"case": bson.M{"$lte": bson.A{reps, 1}},
+				"then": kilograms(),
+			},
+			bson.M{
+				"case": bson.M{"$lte": bson.A{reps, 10}},
+				"then": bson.M{"$divide": bson.A{
+					bson.M{"$multiply": bson.A{kilograms(), 36}},
+					bson.M{"$subtract": bson.A{37, reps}},
+				}},
+			},
+		},
+		"default": bson.M{"$multiply": bson.A{
+			kilograms(),
+			bson.M{"$add": bson.A{1, bson.M{"$divide": bson.A{reps, 30}}}},
+		}},
+	}}
+}
+
+// periodStart returns an expression truncating the timestamp field to the
+// start of its interval in the query timezone
+func periodStart(interval Interval, timezone string) bson.M {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+
+	date := bson.M{"date": "$timestamp", "timezone": timezone}
+
+	switch interval {
+	case WEEK:
+		return bson.M{"$dateFromParts": bson.M{
+			"isoWeekYear":  bson.M{"$isoWeekYear": date},
+			"isoWeek":      bson.M{"$isoWeek": date},
+			"isoDayOfWeek": 1,
+			"timezone":     timezone,
+		}}
+	case MONTH:
+		return bson.M{"$dateFromParts": bson.M{
+			"year":     bson.M{"$year": date},
+			"month":    bson.M{"$month": date},
+			"timezone": timezone,
+		}}
+	}
+
+	return bson.M{"$dateFromParts": bson.M{
+		"year":     bson.M{"$year": date},
+		"month":    bson.M{"$month": date},
+		"day":      bson.M{"$dayOfMonth": date},
+		"timezone": timezone,
+	}}
+}
@@ -23,6 +23,7 @@ func CreateEntry(params CreateEntryParams) Entry {
 		Initiator:    params.Initiator,
 		OtherParties: params.OtherParties,
 		IP:           params.IP,
+		Context:      params.Context,
 		EventName:    params.EventName,
 		Timestamp:    time.Now(),
 	}
@@ -21,6 +21,7 @@ const (
 	CREATE_ACCOUNT            EntryType = "create_account"
 	UPDATE_ACCOUNT            EntryType = "update_account"
 	DELETE_ACCOUNT            EntryType = "delete_account"
+	RESTORE_ACCOUNT           EntryType = "restore_account"
 	AUTH_WITH_CREDENTIALS     EntryType = "auth_with_credentials"
 	LOGOUT                    EntryType = "logout"
 	CREATE_POST               EntryType = "create_post"
@@ -29,14 +30,56 @@ const (
 	UPDATE_COMMENT            EntryType = "update_comment"
 	DELETE_POST               EntryType = "delete_post"
 	DELETE_COMMENT            EntryType = "delete_comment"
+	RESTORE_POST              EntryType = "restore_post"
+	RESTORE_COMMENT           EntryType = "restore_comment"
 	CREATE_TRAINING_SESSION   EntryType = "create_training_session"
 	UPDATE_TRAINING_SESSION   EntryType = "update_training_session"
 	DELETE_TRAINING_SESSION   EntryType = "delete_training_session"
+	RESTORE_TRAINING_SESSION  EntryType = "restore_training_session"
 	CREATE_EXERCISE           EntryType = "create_exercise"
+	CREATE_WORKOUT_TEMPLATE   EntryType = "create_workout_template"
+	UPDATE_WORKOUT_TEMPLATE   EntryType = "update_workout_template"
+	DELETE_WORKOUT_TEMPLATE   EntryType = "delete_workout_template"
+	CREATE_PROGRAM            EntryType = "create_program"
+	UPDATE_PROGRAM            EntryType = "update_program"
+	DELETE_PROGRAM            EntryType = "delete_program"
+	ENROLL_PROGRAM            EntryType = "enroll_program"
+	UNENROLL_PROGRAM          EntryType = "unenroll_program"
+	INVITE_ATHLETE            EntryType = "invite_athlete"
+	ACCEPT_COACH_INVITE       EntryType = "accept_coach_invite"
+	REMOVE_COACH_LINK         EntryType = "remove_coach_link"
+	ASSIGN_TRAINING_SESSION   EntryType = "assign_training_session"
+	CREATE_SESSION_COMMENT    EntryType = "create_session_comment"
+	CREATE_FOOD               EntryType = "create_food"
+	UPDATE_FOOD               EntryType = "update_food"
+	DELETE_FOOD               EntryType = "delete_food"
+	CREATE_DIET_ENTRY         EntryType = "create_diet_entry"
+	UPDATE_DIET_ENTRY         EntryType = "update_diet_entry"
+	DELETE_DIET_ENTRY         EntryType = "delete_diet_entry"
+	UPDATE_NUTRITION_TARGET   EntryType = "update_nutrition_target"
+	CREATE_RECIPE             EntryType = "create_recipe"
+	UPDATE_RECIPE             EntryType = "update_recipe"
+	DELETE_RECIPE             EntryType = "delete_recipe"
+	ASSIGN_MEAL_PLAN          EntryType = "assign_meal_plan"
+	DELETE_MEAL_PLAN          EntryType = "delete_meal_plan"
+	LOG_PLANNED_MEAL          EntryType = "log_planned_meal"
+	CREATE_BODY_MEASUREMENT   EntryType = "create_body_measurement"
+	UPDATE_BODY_MEASUREMENT   EntryType = "update_body_measurement"
+	DELETE_BODY_MEASUREMENT   EntryType = "delete_body_measurement"
+	REGISTER_DEVICE_TOKEN     EntryType = "register_device_token"
+	DELETE_DEVICE_TOKEN       EntryType = "delete_device_token"
+	BLOCK_ACCOUNT             EntryType = "block_account"
+	UNBLOCK_ACCOUNT           EntryType = "unblock_account"
+	CREATE_CONVERSATION       EntryType = "create_conversation"
+	LEAVE_CONVERSATION        EntryType = "leave_conversation"
+	IMPORT_TRAINING_SESSIONS  EntryType = "import_training_sessions"
+	EXPORT_ACCOUNT_DATA       EntryType = "export_account_data"
 	UPLOAD_FILE               EntryType = "upload_file"
 	CREATE_LOCATION           EntryType = "create_location"
 	UPDATE_LOCATION           EntryType = "update_location"
 	DELETE_LOCATION           EntryType = "delete_location"
+	RESTORE_LOCATION          EntryType = "restore_location"
+	RESTORE_BLOG              EntryType = "restore_blog"
 	CREATE_ROLE               EntryType = "create_role"
 	DELETE_ROLE               EntryType = "delete_role"
 	UPDATE_ROLE               EntryType = "update_role"
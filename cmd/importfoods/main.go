@@ -0,0 +1,138 @@
+// Command importfoods loads an Open Food Facts style product dump in to the
+// food catalog as verified foods. Dumps run to millions of products, so they
+// are imported offline rather than uploaded through the API:
+//
+//	go run ./cmd/importfoods products.jsonl
+//
+// Products are matched on barcode so the same dump can be imported again to
+// refresh it. Foods with a barcode that were not imported from a dump are
+// never replaced
+package main
+
+import (
+	"ares/config"
+	"ares/database"
+	"ares/importer"
+	"ares/model"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const batchSize = 500
+
+// importFoodUpdate saves every field of an imported food. The source comes
+// from the filter when the food is inserted
+func importFoodUpdate(food model.Food, now time.Time) bson.M {
+	food.UpdatedAt = now
+
+	update := importer.FoodUpdate(food)
+	update["$setOnInsert"] = bson.M{"createdAt": now}
+
+	return update
+}
+
+func main() {
+	databaseName := flag.String("database", "prod", "database to import the foods in to")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: importfoods [-database name] <dump.jsonl|dump.csv>")
+		os.Exit(2)
+	}
+
+	filename := flag.Arg(0)
+
+	file, err := os.Open(filename)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to open dump: ", err)
+		os.Exit(1)
+	}
+
+	defer file.Close()
+
+	products, err := importer.NewProductReader(filename, file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to parse dump: ", err)
+		os.Exit(1)
+	}
+
+	conf := config.Get()
+	mongoClient, err := database.GetMongoClient(conf.Mongo.URI)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to establish mongo client instance: ", err)
+		os.Exit(1)
+	}
+
+	dbQueryParams := database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   *databaseName,
+		CollectionName: "food",
+	}
+
+	var productCount, skipped int
+	var inserted, updated int64
+	var batch []mongo.WriteModel
+
+	flush := func() error {
+		written, duplicates, err := database.BulkWrite(dbQueryParams, batch)
+		if err != nil {
+			return err
+		}
+
+		inserted += written.UpsertedCount
+		updated += written.MatchedCount
+		skipped += duplicates
+		batch = batch[:0]
+
+		return nil
+	}
+
+	for {
+		food, err := products.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			if _, ok := err.(*importer.ProductError); !ok {
+				fmt.Fprintln(os.Stderr, "failed to read dump: ", err)
+				os.Exit(1)
+			}
+
+			fmt.Fprintln(os.Stderr, "skipped product: ", err)
+			skipped++
+			continue
+		}
+
+		productCount++
+
+		// a barcode already used by a food from another source is a
+		// duplicate key error and counts as skipped
+		batch = append(batch, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"barcode": food.Barcode, "source": importer.OPEN_FOOD_FACTS}).
+			SetUpdate(importFoodUpdate(food, time.Now())).
+			SetUpsert(true))
+
+		if len(batch) >= batchSize {
+			err = flush()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "failed to save foods: ", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	err = flush()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to save foods: ", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("products: %d, inserted: %d, updated: %d, skipped: %d\n", productCount, inserted, updated, skipped)
+}
@@ -7,12 +7,14 @@ import (
 )
 
 type Configuration struct {
-	Ares  Ares  `toml:"ares"`
-	Gin   Gin   `toml:"gin"`
-	Auth  Auth  `toml:"auth"`
-	Mongo Mongo `toml:"mongo"`
-	Redis Redis `toml:"redis"`
-	S3    S3    `toml:"s3"`
+	Ares   Ares   `toml:"ares"`
+	Gin    Gin    `toml:"gin"`
+	Auth   Auth   `toml:"auth"`
+	Mongo  Mongo  `toml:"mongo"`
+	Redis  Redis  `toml:"redis"`
+	S3     S3     `toml:"s3"`
+	Worker Worker `toml:"worker"`
+	Push   Push   `toml:"push"`
 }
 
 type Ares struct {
@@ -50,6 +52,37 @@ type S3 struct {
 	Bucket   string `toml:"bucket"`
 }
 
+// Worker configures the scheduled background tasks, intervals are in minutes
+type Worker struct {
+	Enabled         bool `toml:"enabled"`
+	PurgeInterval   int  `toml:"purgeInterval"`
+	CounterInterval int  `toml:"counterInterval"`
+}
+
+// Push configures push notification delivery. The interval is in seconds,
+// fake replaces both providers with ones that only log what they would send
+type Push struct {
+	Enabled     bool `toml:"enabled"`
+	Interval    int  `toml:"interval"`
+	BatchSize   int  `toml:"batchSize"`
+	MaxAttempts int  `toml:"maxAttempts"`
+	Fake        bool `toml:"fake"`
+	APNs        APNs `toml:"apns"`
+	FCM         FCM  `toml:"fcm"`
+}
+
+type APNs struct {
+	KeyID      string `toml:"keyId"`
+	TeamID     string `toml:"teamId"`
+	Topic      string `toml:"topic"`
+	KeyFile    string `toml:"keyFile"`
+	Production bool   `toml:"production"`
+}
+
+type FCM struct {
+	CredentialsFile string `toml:"credentialsFile"`
+}
+
 func Get() *Configuration {
 	f := "config.toml"
 
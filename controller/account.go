@@ -4,7 +4,9 @@ import (
 	"ares/audit"
 	"ares/config"
 	"ares/database"
+	"ares/deletion"
 	"ares/model"
+	"ares/units"
 	"ares/util"
 	"fmt"
 	"net/http"
@@ -66,6 +68,51 @@ func getAccountsFuzzySearch(
 	return accounts, err
 }
 
+// canViewAccount returns true if the requesting account is allowed to see
+// content belonging to the provided account based on its profile privacy
+func canViewAccount(
+	mongoClient *mongo.Client,
+	databaseName string,
+	requestAccountId primitive.ObjectID,
+	account model.Account,
+) (bool, error) {
+	if requestAccountId == account.ID {
+		return true, nil
+	}
+
+	switch account.Preferences.Privacy.ProfilePrivacy {
+	case model.PRIVATE:
+		return false, nil
+	case model.FOLLOWER_ONLY:
+		return IsFollowing(mongoClient, databaseName, "follow", requestAccountId, account.ID)
+	}
+
+	return true, nil
+}
+
+// preferredMeasurementSystem returns the measurement system responses should
+// be converted to, taken from the 'units' query string or the unit preference
+// of the requesting account. An empty system means values are returned in the
+// units they were logged in
+func preferredMeasurementSystem(ctx *gin.Context, mongoClient *mongo.Client, databaseName string) (model.MeasurementSystem, error) {
+	system, systemPresent := ctx.GetQuery("units")
+	if systemPresent {
+		return units.ParseSystem(system)
+	}
+
+	account, err := database.FindDocumentById[model.Account](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "account",
+	}, ctx.GetString("accountId"))
+
+	if err != nil {
+		return "", err
+	}
+
+	return account.Preferences.Units.MeasurementSystem, nil
+}
+
 // GetAccountAvailability checks the database to see if the provided
 // key/value pair is already in use in the database
 //
@@ -356,6 +403,9 @@ func (controller *AresController) CreateStandardAccount(secure bool) gin.Handler
 			Email:    params.Email,
 			Password: hashedPwd,
 			Type:     model.STANDARD,
+			Preferences: model.Preferences{
+				Notifications: model.AllNotifications(),
+			},
 		}
 
 		id, err := database.InsertOne(dbQueryParams, acc)
@@ -449,7 +499,8 @@ func (controller *AresController) UpdateAccount(id string) gin.HandlerFunc {
 		if id != "notifications" &&
 			id != "privacy" &&
 			id != "profile" &&
-			id != "biometrics" {
+			id != "biometrics" &&
+			id != "units" {
 			ctx.AbortWithStatus(http.StatusBadRequest)
 			return
 		}
@@ -501,6 +552,25 @@ func (controller *AresController) UpdateAccount(id string) gin.HandlerFunc {
 			}
 
 			account.Biometrics = params
+		} else if id == "units" {
+			var params model.UnitPreferences
+
+			err = ctx.ShouldBindJSON(&params)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal unit preferences object"})
+				return
+			}
+
+			params.MeasurementSystem, err = units.ParseSystem(string(params.MeasurementSystem))
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				return
+			}
+
+			// biometrics are kept in the preferred system, so they change
+			// system along with the preference
+			account.Biometrics = units.ConvertBiometrics(account.Biometrics, account.Preferences.Units.MeasurementSystem, params.MeasurementSystem)
+			account.Preferences.Units = params
 		}
 
 		updated, err := database.UpdateOne(dbQueryParams, account.ID, account)
@@ -579,10 +649,12 @@ func (controller *AresController) GetAccountCount() gin.HandlerFunc {
 }
 
 // DeleteAccount will remove an account from the account database and
-// transfer it to the deleted collection. Along with the account itself,
-// a deleted account struct contains the time it will expire and need to
-// be removed. That date is then picked up by a utility application which
-// will clean up the database and handle the actual removal
+// transfer it to the deleted collection, along with every document that
+// belongs to it. Each deleted document contains the time it will expire and
+// need to be removed. That date is then picked up by the purge worker which
+// will clean up the database and handle the actual removal. The refresh
+// tokens issued to the account are revoked and a receipt of everything
+// removed is stored in the audit log
 func (controller *AresController) DeleteAccount() gin.HandlerFunc {
 	accountDbQueryParams := database.QueryParams{
 		MongoClient:    controller.DB,
@@ -590,6 +662,12 @@ func (controller *AresController) DeleteAccount() gin.HandlerFunc {
 		CollectionName: controller.CollectionName,
 	}
 
+	orchestrator := deletion.Orchestrator{
+		MongoClient:  controller.DB,
+		DatabaseName: controller.DatabaseName,
+		RedisClient:  controller.RedisCache,
+	}
+
 	return func(ctx *gin.Context) {
 		accountId := ctx.GetString("accountId")
 		account, err := database.FindDocumentById[model.Account](accountDbQueryParams, accountId)
@@ -605,36 +683,20 @@ func (controller *AresController) DeleteAccount() gin.HandlerFunc {
 		}
 
 		// TODO: Make removalAt customizable
-		deletedAccount := model.DeletedAccount{
-			Account:   account,
-			RemovalAt: time.Now().Add(time.Hour * 24 * 7 * time.Duration(4)),
-		}
-
-		deletedId, err := database.InsertOne(database.QueryParams{
-			MongoClient:    controller.DB,
-			DatabaseName:   controller.DatabaseName,
-			CollectionName: controller.CollectionName + "_deleted",
-		}, deletedAccount)
-
-		if err != nil {
-			ctx.AbortWithStatus(http.StatusInternalServerError)
-			return
-		}
-
-		deleteResult, err := database.DeleteOne(accountDbQueryParams, account)
+		receipt, err := orchestrator.DeleteAccount(account, time.Now().Add(time.Hour*24*7*time.Duration(4)))
 		if err != nil {
 			if err == mongo.ErrNoDocuments {
 				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "account not found"})
 				return
 			}
 
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to delete account from database"})
-			return
-		}
+			// the account is already gone if only revoking tokens failed
+			if receipt.DeletedId == "" {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to delete account: " + err.Error()})
+				return
+			}
 
-		if deleteResult.DeletedCount <= 0 {
-			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "account delete result returned as zero"})
-			return
+			fmt.Println("failed to revoke refresh tokens: ", err)
 		}
 
 		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
@@ -642,13 +704,13 @@ func (controller *AresController) DeleteAccount() gin.HandlerFunc {
 			Initiator:   account.ID,
 			IP:          ctx.ClientIP(),
 			EventName:   audit.DELETE_ACCOUNT,
-			Context:     []string{"deleted id: " + deletedId},
+			Context:     receipt.Context(),
 		})
 
 		if err != nil {
 			fmt.Println("failed to save audit entry: ", err)
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{"deletedId": deletedId})
+		ctx.JSON(http.StatusOK, gin.H{"deletedId": receipt.DeletedId, "receipt": receipt})
 	}
 }
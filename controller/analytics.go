@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"ares/analytics"
+	"ares/database"
+	"ares/model"
+	"ares/util"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// parseAnalyticsQuery builds an analytics query for the account in the path,
+// aborting the request if the account can't be viewed by the requester or the
+// query strings are malformed
+func (controller *AresController) parseAnalyticsQuery(ctx *gin.Context) (analytics.Query, bool) {
+	var query analytics.Query
+
+	accountId := ctx.Param("accountId")
+	from, fromPresent := ctx.GetQuery("from")
+	to, toPresent := ctx.GetQuery("to")
+	timezone, timezonePresent := ctx.GetQuery("timezone")
+	attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+	requestAccountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad request account id hex"})
+		return query, false
+	}
+
+	account, err := database.FindDocumentById[model.Account](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: "account",
+	}, accountId)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "account not found"})
+			return query, false
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up account: " + err.Error()})
+		return query, false
+	}
+
+	if !util.ContainsPerm(model.BYPASS_PRIVACY, attachedPermissions) {
+		canView, err := canViewAccount(controller.DB, controller.DatabaseName, requestAccountIdHex, account)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+			return query, false
+		}
+
+		if !canView {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return query, false
+		}
+	}
+
+	query.Account = account.ID
+
+	if timezonePresent {
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid timezone: " + err.Error()})
+			return query, false
+		}
+
+		query.Timezone = location.String()
+	}
+
+	if fromPresent {
+		query.From, err = time.ParseInLocation("01-02-2006", from, query.Location())
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid from time/date: " + err.Error()})
+			return query, false
+		}
+	}
+
+	if toPresent {
+		query.To, err = time.ParseInLocation("01-02-2006", to, query.Location())
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid to time/date: " + err.Error()})
+			return query, false
+		}
+
+		// include sessions logged at any point on the final day, which isn't
+		// 24 hours long when daylight saving changes
+		query.To = query.To.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	return query, true
+}
+
+// parseAnalyticsInterval reads the interval query string, defaulting to weeks
+func parseAnalyticsInterval(ctx *gin.Context, allowed ...analytics.Interval) (analytics.Interval, error) {
+	interval := analytics.Interval(strings.ToUpper(ctx.DefaultQuery("interval", string(analytics.WEEK))))
+
+	for _, candidate := range allowed {
+		if interval == candidate {
+			return interval, nil
+		}
+	}
+
+	return interval, errors.New("unsupported interval " + string(interval))
+}
+
+// GetTrainingVolume returns the sets, reps, tonnage, distance and time
+// trained by an account per week or month
+func (controller *AresController) GetTrainingVolume() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		query, ok := controller.parseAnalyticsQuery(ctx)
+		if !ok {
+			return
+		}
+
+		interval, err := parseAnalyticsInterval(ctx, analytics.WEEK, analytics.MONTH)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		result, err := database.Aggregate[analytics.Volume](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, analytics.VolumePipeline(query, interval))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		for i, entry := range result {
+			result[i] = entry.Convert(system)
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// GetMuscleGroupVolume returns the working sets and tonnage trained by an
+// account per muscle group
+func (controller *AresController) GetMuscleGroupVolume() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		query, ok := controller.parseAnalyticsQuery(ctx)
+		if !ok {
+			return
+		}
+
+		result, err := database.Aggregate[analytics.MuscleGroupVolume](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, analytics.MuscleGroupPipeline(query, "exercise_info"))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		for i, entry := range result {
+			result[i] = entry.Convert(system)
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// GetTrainingFrequency returns the number of sessions completed by an account
+// per day, week or month along with its current and longest streak
+func (controller *AresController) GetTrainingFrequency() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		query, ok := controller.parseAnalyticsQuery(ctx)
+		if !ok {
+			return
+		}
+
+		interval, err := parseAnalyticsInterval(ctx, analytics.DAY, analytics.WEEK, analytics.MONTH)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		result, err := database.Aggregate[analytics.Frequency](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, analytics.FrequencyPipeline(query, interval))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"result": result,
+			"streak": analytics.Streaks(result, interval, query.Location(), time.Now()),
+		})
+	}
+}
+
+// GetExerciseProgression returns the top weight, estimated one rep max and
+// totals of a single exercise for every session it was performed in
+func (controller *AresController) GetExerciseProgression() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		exerciseName, exerciseNamePresent := ctx.GetQuery("exercise")
+		if !exerciseNamePresent || exerciseName == "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "missing exercise name"})
+			return
+		}
+
+		query, ok := controller.parseAnalyticsQuery(ctx)
+		if !ok {
+			return
+		}
+
+		result, err := database.Aggregate[analytics.Progression](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, analytics.ProgressionPipeline(query, exerciseName))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		for i, entry := range result {
+			result[i] = entry.Convert(system)
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"ares/util"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
@@ -133,6 +134,15 @@ func (controller *AresController) AuthenticateStandardCredentials(secure bool) g
 			return
 		}
 
+		// tokens are also tracked per account so they can all be revoked
+		err = database.AddSetMember(database.RedisClientParams{
+			RedisClient: controller.RedisCache,
+		}, database.RefreshTokensKey(account.ID.Hex()), refreshToken, refreshTokenTTL)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to track refresh token: " + err.Error()})
+			return
+		}
+
 		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
 			MongoClient: controller.DB,
 			Initiator:   account.ID,
@@ -255,6 +265,10 @@ func (controller *AresController) Logout(secure bool) gin.HandlerFunc {
 			return
 		}
 
+		tokenAccountId, _ := database.GetCacheValue(database.RedisClientParams{
+			RedisClient: controller.RedisCache,
+		}, refreshToken)
+
 		deleteCount, err := database.DeleteCacheValue(database.RedisClientParams{
 			RedisClient: controller.RedisCache,
 		}, refreshToken)
@@ -264,6 +278,16 @@ func (controller *AresController) Logout(secure bool) gin.HandlerFunc {
 			return
 		}
 
+		if tokenAccountId != "" {
+			_, err = database.RemoveSetMember(database.RedisClientParams{
+				RedisClient: controller.RedisCache,
+			}, database.RefreshTokensKey(tokenAccountId), refreshToken)
+
+			if err != nil {
+				fmt.Println("failed to untrack refresh token: ", err)
+			}
+		}
+
 		var cookieDomain string
 		if isReleaseVersion {
 			cookieDomain = "*.trainingclubapp.com"
@@ -273,6 +297,18 @@ func (controller *AresController) Logout(secure bool) gin.HandlerFunc {
 
 		accountIdHex, err := primitive.ObjectIDFromHex(accountId)
 		if err == nil {
+			// devices registered during the session should stop receiving
+			// pushes for an account that is no longer logged in on them
+			_, err = database.DeleteManyByFilter(database.QueryParams{
+				MongoClient:    controller.DB,
+				DatabaseName:   controller.DatabaseName,
+				CollectionName: "device_token",
+			}, bson.M{"account": accountIdHex, "session": sessionHash(refreshToken)})
+
+			if err != nil {
+				fmt.Println("failed to remove session device tokens: ", err)
+			}
+
 			err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
 				MongoClient: controller.DB,
 				Initiator:   accountIdHex,
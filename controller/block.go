@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/model"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IsBlocked returns true if either account has blocked the other
+func IsBlocked(mongoClient *mongo.Client, databaseName string, a primitive.ObjectID, b primitive.ObjectID) (bool, error) {
+	count, err := database.Count(database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "block",
+	}, bson.M{"$or": bson.A{
+		bson.M{"blocker": a, "blocked": b},
+		bson.M{"blocker": b, "blocked": a},
+	}})
+
+	return count > 0, err
+}
+
+// BlockedAmong returns the accounts in others that have blocked the account
+// or were blocked by it
+func BlockedAmong(mongoClient *mongo.Client, databaseName string, account primitive.ObjectID, others []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	blocks, err := database.FindManyDocumentsByFilter[model.Block](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "block",
+	}, bson.M{"$or": bson.A{
+		bson.M{"blocker": account, "blocked": bson.M{"$in": others}},
+		bson.M{"blocker": bson.M{"$in": others}, "blocked": account},
+	}})
+
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := map[primitive.ObjectID]bool{}
+	for _, block := range blocks {
+		if block.Blocker == account {
+			blocked[block.Blocked] = true
+		} else {
+			blocked[block.Blocker] = true
+		}
+	}
+
+	return blocked, nil
+}
+
+// BlockAccount blocks the account in the path for the requesting account and
+// removes the follows between them
+func (controller *AresController) BlockAccount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		blockedHex, err := primitive.ObjectIDFromHex(ctx.Param("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad blocked account id hex"})
+			return
+		}
+
+		if accountIdHex == blockedHex {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "can not block self"})
+			return
+		}
+
+		_, err = database.FindDocumentById[model.Account](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "account",
+		}, blockedHex.Hex())
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "blocked account not found"})
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "block",
+		}, model.Block{
+			Blocker:   accountIdHex,
+			Blocked:   blockedHex,
+			BlockedAt: time.Now(),
+		})
+
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "account is already blocked"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document: " + err.Error()})
+			return
+		}
+
+		_, err = database.DeleteManyByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "follow",
+		}, bson.M{"$or": bson.A{
+			bson.M{"followingId": accountIdHex, "followedId": blockedHex},
+			bson.M{"followingId": blockedHex, "followedId": accountIdHex},
+		}})
+
+		if err != nil {
+			fmt.Println("failed to remove follows of blocked account: ", err)
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.BLOCK_ACCOUNT,
+			Context:     []string{"blocked: " + blockedHex.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// UnblockAccount removes the block the requesting account placed on the
+// account in the path
+func (controller *AresController) UnblockAccount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		blockedHex, err := primitive.ObjectIDFromHex(ctx.Param("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad blocked account id hex"})
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "block",
+		}, bson.M{"blocker": accountIdHex, "blocked": blockedHex})
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.UNBLOCK_ACCOUNT,
+			Context:     []string{"unblocked: " + blockedHex.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// GetBlockedAccounts returns the accounts blocked by the requesting account,
+// most recently blocked first
+func (controller *AresController) GetBlockedAccounts() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.Block](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "block",
+		}, bson.M{"blocker": accountIdHex}, options.
+			Find().
+			SetLimit(25).
+			SetSkip(int64(pageNumber*25)).
+			SetSort(bson.M{"blockedAt": -1}))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
@@ -302,7 +302,7 @@ func (controller *AresController) DeleteBlog() gin.HandlerFunc {
 		_, err = database.InsertOne[model.DeletedBlogPost](database.QueryParams{
 			MongoClient:    controller.DB,
 			DatabaseName:   controller.DatabaseName,
-			CollectionName: controller.CollectionName,
+			CollectionName: controller.CollectionName + "_deleted",
 		}, deletedBlog)
 		if err != nil {
 			ctx.AbortWithStatus(http.StatusInternalServerError)
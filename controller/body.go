@@ -0,0 +1,625 @@
+package controller
+
+import (
+	"ares/analytics"
+	"ares/audit"
+	"ares/config"
+	"ares/database"
+	"ares/model"
+	"ares/units"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BodyMeasurementParams are the values of a body measurement in the units of
+// the measurement system of the request
+type BodyMeasurementParams struct {
+	MeasuredAt time.Time `json:"measuredAt,omitempty"`
+	Weight     float64   `json:"weight,omitempty"`
+	BodyFat    float64   `json:"bodyFat,omitempty"`
+	Neck       float64   `json:"neck,omitempty"`
+	Chest      float64   `json:"chest,omitempty"`
+	Waist      float64   `json:"waist,omitempty"`
+	Hips       float64   `json:"hips,omitempty"`
+	Arms       float64   `json:"arms,omitempty"`
+	Thighs     float64   `json:"thighs,omitempty"`
+	Calves     float64   `json:"calves,omitempty"`
+	Photos     []string  `json:"photos,omitempty"`
+	Note       string    `json:"note,omitempty"`
+}
+
+// apply copies the params on to the measurement, converting them from the
+// provided system to kilograms and centimeters. The returned message
+// describes the first invalid value and is empty if the params are valid
+func (params BodyMeasurementParams) apply(measurement *model.BodyMeasurement, system model.MeasurementSystem) string {
+	values := []float64{params.Weight, params.BodyFat, params.Neck, params.Chest, params.Waist, params.Hips, params.Arms, params.Thighs, params.Calves}
+
+	measured := false
+	for _, value := range values {
+		if value < 0 {
+			return "measurements can't be negative"
+		}
+
+		measured = measured || value > 0
+	}
+
+	if !measured && len(params.Photos) == 0 {
+		return "body measurement must contain a measurement or a photo"
+	}
+
+	if params.BodyFat > 100 {
+		return "body fat must be a percentage"
+	}
+
+	measurement.MeasuredAt = params.MeasuredAt
+	measurement.Weight = params.Weight
+	measurement.BodyFat = params.BodyFat
+	measurement.Neck = params.Neck
+	measurement.Chest = params.Chest
+	measurement.Waist = params.Waist
+	measurement.Hips = params.Hips
+	measurement.Arms = params.Arms
+	measurement.Thighs = params.Thighs
+	measurement.Calves = params.Calves
+	measurement.Photos = params.Photos
+	measurement.Note = params.Note
+
+	if measurement.MeasuredAt.IsZero() {
+		measurement.MeasuredAt = time.Now()
+	}
+
+	*measurement = units.CanonicalizeBodyMeasurement(*measurement, system)
+
+	return ""
+}
+
+// bodyMeasurementUpdate returns the update saving the editable values of a
+// measurement. Values that are no longer recorded are unset, the fields are
+// omitempty so setting the measurement itself would keep the previous value
+func bodyMeasurementUpdate(measurement model.BodyMeasurement) bson.M {
+	set := bson.M{"measuredAt": measurement.MeasuredAt}
+	unset := bson.M{}
+
+	values := map[string]float64{
+		"weight":  measurement.Weight,
+		"bodyFat": measurement.BodyFat,
+		"neck":    measurement.Neck,
+		"chest":   measurement.Chest,
+		"waist":   measurement.Waist,
+		"hips":    measurement.Hips,
+		"arms":    measurement.Arms,
+		"thighs":  measurement.Thighs,
+		"calves":  measurement.Calves,
+	}
+
+	for key, value := range values {
+		if value > 0 {
+			set[key] = value
+		} else {
+			unset[key] = ""
+		}
+	}
+
+	if len(measurement.Photos) > 0 {
+		set["photos"] = measurement.Photos
+	} else {
+		unset["photos"] = ""
+	}
+
+	if measurement.Note != "" {
+		set["note"] = measurement.Note
+	} else {
+		unset["note"] = ""
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	return update
+}
+
+// ownsFiles returns true if every provided key belongs to a file uploaded by
+// the account
+func ownsFiles(mongoClient *mongo.Client, databaseName string, accountId primitive.ObjectID, keys []string) (bool, error) {
+	unique := map[string]bool{}
+	for _, key := range keys {
+		unique[key] = true
+	}
+
+	if len(unique) == 0 {
+		return true, nil
+	}
+
+	owned, err := database.Count(database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "file",
+	}, bson.M{"owner": accountId, "key": bson.M{"$in": keys}})
+
+	return owned == int64(len(unique)), err
+}
+
+// syncBiometricWeight sets the weight in the account biometrics to the most
+// recent weigh-in, in the measurement system the account prefers. The
+// biometrics are left as they are if the account has no weigh-ins
+func syncBiometricWeight(mongoClient *mongo.Client, databaseName string, accountId primitive.ObjectID) error {
+	latest, err := database.FindManyDocumentsByFilterWithOpts[model.BodyMeasurement](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "body_measurement",
+	}, bson.M{"account": accountId, "weight": bson.M{"$gt": 0}}, options.Find().SetSort(bson.M{"measuredAt": -1}).SetLimit(1))
+
+	if err != nil || len(latest) == 0 {
+		return err
+	}
+
+	accountParams := database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "account",
+	}
+
+	account, err := database.FindDocumentById[model.Account](accountParams, accountId.Hex())
+	if err != nil {
+		return err
+	}
+
+	weight := units.Round(units.FromKilograms(latest[0].Weight, account.Preferences.Units.MeasurementSystem))
+
+	_, err = database.UpdateOneByFilter(accountParams, bson.M{"_id": accountId}, bson.M{"$set": bson.M{"biometrics.weight": float32(weight)}})
+
+	return err
+}
+
+// findOwnBodyMeasurement looks up a body measurement taken by the requesting
+// account, aborting the request if it doesn't exist or belongs to another
+// account
+func (controller *AresController) findOwnBodyMeasurement(ctx *gin.Context, measurementId string, accountId primitive.ObjectID) (model.BodyMeasurement, bool) {
+	_, err := primitive.ObjectIDFromHex(measurementId)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad measurement id hex"})
+		return model.BodyMeasurement{}, false
+	}
+
+	measurement, err := database.FindDocumentById[model.BodyMeasurement](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}, measurementId)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return measurement, false
+		}
+
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return measurement, false
+	}
+
+	if measurement.Account != accountId {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return measurement, false
+	}
+
+	return measurement, true
+}
+
+// GetBodyMeasurements returns the body measurements of the requesting
+// account, newest first, in the measurement system of the request. The
+// optional from and to query strings (MM-DD-YYYY) limit the measurements to a
+// range of days
+func (controller *AresController) GetBodyMeasurements() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		page := ctx.DefaultQuery("page", "0")
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(page)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		filter := bson.M{"account": accountIdHex}
+		measuredAt := bson.M{}
+
+		if from, present := ctx.GetQuery("from"); present {
+			date, err := time.Parse("01-02-2006", from)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid from date: " + err.Error()})
+				return
+			}
+
+			measuredAt["$gte"] = date
+		}
+
+		if to, present := ctx.GetQuery("to"); present {
+			date, err := time.Parse("01-02-2006", to)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid to date: " + err.Error()})
+				return
+			}
+
+			measuredAt["$lt"] = date.AddDate(0, 0, 1)
+		}
+
+		if len(measuredAt) > 0 {
+			filter["measuredAt"] = measuredAt
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.BodyMeasurement](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, filter, options.Find().SetSort(bson.M{"measuredAt": -1}).SetLimit(25).SetSkip(int64(pageNumber*25)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		for i, measurement := range result {
+			result[i] = units.ConvertBodyMeasurement(measurement, system)
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// GetBodyMeasurementById returns a single body measurement of the requesting
+// account with signed urls for its progress photos
+func (controller *AresController) GetBodyMeasurementById() gin.HandlerFunc {
+	conf := config.Get()
+	bucket := conf.S3.Bucket
+
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		measurement, ok := controller.findOwnBodyMeasurement(ctx, ctx.Param("measurementId"), accountIdHex)
+		if !ok {
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		for _, key := range measurement.Photos {
+			signed, err := database.SignUrl(controller.S3, bucket, key)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to sign url for progress photo"})
+				return
+			}
+
+			measurement.PhotoUrls = append(measurement.PhotoUrls, signed)
+		}
+
+		ctx.JSON(http.StatusOK, units.ConvertBodyMeasurement(measurement, system))
+	}
+}
+
+// CreateBodyMeasurement records a body measurement for the requesting
+// account. Values are read in the measurement system of the request and
+// photos must be files the account uploaded. Weigh-ins update the weight in
+// the account biometrics when they are the most recent
+func (controller *AresController) CreateBodyMeasurement() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var params BodyMeasurementParams
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		measurement := model.BodyMeasurement{Account: accountIdHex}
+
+		message := params.apply(&measurement, system)
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		owned, err := ownsFiles(controller.DB, controller.DatabaseName, accountIdHex, measurement.Photos)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up photos: " + err.Error()})
+			return
+		}
+
+		if !owned {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "photos must be uploaded by the requesting account"})
+			return
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, measurement)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		if measurement.Weight > 0 {
+			err = syncBiometricWeight(controller.DB, controller.DatabaseName, accountIdHex)
+			if err != nil {
+				fmt.Println("failed to sync biometric weight: ", err)
+			}
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.CREATE_BODY_MEASUREMENT,
+			Context:     []string{"measurement id: " + inserted},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// UpdateBodyMeasurement replaces the values of a body measurement taken by
+// the requesting account
+//
+// If successful the response will return a 202 status accepted
+func (controller *AresController) UpdateBodyMeasurement() gin.HandlerFunc {
+	type Params struct {
+		ID primitive.ObjectID `json:"id" binding:"required"`
+		BodyMeasurementParams
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		measurement, ok := controller.findOwnBodyMeasurement(ctx, params.ID.Hex(), accountIdHex)
+		if !ok {
+			return
+		}
+
+		hadWeight := measurement.Weight > 0
+
+		message := params.apply(&measurement, system)
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		owned, err := ownsFiles(controller.DB, controller.DatabaseName, accountIdHex, measurement.Photos)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up photos: " + err.Error()})
+			return
+		}
+
+		if !owned {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "photos must be uploaded by the requesting account"})
+			return
+		}
+
+		updated, err := database.UpdateOneByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": measurement.ID}, bodyMeasurementUpdate(measurement))
+
+		if err != nil || updated.MatchedCount <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update document"})
+			return
+		}
+
+		// the latest weigh-in is read back, so a cleared weight falls back
+		// to the weigh-in before it
+		if hadWeight || measurement.Weight > 0 {
+			err = syncBiometricWeight(controller.DB, controller.DatabaseName, accountIdHex)
+			if err != nil {
+				fmt.Println("failed to sync biometric weight: ", err)
+			}
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.UPDATE_BODY_MEASUREMENT,
+			Context:     []string{"measurement id: " + measurement.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+// DeleteBodyMeasurement removes a body measurement taken by the requesting
+// account and creates a Deleted Body Measurement in the deleted database.
+// Removing the latest weigh-in reverts the biometric weight to the one before
+func (controller *AresController) DeleteBodyMeasurement() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		measurement, ok := controller.findOwnBodyMeasurement(ctx, ctx.Param("measurementId"), accountIdHex)
+		if !ok {
+			return
+		}
+
+		_, err = database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName + "_deleted",
+		}, model.DeletedBodyMeasurement{
+			Measurement: measurement,
+			RemovalAt:   time.Now().Add(time.Hour * 24 * 7 * time.Duration(4)),
+		})
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": measurement.ID})
+
+		if err != nil || deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if measurement.Weight > 0 {
+			err = syncBiometricWeight(controller.DB, controller.DatabaseName, accountIdHex)
+			if err != nil {
+				fmt.Println("failed to sync biometric weight: ", err)
+			}
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.DELETE_BODY_MEASUREMENT,
+			Context:     []string{"measurement id: " + measurement.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// GetBodyTrend returns the trend of the metric in the path over the last
+// number of days in the days query string, 90 by default. The moving average
+// covers the number of days in the window query string, 7 by default. Days
+// start at midnight in the optional timezone query string, UTC otherwise
+func (controller *AresController) GetBodyTrend() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		metric := model.BodyMetric(ctx.Param("metric"))
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		if _, known := (model.BodyMeasurement{}).Value(metric); !known {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "unknown body metric " + string(metric)})
+			return
+		}
+
+		days, err := strconv.Atoi(ctx.DefaultQuery("days", "90"))
+		if err != nil || days <= 0 || days > 3650 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "days must be between 1 and 3650"})
+			return
+		}
+
+		window, err := strconv.Atoi(ctx.DefaultQuery("window", "7"))
+		if err != nil || window <= 0 || window > 365 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "window must be between 1 and 365"})
+			return
+		}
+
+		location, err := time.LoadLocation(ctx.DefaultQuery("timezone", "UTC"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid timezone: " + err.Error()})
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		// measurements before the range are loaded so the first moving
+		// averages cover a full window
+		year, month, day := time.Now().In(location).Date()
+		from := time.Date(year, month, day, 0, 0, 0, 0, location).AddDate(0, 0, 1-days)
+
+		measurements, err := database.FindManyDocumentsByFilterWithOpts[model.BodyMeasurement](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{
+			"account":      accountIdHex,
+			string(metric): bson.M{"$gt": 0},
+			"measuredAt":   bson.M{"$gte": from.AddDate(0, 0, -window)},
+		}, options.Find().SetSort(bson.M{"measuredAt": 1}))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": analytics.BodyTrend(measurements, metric, window, from, system, location)})
+	}
+}
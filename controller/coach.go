@@ -0,0 +1,662 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/model"
+	"ares/notification"
+	"ares/realtime"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findAcceptedCoachLink returns the accepted link between the coach and the
+// athlete, or mongo.ErrNoDocuments if the coach does not coach the athlete
+func findAcceptedCoachLink(
+	mongoClient *mongo.Client,
+	databaseName string,
+	coachId primitive.ObjectID,
+	athleteId primitive.ObjectID,
+) (model.CoachLink, error) {
+	return database.FindDocumentByFilter[model.CoachLink](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "coach_link",
+	}, bson.M{"coach": coachId, "athlete": athleteId, "status": model.COACH_LINK_ACCEPTED})
+}
+
+// coachSessionFilter narrows a session filter to the sessions of an athlete
+// a coach can see. Athletes with a private profile only share the sessions
+// the coach assigned to them
+func coachSessionFilter(athlete model.Account, coachId primitive.ObjectID) bson.M {
+	filter := bson.M{"author": athlete.ID}
+
+	if athlete.Preferences.Privacy.ProfilePrivacy == model.PRIVATE {
+		filter["assignedBy"] = coachId
+	}
+
+	return filter
+}
+
+// canAccessSession returns true if the requesting account is the author of
+// the session or one of the author's coaches allowed to see it
+func canAccessSession(
+	mongoClient *mongo.Client,
+	databaseName string,
+	requestAccountId primitive.ObjectID,
+	session model.Session,
+) (bool, error) {
+	if session.Author == requestAccountId {
+		return true, nil
+	}
+
+	_, err := findAcceptedCoachLink(mongoClient, databaseName, requestAccountId, session.Author)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	athlete, err := database.FindDocumentById[model.Account](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "account",
+	}, session.Author.Hex())
+
+	if err != nil {
+		return false, err
+	}
+
+	if athlete.Preferences.Privacy.ProfilePrivacy == model.PRIVATE {
+		return session.AssignedBy == requestAccountId, nil
+	}
+
+	return true, nil
+}
+
+// InviteAthlete creates a pending coach link between the requesting account
+// and the provided athlete, which the athlete can then accept
+func (controller *AresController) InviteAthlete() gin.HandlerFunc {
+	type Params struct {
+		Athlete primitive.ObjectID `json:"athlete" binding:"required"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		if params.Athlete == accountIdHex {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "cannot coach yourself"})
+			return
+		}
+
+		_, err = database.FindDocumentById[model.Account](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "account",
+		}, params.Athlete.Hex())
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "athlete not found"})
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		link := model.CoachLink{
+			Coach:     accountIdHex,
+			Athlete:   params.Athlete,
+			Status:    model.COACH_LINK_PENDING,
+			CreatedAt: time.Now(),
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, link)
+
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "athlete already invited"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient:  controller.DB,
+			Initiator:    accountIdHex,
+			IP:           ctx.ClientIP(),
+			OtherParties: []primitive.ObjectID{params.Athlete},
+			EventName:    audit.INVITE_ATHLETE,
+			Context:      []string{"coach link id: " + inserted},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// AcceptCoachInvite accepts a pending invite sent to the requesting account
+func (controller *AresController) AcceptCoachInvite() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		linkIdHex, err := primitive.ObjectIDFromHex(ctx.Param("linkId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad coach link id hex"})
+			return
+		}
+
+		dbQueryParams := database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}
+
+		link, err := database.FindDocumentByFilter[model.CoachLink](dbQueryParams, bson.M{
+			"_id":     linkIdHex,
+			"athlete": accountIdHex,
+			"status":  model.COACH_LINK_PENDING,
+		})
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "invite not found"})
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		result, err := database.UpdateOneByFilter(dbQueryParams, bson.M{"_id": link.ID, "status": model.COACH_LINK_PENDING}, bson.M{
+			"$set": bson.M{"status": model.COACH_LINK_ACCEPTED, "acceptedAt": time.Now()},
+		})
+
+		if err != nil || result.ModifiedCount <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient:  controller.DB,
+			Initiator:    accountIdHex,
+			IP:           ctx.ClientIP(),
+			OtherParties: []primitive.ObjectID{link.Coach},
+			EventName:    audit.ACCEPT_COACH_INVITE,
+			Context:      []string{"coach link id: " + link.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// RemoveCoachLink removes a coach link, either side of the link can remove
+// it. Removing a pending invite rejects it
+func (controller *AresController) RemoveCoachLink() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		linkIdHex, err := primitive.ObjectIDFromHex(ctx.Param("linkId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad coach link id hex"})
+			return
+		}
+
+		dbQueryParams := database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}
+
+		link, err := database.FindDocumentById[model.CoachLink](dbQueryParams, linkIdHex.Hex())
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if link.Coach != accountIdHex && link.Athlete != accountIdHex {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(dbQueryParams, bson.M{"_id": link.ID})
+		if err != nil || deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to delete document"})
+			return
+		}
+
+		otherParty := link.Athlete
+		if link.Athlete == accountIdHex {
+			otherParty = link.Coach
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient:  controller.DB,
+			Initiator:    accountIdHex,
+			IP:           ctx.ClientIP(),
+			OtherParties: []primitive.ObjectID{otherParty},
+			EventName:    audit.REMOVE_COACH_LINK,
+			Context:      []string{"coach link id: " + link.ID.Hex(), "status: " + string(link.Status)},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// GetCoachLinks returns the links of the requesting account, either the
+// athletes they coach or the coaches they have including pending invites
+func (controller *AresController) GetCoachLinks(side string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if side != "coach" && side != "athlete" {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.CoachLink](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{side: accountIdHex}, options.Find().SetSort(bson.M{"createdAt": -1}))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// findCoachedAthlete looks up the athlete in the path, aborting the request
+// unless the requesting account is one of their coaches
+func (controller *AresController) findCoachedAthlete(ctx *gin.Context) (model.Account, primitive.ObjectID, bool) {
+	accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+		return model.Account{}, accountIdHex, false
+	}
+
+	athleteIdHex, err := primitive.ObjectIDFromHex(ctx.Param("athleteId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad athlete id hex"})
+		return model.Account{}, accountIdHex, false
+	}
+
+	_, err = findAcceptedCoachLink(controller.DB, controller.DatabaseName, accountIdHex, athleteIdHex)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "athlete not found"})
+			return model.Account{}, accountIdHex, false
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up coach link: " + err.Error()})
+		return model.Account{}, accountIdHex, false
+	}
+
+	athlete, err := database.FindDocumentById[model.Account](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: "account",
+	}, athleteIdHex.Hex())
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "athlete not found"})
+			return athlete, accountIdHex, false
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up athlete: " + err.Error()})
+		return athlete, accountIdHex, false
+	}
+
+	return athlete, accountIdHex, true
+}
+
+// GetAthleteSessions returns the sessions of an athlete coached by the
+// requesting account, newest first. Athletes with a private profile only
+// share the sessions the coach assigned to them
+func (controller *AresController) GetAthleteSessions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		page := ctx.DefaultQuery("page", "0")
+		status, statusPresent := ctx.GetQuery("status")
+
+		athlete, coachIdHex, ok := controller.findCoachedAthlete(ctx)
+		if !ok {
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(page)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		filter := coachSessionFilter(athlete, coachIdHex)
+		if statusPresent {
+			filter["status"] = model.SessionStatus(status)
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.Session](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "exercise_sessions",
+		}, filter, options.Find().SetSort(bson.M{"timestamp": -1}).SetLimit(25).SetSkip(int64(pageNumber*25)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// AssignSession assigns a session to an athlete coached by the requesting
+// account. The session is built from the provided exercises, or from a
+// template the coach can view if no exercises are provided. The athlete
+// starts the session once they are ready to perform it
+func (controller *AresController) AssignSession() gin.HandlerFunc {
+	type Params struct {
+		SessionName string             `json:"sessionName" binding:"required"`
+		Timestamp   time.Time          `json:"timestamp" binding:"required"`
+		Exercises   []model.Exercise   `json:"exercises,omitempty" binding:"dive"`
+		Template    primitive.ObjectID `json:"template,omitempty"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		athlete, coachIdHex, ok := controller.findCoachedAthlete(ctx)
+		if !ok {
+			return
+		}
+
+		var session model.Session
+
+		if len(params.Exercises) > 0 {
+			for i, exercise := range params.Exercises {
+				params.Exercises[i] = prepareExercise(exercise)
+			}
+
+			session.Exercises = params.Exercises
+		} else if !params.Template.IsZero() {
+			template, err := database.FindDocumentById[model.WorkoutTemplate](database.QueryParams{
+				MongoClient:    controller.DB,
+				DatabaseName:   controller.DatabaseName,
+				CollectionName: "workout_template",
+			}, params.Template.Hex())
+
+			if err != nil {
+				if err == mongo.ErrNoDocuments {
+					ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "template not found"})
+					return
+				}
+
+				ctx.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+
+			canView, err := canViewTemplate(controller.DB, controller.DatabaseName, coachIdHex, template, attachedPermissions)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+				return
+			}
+
+			if !canView {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "template not found"})
+				return
+			}
+
+			session = sessionFromTemplate(template, athlete.ID)
+		} else {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "session must contain exercises or a template"})
+			return
+		}
+
+		session.SessionName = params.SessionName
+		session.Author = athlete.ID
+		session.Status = model.ASSIGNED
+		session.Timestamp = params.Timestamp
+		session.AssignedBy = coachIdHex
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "exercise_sessions",
+		}, session)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient:  controller.DB,
+			Initiator:    coachIdHex,
+			IP:           ctx.ClientIP(),
+			OtherParties: []primitive.ObjectID{athlete.ID},
+			EventName:    audit.ASSIGN_TRAINING_SESSION,
+			Context:      []string{"session id: " + inserted},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		sessionIdHex, _ := primitive.ObjectIDFromHex(inserted)
+
+		err = notification.Emit(notification.EmitParams{
+			MongoClient: controller.DB,
+			Recipient:   athlete.ID,
+			Actor:       coachIdHex,
+			Type:        notification.ASSIGNED_SESSION,
+			Subject:     sessionIdHex,
+			Text:        session.SessionName,
+		})
+
+		if err != nil {
+			fmt.Println("failed to emit notification: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// findAccessibleSession looks up the session in the path, aborting the
+// request unless the requesting account is its author or one of the author's
+// coaches allowed to see it
+func (controller *AresController) findAccessibleSession(ctx *gin.Context) (model.Session, primitive.ObjectID, bool) {
+	accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+		return model.Session{}, accountIdHex, false
+	}
+
+	sessionIdHex, err := primitive.ObjectIDFromHex(ctx.Param("sessionId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad session id hex"})
+		return model.Session{}, accountIdHex, false
+	}
+
+	session, err := database.FindDocumentById[model.Session](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: "exercise_sessions",
+	}, sessionIdHex.Hex())
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "session not found"})
+			return session, accountIdHex, false
+		}
+
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return session, accountIdHex, false
+	}
+
+	canAccess, err := canAccessSession(controller.DB, controller.DatabaseName, accountIdHex, session)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up coach link: " + err.Error()})
+		return session, accountIdHex, false
+	}
+
+	if !canAccess {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "session not found"})
+		return session, accountIdHex, false
+	}
+
+	return session, accountIdHex, true
+}
+
+// GetSessionComments returns the comments left on a session, oldest first
+func (controller *AresController) GetSessionComments() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		session, _, ok := controller.findAccessibleSession(ctx)
+		if !ok {
+			return
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.SessionComment](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "session_comment",
+		}, bson.M{"session": session.ID}, options.Find().SetSort(bson.M{"createdAt": 1}))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// CreateSessionComment leaves a comment on a session, comments can be left
+// by the session author and their coaches
+func (controller *AresController) CreateSessionComment() gin.HandlerFunc {
+	type Params struct {
+		Text string `json:"text" binding:"required"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		session, accountIdHex, ok := controller.findAccessibleSession(ctx)
+		if !ok {
+			return
+		}
+
+		comment := model.SessionComment{
+			Session:   session.ID,
+			Author:    accountIdHex,
+			Text:      params.Text,
+			CreatedAt: time.Now(),
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "session_comment",
+		}, comment)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient:  controller.DB,
+			Initiator:    accountIdHex,
+			IP:           ctx.ClientIP(),
+			OtherParties: []primitive.ObjectID{session.Author},
+			EventName:    audit.CREATE_SESSION_COMMENT,
+			Context:      []string{"session comment id: " + inserted, "session id: " + session.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		// coaches following a live session push their comments to the athlete
+		if session.Author != accountIdHex {
+			comment.ID, _ = primitive.ObjectIDFromHex(inserted)
+
+			err = realtime.Publish(session.Author, realtime.SESSION_COMMENT, comment)
+			if err != nil {
+				fmt.Println("failed to publish realtime event: ", err)
+			}
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"ares/config"
 	"ares/database"
 	"ares/model"
+	"ares/notification"
+	"ares/realtime"
 	"ares/util"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -14,7 +16,6 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"net/http"
-	"reflect"
 	"strconv"
 	"time"
 )
@@ -290,7 +291,7 @@ func (controller *AresController) GetCommentsByPostID() gin.HandlerFunc {
 			MongoClient:    controller.DB,
 			DatabaseName:   controller.DatabaseName,
 			CollectionName: controller.CollectionName,
-		}, bson.M{"post": postIdHex}, options.Find().SetLimit(10).SetSkip(int64(pageNumber*10)).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
+		}, bson.M{"post": postIdHex, "type": model.POST}, options.Find().SetLimit(10).SetSkip(int64(pageNumber*10)).SetSort(bson.D{{Key: "createdAt", Value: -1}}))
 
 		if err != nil {
 			if err == mongo.ErrNoDocuments {
@@ -306,9 +307,80 @@ func (controller *AresController) GetCommentsByPostID() gin.HandlerFunc {
 	}
 }
 
-// GetCommentCount returns a comment count for a post
+// GetCommentReplies returns a paginated list of the direct replies to the
+// comment matching the provided ID, oldest first so threads read in order
+func (controller *AresController) GetCommentReplies() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		commentIdHex, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "comment id invalid hex"})
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		comment, err := database.FindDocumentById[model.Comment](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, commentIdHex.Hex())
+
+		if err == nil {
+			var post model.Post
+			post, err = database.FindDocumentById[model.Post](database.QueryParams{
+				MongoClient:    controller.DB,
+				DatabaseName:   controller.DatabaseName,
+				CollectionName: "post",
+			}, comment.Root.Hex())
+
+			if err == nil {
+				var visible bool
+				visible, err = canViewPost(controller.DB, controller.DatabaseName, accountIdHex, post)
+				if err == nil && !visible {
+					err = mongo.ErrNoDocuments
+				}
+			}
+		}
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "comment not found"})
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		replies, err := database.FindManyDocumentsByFilterWithOpts[model.Comment](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"post": comment.ID, "type": model.COMMENT}, options.Find().SetLimit(10).SetSkip(int64(pageNumber*10)).SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": replies})
+	}
+}
+
+// GetCommentCount returns the comment count of a post, or the reply count
+// of a comment, from the counter stored on it
 //
-// 'key' param determines if we should look in the posts
+// 'key' param determines if we should look in to the posts
 // or the comments collection
 func (controller *AresController) GetCommentCount(key string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -326,12 +398,7 @@ func (controller *AresController) GetCommentCount(key string) gin.HandlerFunc {
 			postType = model.COMMENT
 		}
 
-		count, err := database.Count(database.QueryParams{
-			MongoClient:    controller.DB,
-			DatabaseName:   controller.DatabaseName,
-			CollectionName: controller.CollectionName,
-		}, bson.M{"post": idHex, "type": postType})
-
+		counters, err := findCounters(controller.DB, controller.DatabaseName, postType, idHex)
 		if err != nil {
 			if err == mongo.ErrNoDocuments {
 				ctx.AbortWithStatus(http.StatusNotFound)
@@ -342,10 +409,83 @@ func (controller *AresController) GetCommentCount(key string) gin.HandlerFunc {
 			return
 		}
 
+		count := counters.CommentCount
+		if postType == model.COMMENT {
+			count = counters.ReplyCount
+		}
+
 		ctx.JSON(http.StatusOK, gin.H{"result": count})
 	}
 }
 
+// likedItemType returns the item type likes are filtered by for the 'key'
+// param of the like handlers
+func likedItemType(key string) (model.PostItemType, bool) {
+	switch key {
+	case "post":
+		return model.POST, true
+	case "comment":
+		return model.COMMENT, true
+	}
+
+	return "", false
+}
+
+// commentCountField returns the counter a comment of the provided type is
+// counted in on the post or comment it was left on
+func commentCountField(itemType model.PostItemType) string {
+	if itemType == model.COMMENT {
+		return "replyCount"
+	}
+
+	return "commentCount"
+}
+
+// itemCounters holds the counters stored on a post or comment, posts have no
+// reply count and comments no comment count
+type itemCounters struct {
+	LikeCount    int64 `bson:"likeCount"`
+	CommentCount int64 `bson:"commentCount"`
+	ReplyCount   int64 `bson:"replyCount"`
+}
+
+// countedCollection returns the collection holding items of the provided type
+func countedCollection(itemType model.PostItemType) string {
+	if itemType == model.COMMENT {
+		return "comment"
+	}
+
+	return "post"
+}
+
+// findCounters returns the counters of the post or comment matching the
+// provided ID, so counts are served without counting the likes and comments
+func findCounters(mongoClient *mongo.Client, databaseName string, itemType model.PostItemType, id primitive.ObjectID) (itemCounters, error) {
+	return database.FindDocumentByFilter[itemCounters](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: countedCollection(itemType),
+	}, bson.M{"_id": id})
+}
+
+// incrementCount atomically adds delta to a counter of the post or comment
+// matching the provided ID. Counters are never taken below zero, any drift
+// from the likes and comments is corrected by the counter reconciliation
+func incrementCount(mongoClient *mongo.Client, databaseName string, itemType model.PostItemType, id primitive.ObjectID, field string, delta int64) error {
+	filter := bson.M{"_id": id}
+	if delta < 0 {
+		filter[field] = bson.M{"$gte": -delta}
+	}
+
+	_, err := database.UpdateOneByFilter(database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: countedCollection(itemType),
+	}, filter, bson.M{"$inc": bson.M{field: delta}})
+
+	return err
+}
+
 // GetLikeList returns a paginated list of like documents
 // for  post matching the provided ID
 //
@@ -353,7 +493,8 @@ func (controller *AresController) GetCommentCount(key string) gin.HandlerFunc {
 // or the comments collection
 func (controller *AresController) GetLikeList(key string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if key != "post" && key != "comment" {
+		itemType, ok := likedItemType(key)
+		if !ok {
 			ctx.AbortWithStatus(http.StatusBadRequest)
 			return
 		}
@@ -376,18 +517,34 @@ func (controller *AresController) GetLikeList(key string) gin.HandlerFunc {
 			MongoClient:    controller.DB,
 			DatabaseName:   controller.DatabaseName,
 			CollectionName: controller.CollectionName,
-		}, bson.M{"post": postIdHex}, options.Find().SetLimit(50).SetSkip(int64(pageNumber*50)))
+		}, bson.M{"post": postIdHex, "type": itemType}, options.
+			Find().
+			SetLimit(50).
+			SetSkip(int64(pageNumber*50)).
+			SetSort(bson.M{"likedAt": -1}))
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
 
 		ctx.JSON(http.StatusOK, gin.H{"result": likes})
 	}
 }
 
-// GetLikeCount returns a count for comments on a provided post ID
+// GetLikeCount returns the like count of the post or comment matching the
+// provided ID from the counter stored on it
 //
-// 'key' param determines if we should look in to the posts
-// or the comments collection
+// 'key' param determines if the likes of a post or of a comment
+// are counted
 func (controller *AresController) GetLikeCount(key string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		itemType, ok := likedItemType(key)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
 		id := ctx.Param("id")
 
 		idHex, err := primitive.ObjectIDFromHex(id)
@@ -396,12 +553,7 @@ func (controller *AresController) GetLikeCount(key string) gin.HandlerFunc {
 			return
 		}
 
-		count, err := database.Count(database.QueryParams{
-			MongoClient:    controller.DB,
-			DatabaseName:   controller.DatabaseName,
-			CollectionName: controller.CollectionName,
-		}, bson.M{"post": idHex})
-
+		counters, err := findCounters(controller.DB, controller.DatabaseName, itemType, idHex)
 		if err != nil {
 			if err == mongo.ErrNoDocuments {
 				ctx.AbortWithStatus(http.StatusNotFound)
@@ -412,7 +564,7 @@ func (controller *AresController) GetLikeCount(key string) gin.HandlerFunc {
 			return
 		}
 
-		ctx.JSON(http.StatusOK, gin.H{"result": count})
+		ctx.JSON(http.StatusOK, gin.H{"result": counters.LikeCount})
 	}
 }
 
@@ -503,7 +655,62 @@ func (controller *AresController) CreatePost(s3Client *s3.Client, bucket string)
 	}
 }
 
-// CreateComment creates a new comment object in the database
+// canViewPost returns true if the viewer can see the post. Accounts blocked
+// by or blocking the author can't, follower only posts need an accepted
+// follow of the author and private posts are only visible to the author
+func canViewPost(mongoClient *mongo.Client, databaseName string, viewer primitive.ObjectID, post model.Post) (bool, error) {
+	if viewer == post.Author {
+		return true, nil
+	}
+
+	blocked, err := IsBlocked(mongoClient, databaseName, viewer, post.Author)
+	if err != nil || blocked {
+		return false, err
+	}
+
+	switch post.Privacy {
+	case model.PRIVATE:
+		return false, nil
+	case model.FOLLOWER_ONLY:
+		return IsFollowing(mongoClient, databaseName, "follow", viewer, post.Author)
+	}
+
+	return true, nil
+}
+
+// canComment returns true if the commenter may comment in the threads of the
+// post, decided by the comment privacy of the post author. Authors can always
+// comment on their own posts
+func canComment(mongoClient *mongo.Client, databaseName string, commenter primitive.ObjectID, post model.Post) (bool, error) {
+	if commenter == post.Author {
+		return true, nil
+	}
+
+	author, err := database.FindDocumentById[model.Account](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "account",
+	}, post.Author.Hex())
+
+	if err != nil {
+		return false, err
+	}
+
+	switch author.Preferences.Privacy.CommentPrivacy {
+	case model.PRIVATE:
+		return false, nil
+	case model.FOLLOWER_ONLY:
+		return IsFollowing(mongoClient, databaseName, "follow", commenter, post.Author)
+	}
+
+	return true, nil
+}
+
+// CreateComment creates a new comment object in the database. Replies are
+// comments of type COMMENT on their parent comment and can be nested up to
+// model.MaxCommentDepth levels. The commenter must be able to see the post at
+// the top of the thread and be allowed to comment by the comment privacy of
+// its author
 //
 // If successful, a comment ID will be returned with the document ID
 // in a success 200 OK response
@@ -530,19 +737,44 @@ func (controller *AresController) CreateComment() gin.HandlerFunc {
 			return
 		}
 
-		// TODO: Check if authorId can make a comment on this post
+		// the author of the post or comment is notified of the new comment
+		var recipient primitive.ObjectID
+		var post model.Post
+		var depth int
+
 		if params.PostType == model.POST {
-			_, err = database.FindDocumentById[model.Post](database.QueryParams{
+			post, err = database.FindDocumentById[model.Post](database.QueryParams{
 				MongoClient:    controller.DB,
 				DatabaseName:   controller.DatabaseName,
 				CollectionName: "post",
 			}, params.Post.Hex())
+			recipient = post.Author
 		} else if params.PostType == model.COMMENT {
-			_, err = database.FindDocumentById[model.Comment](database.QueryParams{
+			var parent model.Comment
+			parent, err = database.FindDocumentById[model.Comment](database.QueryParams{
 				MongoClient:    controller.DB,
 				DatabaseName:   controller.DatabaseName,
 				CollectionName: "comment",
 			}, params.Post.Hex())
+
+			if err == nil {
+				if parent.Depth+1 >= model.MaxCommentDepth {
+					ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("replies can't be nested more than %d levels deep", model.MaxCommentDepth)})
+					return
+				}
+
+				depth = parent.Depth + 1
+				recipient = parent.Author
+
+				post, err = database.FindDocumentById[model.Post](database.QueryParams{
+					MongoClient:    controller.DB,
+					DatabaseName:   controller.DatabaseName,
+					CollectionName: "post",
+				}, parent.Root.Hex())
+			}
+		} else {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "type must be POST or COMMENT"})
+			return
 		}
 
 		if err != nil {
@@ -555,8 +787,38 @@ func (controller *AresController) CreateComment() gin.HandlerFunc {
 			return
 		}
 
+		visible, err := canViewPost(controller.DB, controller.DatabaseName, authorIdHex, post)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to check post privacy: " + err.Error()})
+			return
+		}
+
+		if !visible {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "post not found"})
+			return
+		}
+
+		allowed, err := canComment(controller.DB, controller.DatabaseName, authorIdHex, post)
+		if err == nil && allowed && recipient != post.Author {
+			var blocked bool
+			blocked, err = IsBlocked(controller.DB, controller.DatabaseName, authorIdHex, recipient)
+			allowed = !blocked
+		}
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to check comment privacy: " + err.Error()})
+			return
+		}
+
+		if !allowed {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "you can't comment on this post"})
+			return
+		}
+
 		comment := model.Comment{
 			Post:      params.Post,
+			Root:      post.ID,
+			Depth:     depth,
 			Author:    authorIdHex,
 			PostType:  params.PostType,
 			Text:      params.Text,
@@ -574,6 +836,11 @@ func (controller *AresController) CreateComment() gin.HandlerFunc {
 			return
 		}
 
+		err = incrementCount(controller.DB, controller.DatabaseName, params.PostType, params.Post, commentCountField(params.PostType), 1)
+		if err != nil {
+			fmt.Println("failed to update comment count: ", err)
+		}
+
 		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
 			MongoClient: controller.DB,
 			Initiator:   authorIdHex,
@@ -586,6 +853,28 @@ func (controller *AresController) CreateComment() gin.HandlerFunc {
 			fmt.Println("failed to save audit entry: ", err)
 		}
 
+		err = notification.Emit(notification.EmitParams{
+			MongoClient: controller.DB,
+			Recipient:   recipient,
+			Actor:       authorIdHex,
+			Type:        notification.NEW_COMMENT,
+			Subject:     params.Post,
+			Text:        params.Text,
+		})
+
+		if err != nil {
+			fmt.Println("failed to emit notification: ", err)
+		}
+
+		if recipient != authorIdHex {
+			comment.ID, _ = primitive.ObjectIDFromHex(inserted)
+
+			err = realtime.Publish(recipient, realtime.COMMENT, comment)
+			if err != nil {
+				fmt.Println("failed to publish realtime event: ", err)
+			}
+		}
+
 		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
 	}
 }
@@ -622,9 +911,12 @@ func (controller *AresController) AddLike() gin.HandlerFunc {
 			return
 		}
 
+		// the author of the post or comment is notified of the like
+		var recipient primitive.ObjectID
+
 		// TODO: Check if user can see this post
 		if params.PostType == model.POST {
-			_, err := database.FindDocumentById[model.Post](database.QueryParams{
+			post, err := database.FindDocumentById[model.Post](database.QueryParams{
 				MongoClient:    controller.DB,
 				DatabaseName:   controller.DatabaseName,
 				CollectionName: "post",
@@ -639,8 +931,10 @@ func (controller *AresController) AddLike() gin.HandlerFunc {
 				ctx.AbortWithStatus(http.StatusInternalServerError)
 				return
 			}
+
+			recipient = post.Author
 		} else if params.PostType == model.COMMENT {
-			_, err := database.FindDocumentById[model.Comment](database.QueryParams{
+			comment, err := database.FindDocumentById[model.Comment](database.QueryParams{
 				MongoClient:    controller.DB,
 				DatabaseName:   controller.DatabaseName,
 				CollectionName: "comment",
@@ -655,18 +949,10 @@ func (controller *AresController) AddLike() gin.HandlerFunc {
 				ctx.AbortWithStatus(http.StatusInternalServerError)
 				return
 			}
-		}
-
-		filter := bson.M{"post": params.Post, "author": accountIdHex}
-		existingRecord, err := database.FindDocumentByFilter[model.Like](dbQueryParams, filter)
 
-		if err != nil && err != mongo.ErrNoDocuments {
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to check for existing like record: " + err.Error()})
-			return
-		}
-
-		if !reflect.ValueOf(existingRecord).IsZero() {
-			ctx.AbortWithStatus(http.StatusConflict)
+			recipient = comment.Author
+		} else {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid item type"})
 			return
 		}
 
@@ -677,12 +963,45 @@ func (controller *AresController) AddLike() gin.HandlerFunc {
 			LikedAt:  time.Now(),
 		}
 
+		// likes are unique per item and account, a concurrent double like
+		// fails on the index rather than creating a second like
 		inserted, err := database.InsertOne(dbQueryParams, like)
 		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				ctx.AbortWithStatus(http.StatusConflict)
+				return
+			}
+
 			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
 			return
 		}
 
+		err = incrementCount(controller.DB, controller.DatabaseName, params.PostType, params.Post, "likeCount", 1)
+		if err != nil {
+			fmt.Println("failed to update like count: ", err)
+		}
+
+		err = notification.Emit(notification.EmitParams{
+			MongoClient: controller.DB,
+			Recipient:   recipient,
+			Actor:       accountIdHex,
+			Type:        notification.NEW_LIKE,
+			Subject:     params.Post,
+		})
+
+		if err != nil {
+			fmt.Println("failed to emit notification: ", err)
+		}
+
+		if recipient != accountIdHex {
+			like.ID, _ = primitive.ObjectIDFromHex(inserted)
+
+			err = realtime.Publish(recipient, realtime.LIKE, like)
+			if err != nil {
+				fmt.Println("failed to publish realtime event: ", err)
+			}
+		}
+
 		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
 	}
 }
@@ -691,7 +1010,9 @@ func (controller *AresController) AddLike() gin.HandlerFunc {
 //
 // Unlike other delete functions, this does not store the result in
 // a 'deleted' version of the database as it is arbitrary to hold on to
-func (controller *AresController) RemoveLike() gin.HandlerFunc {
+//
+// 'key' param determines if a like on a post or on a comment is removed
+func (controller *AresController) RemoveLike(key string) gin.HandlerFunc {
 	dbQueryParams := database.QueryParams{
 		MongoClient:    controller.DB,
 		DatabaseName:   controller.DatabaseName,
@@ -699,6 +1020,12 @@ func (controller *AresController) RemoveLike() gin.HandlerFunc {
 	}
 
 	return func(ctx *gin.Context) {
+		itemType, ok := likedItemType(key)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
 		accountId := ctx.GetString("accountId")
 		postId := ctx.Param("id")
 
@@ -711,9 +1038,10 @@ func (controller *AresController) RemoveLike() gin.HandlerFunc {
 		postIdHex, err := primitive.ObjectIDFromHex(postId)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad post id hex"})
+			return
 		}
 
-		filter := bson.M{"post": postIdHex, "author": accountIdHex}
+		filter := bson.M{"post": postIdHex, "type": itemType, "author": accountIdHex}
 		existingLike, err := database.FindDocumentByFilter[model.Like](dbQueryParams, filter)
 
 		if err != nil {
@@ -732,12 +1060,20 @@ func (controller *AresController) RemoveLike() gin.HandlerFunc {
 			return
 		}
 
+		err = incrementCount(controller.DB, controller.DatabaseName, itemType, postIdHex, "likeCount", -1)
+		if err != nil {
+			fmt.Println("failed to update like count: ", err)
+		}
+
 		ctx.Status(http.StatusOK)
 	}
 }
 
-// IsLiked returns a like record matching the provided post id
-func (controller *AresController) IsLiked() gin.HandlerFunc {
+// IsLiked returns the like record of the requesting account on the post or
+// comment matching the provided id
+//
+// 'key' param determines if a like on a post or on a comment is looked up
+func (controller *AresController) IsLiked(key string) gin.HandlerFunc {
 	dbQueryParams := database.QueryParams{
 		MongoClient:    controller.DB,
 		DatabaseName:   controller.DatabaseName,
@@ -745,6 +1081,12 @@ func (controller *AresController) IsLiked() gin.HandlerFunc {
 	}
 
 	return func(ctx *gin.Context) {
+		itemType, ok := likedItemType(key)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
 		accountId := ctx.GetString("accountId")
 		postId := ctx.Param("id")
 
@@ -757,9 +1099,10 @@ func (controller *AresController) IsLiked() gin.HandlerFunc {
 		postIdHex, err := primitive.ObjectIDFromHex(postId)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad post id hex"})
+			return
 		}
 
-		filter := bson.M{"post": postIdHex, "author": accountIdHex}
+		filter := bson.M{"post": postIdHex, "type": itemType, "author": accountIdHex}
 		existingLike, err := database.FindDocumentByFilter[model.Like](dbQueryParams, filter)
 
 		if err != nil {
@@ -776,6 +1119,42 @@ func (controller *AresController) IsLiked() gin.HandlerFunc {
 	}
 }
 
+// postUpdate returns the update saving the editable fields of a post. The
+// like and comment counters are left out so that increments landing while
+// the post is edited are kept, fields cleared by the edit are unset
+func postUpdate(post model.Post) bson.M {
+	set := bson.M{"editedAt": post.EditedAt}
+	unset := bson.M{}
+
+	fields := []struct {
+		key   string
+		value interface{}
+		empty bool
+	}{
+		{"location", post.Location, post.Location.IsZero()},
+		{"session", post.Session, post.Session.IsZero()},
+		{"text", post.Text, post.Text == ""},
+		{"content", post.Content, len(post.Content) == 0},
+		{"tags", post.Tags, len(post.Tags) == 0},
+		{"privacy", post.Privacy, post.Privacy == ""},
+	}
+
+	for _, field := range fields {
+		if field.empty {
+			unset[field.key] = ""
+		} else {
+			set[field.key] = field.value
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	return update
+}
+
 // UpdatePost performs an update on an existing Post document in the database
 func (controller *AresController) UpdatePost() gin.HandlerFunc {
 	dbQueryParams := database.QueryParams{
@@ -793,7 +1172,7 @@ func (controller *AresController) UpdatePost() gin.HandlerFunc {
 			return
 		}
 
-		_, err = database.FindDocumentById[model.Post](dbQueryParams, post.ID.Hex())
+		existingPost, err := database.FindDocumentById[model.Post](dbQueryParams, post.ID.Hex())
 		if err != nil {
 			if err == mongo.ErrNoDocuments {
 				ctx.AbortWithStatus(http.StatusNotFound)
@@ -805,13 +1184,13 @@ func (controller *AresController) UpdatePost() gin.HandlerFunc {
 		}
 
 		post.EditedAt = time.Now()
-		updatedCount, err := database.UpdateOne[model.Post](dbQueryParams, post.ID, post)
+		updated, err := database.UpdateOneByFilter(dbQueryParams, bson.M{"_id": existingPost.ID}, postUpdate(post))
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update document: "})
 			return
 		}
 
-		if updatedCount <= 0 {
+		if updated.MatchedCount <= 0 {
 			ctx.AbortWithStatus(http.StatusNotFound)
 			return
 		}
@@ -832,7 +1211,8 @@ func (controller *AresController) UpdatePost() gin.HandlerFunc {
 	}
 }
 
-// UpdateComment performs an update on an existing Comment document in the database
+// UpdateComment performs an update on the text of an existing Comment
+// document in the database
 func (controller *AresController) UpdateComment() gin.HandlerFunc {
 	dbQueryParams := database.QueryParams{
 		MongoClient:    controller.DB,
@@ -849,7 +1229,7 @@ func (controller *AresController) UpdateComment() gin.HandlerFunc {
 			return
 		}
 
-		_, err = database.FindDocumentById[model.Comment](dbQueryParams, comment.ID.Hex())
+		existingComment, err := database.FindDocumentById[model.Comment](dbQueryParams, comment.ID.Hex())
 		if err != nil {
 			if err == mongo.ErrNoDocuments {
 				ctx.AbortWithStatus(http.StatusNotFound)
@@ -860,21 +1240,28 @@ func (controller *AresController) UpdateComment() gin.HandlerFunc {
 			return
 		}
 
+		// only the text can be edited, the place of the comment in its
+		// thread is kept and counters are only changed by likes and replies,
+		// leaving them out keeps the increments that land during the edit
 		comment.EditedAt = time.Now()
-		updatedCount, err := database.UpdateOne(dbQueryParams, comment.ID, comment)
+		updated, err := database.UpdateOneByFilter(dbQueryParams, bson.M{"_id": existingComment.ID}, bson.M{"$set": bson.M{
+			"text":     comment.Text,
+			"editedAt": comment.EditedAt,
+		}})
+
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update comment document: " + err.Error()})
 			return
 		}
 
-		if updatedCount <= 0 {
+		if updated.MatchedCount <= 0 {
 			ctx.AbortWithStatus(http.StatusNotFound)
 			return
 		}
 
 		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
 			MongoClient: controller.DB,
-			Initiator:   comment.Author,
+			Initiator:   existingComment.Author,
 			IP:          ctx.ClientIP(),
 			EventName:   audit.UPDATE_COMMENT,
 			Context:     []string{"comment id: " + comment.ID.Hex() + " content: " + comment.Text},
@@ -1055,6 +1442,11 @@ func (controller *AresController) DeleteComment() gin.HandlerFunc {
 			return
 		}
 
+		err = incrementCount(controller.DB, controller.DatabaseName, existingComment.PostType, existingComment.Post, commentCountField(existingComment.PostType), -1)
+		if err != nil {
+			fmt.Println("failed to update comment count: ", err)
+		}
+
 		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
 			MongoClient: controller.DB,
 			Initiator:   accountIdHex,
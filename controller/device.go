@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/model"
+	"ares/push"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type RegisterDeviceTokenParams struct {
+	Platform model.DevicePlatform `json:"platform" binding:"required"`
+	Token    string               `json:"token" binding:"required"`
+}
+
+// sessionHash identifies a login session by the hash of its refresh token,
+// so the token itself is never stored alongside the device
+func sessionHash(refreshToken string) string {
+	if refreshToken == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// RegisterDeviceToken registers a push token for the app install making the
+// request. A token already registered, e.g. by another account on the same
+// device, is moved to the requesting account and session
+func (controller *AresController) RegisterDeviceToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		var params RegisterDeviceTokenParams
+		err = ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		provider, ok := push.ProviderFor(params.Platform)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "unsupported platform"})
+			return
+		}
+
+		now := time.Now()
+		set := bson.M{
+			"account":    accountIdHex,
+			"platform":   params.Platform,
+			"provider":   provider,
+			"lastSeenAt": now,
+		}
+
+		update := bson.M{"$set": set, "$setOnInsert": bson.M{"createdAt": now}}
+
+		refreshToken, _ := ctx.Cookie("refresh_token")
+		if session := sessionHash(refreshToken); session != "" {
+			set["session"] = session
+		} else {
+			update["$unset"] = bson.M{"session": ""}
+		}
+
+		_, err = database.FindOneAndUpsert[model.DeviceToken](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"token": params.Token}, update)
+
+		if err != nil && err != mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to register device token: " + err.Error()})
+			return
+		}
+
+		if err == mongo.ErrNoDocuments {
+			err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+				MongoClient: controller.DB,
+				Initiator:   accountIdHex,
+				IP:          ctx.ClientIP(),
+				EventName:   audit.REGISTER_DEVICE_TOKEN,
+				Context:     []string{"platform: " + string(params.Platform)},
+			})
+
+			if err != nil {
+				fmt.Println("failed to save audit entry: ", err)
+			}
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// GetDeviceTokens returns the push tokens registered by the requesting account
+func (controller *AresController) GetDeviceTokens() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		result, err := database.FindManyDocumentsByFilter[model.DeviceToken](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"account": accountIdHex})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// DeleteDeviceToken unregisters a push token of the requesting account
+func (controller *AresController) DeleteDeviceToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"token": ctx.Param("token"), "account": accountIdHex})
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.DELETE_DEVICE_TOKEN,
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,306 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/model"
+	"ares/nutrition"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findOwnDietEntry looks up a diet entry logged by the requesting account,
+// aborting the request if it doesn't exist or belongs to another account
+func (controller *AresController) findOwnDietEntry(ctx *gin.Context, entryId string, accountId primitive.ObjectID) (model.DietEntry, bool) {
+	_, err := primitive.ObjectIDFromHex(entryId)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad entry id hex"})
+		return model.DietEntry{}, false
+	}
+
+	entry, err := database.FindDocumentById[model.DietEntry](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}, entryId)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return entry, false
+		}
+
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return entry, false
+	}
+
+	if entry.Account != accountId {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return entry, false
+	}
+
+	return entry, true
+}
+
+// GetDietDay returns the diet entries the requesting account logged on the
+// date in the path (MM-DD-YYYY), grouped in to hourly windows with the macro
+// totals of each window and the whole day. The day starts at midnight in the
+// optional timezone query string, UTC otherwise
+func (controller *AresController) GetDietDay() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		location, err := time.LoadLocation(ctx.DefaultQuery("timezone", "UTC"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid timezone: " + err.Error()})
+			return
+		}
+
+		date, err := time.ParseInLocation("01-02-2006", ctx.Param("date"), location)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid date: " + err.Error()})
+			return
+		}
+
+		entries, err := database.FindManyDocumentsByFilterWithOpts[model.DietEntry](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{
+			"account": accountIdHex,
+			"addedAt": bson.M{"$gte": date, "$lt": date.AddDate(0, 0, 1)},
+		}, options.Find().SetSort(bson.M{"addedAt": 1}))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": nutrition.Day(date, entries, location)})
+	}
+}
+
+// CreateDietEntry logs a food from the catalog, or a serving of a recipe the
+// requesting account can view, for the requesting account. The food or the
+// recipe's per serving nutrients are copied in to the entry, servings default
+// to one and the time the food was eaten defaults to now
+func (controller *AresController) CreateDietEntry() gin.HandlerFunc {
+	type Params struct {
+		Food     primitive.ObjectID `json:"food,omitempty"`
+		Recipe   primitive.ObjectID `json:"recipe,omitempty"`
+		Servings float64            `json:"servings,omitempty"`
+		AddedAt  time.Time          `json:"addedAt,omitempty"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		if params.Food.IsZero() == params.Recipe.IsZero() {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "entry must reference either a food or a recipe"})
+			return
+		}
+
+		if params.Servings < 0 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "servings must be greater than zero"})
+			return
+		}
+
+		if params.Servings == 0 {
+			params.Servings = 1
+		}
+
+		if params.AddedAt.IsZero() {
+			params.AddedAt = time.Now()
+		}
+
+		food, err := findDietItem(controller.DB, controller.DatabaseName, params.Food, params.Recipe, func(recipe model.Recipe) (bool, error) {
+			return canViewRecipe(controller.DB, controller.DatabaseName, accountIdHex, recipe, attachedPermissions)
+		})
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments || err == errRecipeHidden {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "food or recipe not found"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up food: " + err.Error()})
+			return
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, model.DietEntry{
+			Account:  accountIdHex,
+			AddedAt:  params.AddedAt,
+			Servings: params.Servings,
+			Food:     food,
+			Recipe:   params.Recipe,
+		})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.CREATE_DIET_ENTRY,
+			Context:     []string{"entry id: " + inserted, "food id: " + params.Food.Hex(), "recipe id: " + params.Recipe.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// UpdateDietEntry changes the servings and time of a diet entry logged by the
+// requesting account
+//
+// If successful the response will return a 202 status accepted
+func (controller *AresController) UpdateDietEntry() gin.HandlerFunc {
+	type Params struct {
+		ID       primitive.ObjectID `json:"id" binding:"required"`
+		Servings float64            `json:"servings" binding:"required"`
+		AddedAt  time.Time          `json:"addedAt" binding:"required"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		if params.Servings <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "servings must be greater than zero"})
+			return
+		}
+
+		entry, ok := controller.findOwnDietEntry(ctx, params.ID.Hex(), accountIdHex)
+		if !ok {
+			return
+		}
+
+		entry.Servings = params.Servings
+		entry.AddedAt = params.AddedAt
+
+		updated, err := database.UpdateOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, entry.ID, entry)
+
+		if err != nil || updated <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.UPDATE_DIET_ENTRY,
+			Context:     []string{"entry id: " + entry.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+// DeleteDietEntry removes a diet entry logged by the requesting account and
+// creates a Deleted Diet Entry in the deleted database
+func (controller *AresController) DeleteDietEntry() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		entry, ok := controller.findOwnDietEntry(ctx, ctx.Param("entryId"), accountIdHex)
+		if !ok {
+			return
+		}
+
+		_, err = database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName + "_deleted",
+		}, model.DeletedDietEntry{
+			Entry:     entry,
+			RemovalAt: time.Now().Add(time.Hour * 24 * 7 * time.Duration(4)),
+		})
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": entry.ID})
+
+		if err != nil || deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.DELETE_DIET_ENTRY,
+			Context:     []string{"entry id: " + entry.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
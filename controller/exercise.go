@@ -4,6 +4,8 @@ import (
 	"ares/audit"
 	"ares/database"
 	"ares/model"
+	"ares/records"
+	"ares/units"
 	"ares/util"
 	"fmt"
 	"net/http"
@@ -48,7 +50,13 @@ func (controller *AresController) GetExerciseSessionByID() gin.HandlerFunc {
 			return
 		}
 
-		ctx.JSON(http.StatusOK, session)
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, units.ConvertSession(session, system))
 	}
 }
 
@@ -140,19 +148,30 @@ func (controller *AresController) GetExerciseSessionByQuery() gin.HandlerFunc {
 			return
 		}
 
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		for i, session := range result {
+			result[i] = units.ConvertSession(session, system)
+		}
+
 		ctx.JSON(http.StatusOK, gin.H{"result": result})
 	}
 }
 
 // CreateExerciseSession marshals request params and attempts to create a new
-// training session in the database.
+// training session in the database. Sessions are always authored by the
+// requesting account, an author in the params must match it
 //
 // If successful, resulting session document ID will be returned in a
 // status 200 OK response
 func (controller *AresController) CreateExerciseSession() gin.HandlerFunc {
 	type Params struct {
 		SessionName string              `json:"sessionName" binding:"required"`
-		Author      primitive.ObjectID  `json:"author" binding:"required"`
+		Author      primitive.ObjectID  `json:"author,omitempty"`
 		Status      model.SessionStatus `json:"status" binding:"required"`
 		Timestamp   time.Time           `json:"timestamp,omitempty"`
 		Exercises   []model.Exercise    `json:"exercises,omitempty" binding:"required"`
@@ -166,9 +185,24 @@ func (controller *AresController) CreateExerciseSession() gin.HandlerFunc {
 			return
 		}
 
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		if !params.Author.IsZero() && params.Author != accountIdHex {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "sessions can only be created for the requesting account"})
+			return
+		}
+
+		for i, exercise := range params.Exercises {
+			params.Exercises[i] = prepareExercise(exercise)
+		}
+
 		session := model.Session{
 			SessionName: params.SessionName,
-			Author:      params.Author,
+			Author:      accountIdHex,
 			Status:      params.Status,
 			Timestamp:   params.Timestamp,
 			Exercises:   params.Exercises,
@@ -187,7 +221,7 @@ func (controller *AresController) CreateExerciseSession() gin.HandlerFunc {
 
 		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
 			MongoClient: controller.DB,
-			Initiator:   params.Author,
+			Initiator:   accountIdHex,
 			IP:          ctx.ClientIP(),
 			EventName:   audit.CREATE_TRAINING_SESSION,
 			Context:     []string{"session id: " + inserted},
@@ -197,6 +231,16 @@ func (controller *AresController) CreateExerciseSession() gin.HandlerFunc {
 			fmt.Println("failed to save audit entry: ", err)
 		}
 
+		// sessions logged after the fact are created as completed
+		if session.Status == model.COMPLETED {
+			session.ID, _ = primitive.ObjectIDFromHex(inserted)
+
+			_, err = records.SaveCompletedSession(controller.DB, controller.DatabaseName, session)
+			if err != nil {
+				fmt.Println("failed to save personal records: ", err)
+			}
+		}
+
 		ctx.JSON(http.StatusOK, gin.H{"message": inserted})
 	}
 }
@@ -262,7 +306,7 @@ func (controller *AresController) DeleteExerciseSession() gin.HandlerFunc {
 			return
 		}
 
-		deleteResult, err := database.DeleteOne(trainingDbQueryParams, session)
+		deleteResult, err := database.DeleteOne(trainingDbQueryParams, bson.M{"_id": session.ID})
 		if err != nil {
 			if err == mongo.ErrNoDocuments {
 				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "session not found"})
@@ -312,3 +356,520 @@ func (controller *AresController) GetExerciseSessionCount() gin.HandlerFunc {
 		ctx.JSON(http.StatusOK, gin.H{"result": count})
 	}
 }
+
+// prepareExerciseSet stamps the completion time on completed sets
+// that were logged without one and stores the SI weight and distance
+func prepareExerciseSet(set model.ExerciseSet) model.ExerciseSet {
+	set = units.CanonicalizeSet(set)
+
+	if set.Completed && set.CompletedAt.IsZero() {
+		set.CompletedAt = time.Now()
+	}
+
+	if !set.Completed {
+		set.CompletedAt = time.Time{}
+	}
+
+	return set
+}
+
+// prepareAdditionalExercise prepares every set of a superset or dropset
+func prepareAdditionalExercise(additional model.AdditionalExercise) model.AdditionalExercise {
+	for i, set := range additional.Sets {
+		additional.Sets[i] = prepareExerciseSet(set)
+	}
+
+	return additional
+}
+
+// prepareExercise prepares every set of an exercise along with any
+// supersets or dropsets attached to it
+func prepareExercise(exercise model.Exercise) model.Exercise {
+	for i, set := range exercise.Sets {
+		exercise.Sets[i] = prepareExerciseSet(set)
+	}
+
+	for i, additional := range exercise.AdditionalExercise {
+		exercise.AdditionalExercise[i] = prepareAdditionalExercise(additional)
+	}
+
+	return exercise
+}
+
+// liveSessionFilter returns a filter matching an in-progress session owned by
+// the provided account at the expected version.
+//
+// Sessions created before versioning was introduced have no version field,
+// which is treated as version zero
+func liveSessionFilter(sessionId primitive.ObjectID, authorId primitive.ObjectID, version int64) bson.M {
+	var versionFilter interface{} = version
+	if version == 0 {
+		versionFilter = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	return bson.M{
+		"_id":     sessionId,
+		"author":  authorId,
+		"status":  model.IN_PROGRESS,
+		"version": versionFilter,
+	}
+}
+
+// applyLiveSessionUpdate performs an optimistic write against an in-progress
+// session and increments the session version. Additional filter conditions
+// can be provided to make sure positional paths exist before writing to them.
+//
+// If nothing was modified the session is looked up again to report why the
+// write was rejected, returning a status code and response body for the caller
+func (controller *AresController) applyLiveSessionUpdate(
+	sessionId primitive.ObjectID,
+	authorId primitive.ObjectID,
+	version int64,
+	conditions bson.M,
+	update bson.M,
+) (int, gin.H) {
+	dbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	filter := liveSessionFilter(sessionId, authorId, version)
+	for key, value := range conditions {
+		filter[key] = value
+	}
+
+	update["$inc"] = bson.M{"version": 1}
+
+	result, err := database.UpdateOneByFilter(dbQueryParams, filter, update)
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"message": "failed to update session: " + err.Error()}
+	}
+
+	if result.MatchedCount > 0 {
+		return http.StatusOK, gin.H{"version": version + 1}
+	}
+
+	return controller.resolveLiveSessionConflict(sessionId, authorId, version)
+}
+
+// resolveLiveSessionConflict looks up a session that did not match a live
+// session filter and returns the status code and response body explaining why
+func (controller *AresController) resolveLiveSessionConflict(
+	sessionId primitive.ObjectID,
+	authorId primitive.ObjectID,
+	version int64,
+) (int, gin.H) {
+	session, err := database.FindDocumentById[model.Session](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}, sessionId.Hex())
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return http.StatusNotFound, gin.H{"message": "session not found"}
+		}
+
+		return http.StatusInternalServerError, gin.H{"message": "failed to look up session: " + err.Error()}
+	}
+
+	if session.Author != authorId {
+		return http.StatusUnauthorized, gin.H{"message": "must be session author to log exercises"}
+	}
+
+	if session.Status != model.IN_PROGRESS {
+		return http.StatusConflict, gin.H{"message": "session is not in progress", "version": session.Version}
+	}
+
+	if session.Version != version {
+		return http.StatusConflict, gin.H{"message": "session has been modified", "version": session.Version}
+	}
+
+	return http.StatusNotFound, gin.H{"message": "exercise not found"}
+}
+
+// parseLiveSessionParams reads the session id, request account id and exercise
+// index (if requested) from the current request
+func parseLiveSessionParams(ctx *gin.Context, withExerciseIndex bool) (primitive.ObjectID, primitive.ObjectID, int, error) {
+	accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, 0, fmt.Errorf("bad account id hex")
+	}
+
+	sessionIdHex, err := primitive.ObjectIDFromHex(ctx.Param("sessionId"))
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, 0, fmt.Errorf("bad session id hex")
+	}
+
+	if !withExerciseIndex {
+		return sessionIdHex, accountIdHex, 0, nil
+	}
+
+	exerciseIndex, err := strconv.Atoi(ctx.Param("exerciseIndex"))
+	if err != nil || exerciseIndex < 0 {
+		return primitive.NilObjectID, primitive.NilObjectID, 0, fmt.Errorf("invalid exercise index")
+	}
+
+	return sessionIdHex, accountIdHex, exerciseIndex, nil
+}
+
+// AppendSessionExercise pushes a single exercise on to the end of an
+// in-progress session.
+//
+// The request must provide the session version it last read, if the
+// session has been modified since then a 409 Conflict is returned
+// along with the current version
+func (controller *AresController) AppendSessionExercise() gin.HandlerFunc {
+	type Params struct {
+		Version  *int64         `json:"version" binding:"required"`
+		Exercise model.Exercise `json:"exercise" binding:"required"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+
+		sessionIdHex, accountIdHex, _, err := parseLiveSessionParams(ctx, false)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		err = ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		status, body := controller.applyLiveSessionUpdate(sessionIdHex, accountIdHex, *params.Version, nil, bson.M{
+			"$push": bson.M{"exercises": prepareExercise(params.Exercise)},
+		})
+
+		ctx.JSON(status, body)
+	}
+}
+
+// AppendExerciseSet pushes a single set on to an exercise within an
+// in-progress session
+func (controller *AresController) AppendExerciseSet() gin.HandlerFunc {
+	type Params struct {
+		Version *int64            `json:"version" binding:"required"`
+		Set     model.ExerciseSet `json:"set"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+
+		sessionIdHex, accountIdHex, exerciseIndex, err := parseLiveSessionParams(ctx, true)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		err = ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		exercisePath := "exercises." + strconv.Itoa(exerciseIndex)
+
+		status, body := controller.applyLiveSessionUpdate(sessionIdHex, accountIdHex, *params.Version, bson.M{
+			exercisePath: bson.M{"$exists": true},
+		}, bson.M{
+			"$push": bson.M{exercisePath + ".sets": prepareExerciseSet(params.Set)},
+		})
+
+		ctx.JSON(status, body)
+	}
+}
+
+// UpdateExerciseSet replaces a single set of an exercise within an
+// in-progress session, e.g. when correcting a weight or marking the
+// set as completed
+func (controller *AresController) UpdateExerciseSet() gin.HandlerFunc {
+	type Params struct {
+		Version *int64            `json:"version" binding:"required"`
+		Set     model.ExerciseSet `json:"set"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+
+		sessionIdHex, accountIdHex, exerciseIndex, err := parseLiveSessionParams(ctx, true)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		setIndex, err := strconv.Atoi(ctx.Param("setIndex"))
+		if err != nil || setIndex < 0 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid set index"})
+			return
+		}
+
+		err = ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		setPath := "exercises." + strconv.Itoa(exerciseIndex) + ".sets." + strconv.Itoa(setIndex)
+
+		status, body := controller.applyLiveSessionUpdate(sessionIdHex, accountIdHex, *params.Version, bson.M{
+			setPath: bson.M{"$exists": true},
+		}, bson.M{
+			"$set": bson.M{setPath: prepareExerciseSet(params.Set)},
+		})
+
+		ctx.JSON(status, body)
+	}
+}
+
+// AppendAdditionalExercise pushes a superset or dropset on to an exercise
+// within an in-progress session
+func (controller *AresController) AppendAdditionalExercise() gin.HandlerFunc {
+	type Params struct {
+		Version            *int64                   `json:"version" binding:"required"`
+		AdditionalExercise model.AdditionalExercise `json:"additionalExercise" binding:"required"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+
+		sessionIdHex, accountIdHex, exerciseIndex, err := parseLiveSessionParams(ctx, true)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		err = ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		if params.AdditionalExercise.Type != model.SUPERSET && params.AdditionalExercise.Type != model.DROPSET {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "type must be 'SUPERSET' or 'DROPSET'"})
+			return
+		}
+
+		exercisePath := "exercises." + strconv.Itoa(exerciseIndex)
+
+		status, body := controller.applyLiveSessionUpdate(sessionIdHex, accountIdHex, *params.Version, bson.M{
+			exercisePath: bson.M{"$exists": true},
+		}, bson.M{
+			"$push": bson.M{exercisePath + ".additionalExercises": prepareAdditionalExercise(params.AdditionalExercise)},
+		})
+
+		ctx.JSON(status, body)
+	}
+}
+
+// ReorderSessionExercises rearranges the exercises of an in-progress session.
+//
+// 'order' must contain every current exercise index exactly once, where the
+// position in the array is the new position of that exercise
+func (controller *AresController) ReorderSessionExercises() gin.HandlerFunc {
+	type Params struct {
+		Version *int64 `json:"version" binding:"required"`
+		Order   []int  `json:"order" binding:"required"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+
+		sessionIdHex, accountIdHex, _, err := parseLiveSessionParams(ctx, false)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		err = ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		session, err := database.FindDocumentByFilter[model.Session](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, liveSessionFilter(sessionIdHex, accountIdHex, *params.Version))
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatusJSON(controller.resolveLiveSessionConflict(sessionIdHex, accountIdHex, *params.Version))
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up session: " + err.Error()})
+			return
+		}
+
+		if len(params.Order) != len(session.Exercises) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "order must contain every exercise index"})
+			return
+		}
+
+		seen := make([]bool, len(session.Exercises))
+		reordered := make([]model.Exercise, len(session.Exercises))
+		for position, index := range params.Order {
+			if index < 0 || index >= len(session.Exercises) || seen[index] {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "order must contain every exercise index exactly once"})
+				return
+			}
+
+			seen[index] = true
+			reordered[position] = session.Exercises[index]
+		}
+
+		status, body := controller.applyLiveSessionUpdate(sessionIdHex, accountIdHex, *params.Version, nil, bson.M{
+			"$set": bson.M{"exercises": reordered},
+		})
+
+		ctx.JSON(status, body)
+	}
+}
+
+// CompleteExerciseSession marks an in-progress session as completed and
+// stores any personal records set during the session. The session stays
+// completed when storing the records fails, the failure is logged
+//
+// If 'announce' is true and at least one record was set, a post listing the
+// new records is created on behalf of the session author
+func (controller *AresController) CompleteExerciseSession() gin.HandlerFunc {
+	type Params struct {
+		Version  *int64 `json:"version" binding:"required"`
+		Announce bool   `json:"announce,omitempty"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+
+		sessionIdHex, accountIdHex, _, err := parseLiveSessionParams(ctx, false)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		err = ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		status, body := controller.applyLiveSessionUpdate(sessionIdHex, accountIdHex, *params.Version, nil, bson.M{
+			"$set": bson.M{"status": model.COMPLETED},
+		})
+
+		if status != http.StatusOK {
+			ctx.AbortWithStatusJSON(status, body)
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.UPDATE_TRAINING_SESSION,
+			Context:     []string{"session id: " + sessionIdHex.Hex(), "status: " + string(model.COMPLETED)},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		// the session is completed at this point and can't be completed
+		// again, so failures past here are logged rather than returned
+		session, err := database.FindDocumentById[model.Session](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, sessionIdHex.Hex())
+
+		if err != nil {
+			fmt.Println("failed to look up completed session: ", err)
+			ctx.JSON(http.StatusOK, body)
+			return
+		}
+
+		personalRecords, err := records.SaveCompletedSession(controller.DB, controller.DatabaseName, session)
+		if err != nil {
+			fmt.Println("failed to save personal records: ", err)
+			ctx.JSON(http.StatusOK, body)
+			return
+		}
+
+		body["records"] = personalRecords
+
+		if params.Announce && len(personalRecords) > 0 {
+			postId, err := announcePersonalRecords(controller.DB, controller.DatabaseName, session, personalRecords)
+			if err != nil {
+				fmt.Println("failed to announce personal records: ", err)
+			} else {
+				body["post"] = postId
+			}
+		}
+
+		ctx.JSON(http.StatusOK, body)
+	}
+}
+
+// StartAssignedSession moves a session assigned to the requesting account in
+// to progress so it can be logged live. The session timestamp is set to the
+// time it was started
+func (controller *AresController) StartAssignedSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sessionIdHex, accountIdHex, _, err := parseLiveSessionParams(ctx, false)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		dbQueryParams := database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}
+
+		result, err := database.UpdateOneByFilter(dbQueryParams, bson.M{
+			"_id":    sessionIdHex,
+			"author": accountIdHex,
+			"status": model.ASSIGNED,
+		}, bson.M{
+			"$set": bson.M{"status": model.IN_PROGRESS, "timestamp": time.Now()},
+			"$inc": bson.M{"version": 1},
+		})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update document: " + err.Error()})
+			return
+		}
+
+		if result.MatchedCount <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "assigned session not found"})
+			return
+		}
+
+		session, err := database.FindDocumentById[model.Session](dbQueryParams, sessionIdHex.Hex())
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up started session: " + err.Error()})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient:  controller.DB,
+			Initiator:    accountIdHex,
+			IP:           ctx.ClientIP(),
+			OtherParties: []primitive.ObjectID{session.AssignedBy},
+			EventName:    audit.UPDATE_TRAINING_SESSION,
+			Context:      []string{"session id: " + session.ID.Hex(), "status: " + string(session.Status)},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"version": session.Version})
+	}
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/export"
+	"ares/model"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RequestDataExport starts an export of all the data held about the
+// requesting account. The export runs in the background, its status can be
+// followed with GetDataExport. Only one export can be pending at a time,
+// exports pending for longer than export.Timeout are marked as failed
+func (controller *AresController) RequestDataExport(s3Client *s3.Client, bucket string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "failed to unmarshal account id"})
+			return
+		}
+
+		jobParams := database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}
+
+		// a job left pending by a process that stopped mid export would
+		// otherwise block every later request
+		_, err = export.FailStale(controller.DB, controller.DatabaseName, time.Now())
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to expire stale exports: " + err.Error()})
+			return
+		}
+
+		job := model.ExportJob{
+			Account:     accountIdHex,
+			Status:      model.EXPORT_PENDING,
+			RequestedAt: time.Now(),
+		}
+
+		// pending jobs are unique per account, so concurrent requests can't
+		// both start an export
+		inserted, err := database.InsertOne(jobParams, job)
+		if mongo.IsDuplicateKeyError(err) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "an export is already in progress"})
+			return
+		}
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		job.ID, _ = primitive.ObjectIDFromHex(inserted)
+
+		go export.Run(controller.DB, controller.DatabaseName, s3Client, bucket, job)
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.EXPORT_ACCOUNT_DATA,
+			Context:     []string{"export job id: " + inserted},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusAccepted, gin.H{"message": inserted})
+	}
+}
+
+// GetDataExports returns every export requested by the requesting account,
+// most recent first
+func (controller *AresController) GetDataExports() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "failed to unmarshal account id"})
+			return
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.ExportJob](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"account": accountIdHex}, options.Find().SetSort(bson.M{"requestedAt": -1}))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// GetDataExport returns the status of an export requested by the requesting
+// account. Once the export is ready a temporary url to download it is
+// returned alongside
+func (controller *AresController) GetDataExport(s3Client *s3.Client, bucket string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "failed to unmarshal account id"})
+			return
+		}
+
+		jobIdHex, err := primitive.ObjectIDFromHex(ctx.Param("jobId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad export job id hex"})
+			return
+		}
+
+		job, err := database.FindDocumentByFilter[model.ExportJob](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": jobIdHex, "account": accountIdHex})
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "export job not found"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		if job.Status != model.EXPORT_READY {
+			ctx.JSON(http.StatusOK, gin.H{"result": job})
+			return
+		}
+
+		url, err := database.SignUrl(s3Client, bucket, job.Key)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to sign export url: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": job, "url": url})
+	}
+}
@@ -3,11 +3,13 @@ package controller
 import (
 	"ares/audit"
 	"ares/database"
+	"ares/model"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"net/http"
+	"time"
 )
 
 func (controller *AresController) UploadFile(s3Client *s3.Client, bucket string) gin.HandlerFunc {
@@ -55,6 +57,24 @@ func (controller *AresController) UploadFile(s3Client *s3.Client, bucket string)
 				return
 			}
 
+			// uploads are recorded so they can be found again for the account,
+			// e.g. when exporting its data
+			_, err = database.InsertOne(database.QueryParams{
+				MongoClient:    controller.DB,
+				DatabaseName:   controller.DatabaseName,
+				CollectionName: controller.CollectionName,
+			}, model.File{
+				Owner:      accountIdHex,
+				Key:        id,
+				Filename:   fileHeader.Filename,
+				Size:       fileSize,
+				UploadedAt: time.Now(),
+			})
+
+			if err != nil {
+				fmt.Println("failed to record uploaded file: ", err)
+			}
+
 			result = append(result, UploadedFile{Key: id, Filename: fileHeader.Filename})
 		}
 
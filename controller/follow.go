@@ -3,7 +3,9 @@ package controller
 import (
 	"ares/database"
 	"ares/model"
+	"ares/notification"
 	"ares/util"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,7 +18,8 @@ import (
 )
 
 // IsFollowing returns true if the provided followingId
-// and followerId has an existing record in the database
+// and followerId has an existing accepted record in the database,
+// pending follow requests are not counted as follows
 func IsFollowing(
 	mongoClient *mongo.Client,
 	databaseName string,
@@ -28,6 +31,7 @@ func IsFollowing(
 		"$and": bson.A{
 			bson.M{"followingId": followingId},
 			bson.M{"followedId": followerId},
+			bson.M{"status": model.ACCEPTED},
 		}}
 
 	_, err := database.FindDocumentByFilter[model.Follow](database.QueryParams{
@@ -225,6 +229,17 @@ func (controller *AresController) StartFollowing() gin.HandlerFunc {
 			return
 		}
 
+		blocked, err := IsBlocked(controller.DB, controller.DatabaseName, followingHex, followedHex)
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if blocked {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "can not follow this account"})
+			return
+		}
+
 		filter := bson.M{"followingId": followingHex, "followedId": followedHex}
 		existingRecord, err := database.FindDocumentByFilter[model.Follow](followDbQueryParams, filter)
 		if err != nil && err != mongo.ErrNoDocuments {
@@ -268,10 +283,152 @@ func (controller *AresController) StartFollowing() gin.HandlerFunc {
 			return
 		}
 
+		notificationType := notification.NEW_FOLLOWER
+		if status == model.PENDING {
+			notificationType = notification.FOLLOW_REQUEST
+		}
+
+		err = notification.Emit(notification.EmitParams{
+			MongoClient: controller.DB,
+			Recipient:   followedHex,
+			Actor:       followingHex,
+			Type:        notificationType,
+		})
+
+		if err != nil {
+			fmt.Println("failed to emit notification: ", err)
+		}
+
 		ctx.JSON(http.StatusOK, gin.H{"message": inserted})
 	}
 }
 
+// GetFollowRequests returns the pending follow requests sent to the
+// requesting account, newest first
+func (controller *AresController) GetFollowRequests() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		page := ctx.DefaultQuery("page", "0")
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(page)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.Follow](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"followedId": accountIdHex, "status": model.PENDING}, options.Find().SetSort(bson.M{"followedAt": -1}).SetLimit(25).SetSkip(int64(pageNumber*25)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// findFollowRequest looks up the pending follow request the account in the
+// path sent to the requesting account, aborting the request if there is none
+func (controller *AresController) findFollowRequest(ctx *gin.Context) (model.Follow, bool) {
+	accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+		return model.Follow{}, false
+	}
+
+	followingHex, err := primitive.ObjectIDFromHex(ctx.Param("followingId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "following id is not a valid hex"})
+		return model.Follow{}, false
+	}
+
+	request, err := database.FindDocumentByFilter[model.Follow](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}, bson.M{"followingId": followingHex, "followedId": accountIdHex, "status": model.PENDING})
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "follow request not found"})
+			return request, false
+		}
+
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return request, false
+	}
+
+	return request, true
+}
+
+// AcceptFollowRequest accepts a pending follow request sent to the requesting
+// account and notifies the account that sent it
+func (controller *AresController) AcceptFollowRequest() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request, ok := controller.findFollowRequest(ctx)
+		if !ok {
+			return
+		}
+
+		result, err := database.UpdateOneByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": request.ID, "status": model.PENDING}, bson.M{"$set": bson.M{"status": model.ACCEPTED}})
+
+		if err != nil || result.ModifiedCount <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to accept follow request"})
+			return
+		}
+
+		err = notification.Emit(notification.EmitParams{
+			MongoClient: controller.DB,
+			Recipient:   request.FollowingID,
+			Actor:       request.FollowedID,
+			Type:        notification.FOLLOW_ACCEPTED,
+		})
+
+		if err != nil {
+			fmt.Println("failed to emit notification: ", err)
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+// RejectFollowRequest removes a pending follow request sent to the requesting
+// account. The account that sent it is not notified
+func (controller *AresController) RejectFollowRequest() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		request, ok := controller.findFollowRequest(ctx)
+		if !ok {
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": request.ID})
+
+		if err != nil || deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to reject follow request"})
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
 func (controller *AresController) StopFollowing() gin.HandlerFunc {
 	followDbQueryParams := database.QueryParams{
 		MongoClient:    controller.DB,
@@ -0,0 +1,461 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/importer"
+	"ares/model"
+	"ares/util"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FoodParams are the editable fields of a food in the catalog
+type FoodParams struct {
+	Name          string                `json:"name" binding:"required"`
+	Barcode       string                `json:"barcode,omitempty"`
+	Measurement   model.FoodMeasureable `json:"measurement" binding:"required"`
+	Calories      uint16                `json:"calories,omitempty"`
+	Protein       uint16                `json:"protein,omitempty"`
+	Carbohydrates uint16                `json:"carbohydrates,omitempty"`
+	Fiber         uint16                `json:"fiber,omitempty"`
+	Sugar         uint16                `json:"sugar,omitempty"`
+	Fat           uint16                `json:"fat,omitempty"`
+	Cholesterol   uint16                `json:"cholesterol,omitempty"`
+	Sodium        uint16                `json:"sodium,omitempty"`
+	Potassium     uint16                `json:"potassium,omitempty"`
+	VitaminA      uint16                `json:"vitaminA,omitempty"`
+	VitaminC      uint16                `json:"vitaminc,omitempty"`
+	Calcium       uint16                `json:"calcium,omitempty"`
+	Iron          uint16                `json:"iron,omitempty"`
+}
+
+// validateFoodMeasurement returns a message describing why a measurement is
+// invalid, or an empty string if it is valid
+func validateFoodMeasurement(measurement model.FoodMeasureable) string {
+	switch measurement.Measurement {
+	case model.OUNCE, model.GRAM, model.POUND, model.KILOGRAM, model.CUP, model.SERVING:
+	default:
+		return "unknown food measurement " + string(measurement.Measurement)
+	}
+
+	if measurement.Size == 0 {
+		return "food measurement size must be greater than zero"
+	}
+
+	return ""
+}
+
+// normalize validates the params, returning a message describing the first
+// problem found or an empty string if the params are valid
+func (params *FoodParams) normalize() string {
+	if params.Barcode != "" {
+		barcode, err := importer.NormalizeBarcode(params.Barcode)
+		if err != nil {
+			return err.Error()
+		}
+
+		params.Barcode = barcode
+	}
+
+	return validateFoodMeasurement(params.Measurement)
+}
+
+// apply copies the params on to the provided food
+func (params FoodParams) apply(food model.Food) model.Food {
+	food.Name = params.Name
+	food.Barcode = params.Barcode
+	food.Measurement = params.Measurement
+	food.Calories = params.Calories
+	food.Protein = params.Protein
+	food.Carbohydrates = params.Carbohydrates
+	food.Fiber = params.Fiber
+	food.Sugar = params.Sugar
+	food.Fat = params.Fat
+	food.Cholesterol = params.Cholesterol
+	food.Sodium = params.Sodium
+	food.Potassium = params.Potassium
+	food.VitaminA = params.VitaminA
+	food.VitaminC = params.VitaminC
+	food.Calcium = params.Calcium
+	food.Iron = params.Iron
+
+	return food
+}
+
+// canEditFood returns true if the requesting account can edit or delete the
+// food. Verified foods can only be changed with the author food permission,
+// unverified foods can also be changed by the account that submitted them
+func canEditFood(food model.Food, requestAccountId primitive.ObjectID, attachedPermissions []model.Permission) bool {
+	if util.ContainsPerm(model.AUTHOR_FOOD, attachedPermissions) {
+		return true
+	}
+
+	return !food.Verified && food.Author == requestAccountId
+}
+
+// GetFoodById returns a single food from the catalog matching the provided
+// document ID
+func (controller *AresController) GetFoodById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		foodId := ctx.Param("foodId")
+
+		_, err := primitive.ObjectIDFromHex(foodId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad food id hex"})
+			return
+		}
+
+		food, err := database.FindDocumentById[model.Food](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, foodId)
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, food)
+	}
+}
+
+// GetFoodByBarcode returns the food in the catalog matching a scanned
+// barcode. UPC-A and EAN-13 forms of the same code match the same food
+func (controller *AresController) GetFoodByBarcode() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		barcode, err := importer.NormalizeBarcode(ctx.Param("barcode"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		food, err := database.FindDocumentByFilter[model.Food](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"barcode": barcode})
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, food)
+	}
+}
+
+// QueryFood searches the catalog for foods matching the name query string.
+// Verified foods are listed first, unverified foods are only listed with
+// ?verified=false
+func (controller *AresController) QueryFood() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		name, namePresent := ctx.GetQuery("name")
+		page := ctx.DefaultQuery("page", "0")
+
+		if !namePresent || name == "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "name must be provided"})
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(page)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		filter := bson.M{"name": primitive.Regex{Pattern: regexp.QuoteMeta(name), Options: "i"}}
+
+		if ctx.DefaultQuery("verified", "true") != "false" {
+			filter["verified"] = true
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.Food](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, filter, options.Find().
+			SetSort(bson.D{{Key: "verified", Value: -1}, {Key: "name", Value: 1}}).
+			SetLimit(25).
+			SetSkip(int64(pageNumber*25)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// CreateFood adds a food to the catalog. Foods created with the author food
+// permission are verified straight away, anyone else can submit unverified
+// foods which they can edit until they are verified
+func (controller *AresController) CreateFood() gin.HandlerFunc {
+	dbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	return func(ctx *gin.Context) {
+		var params FoodParams
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		message := params.normalize()
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		verified := util.ContainsPerm(model.AUTHOR_FOOD, attachedPermissions)
+
+		if verified {
+			count, err := database.Count(dbQueryParams, bson.M{"name": params.Name, "verified": true})
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+				return
+			}
+
+			if count > 0 {
+				ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "a verified food with that name already exists"})
+				return
+			}
+		}
+
+		food := params.apply(model.Food{
+			Verified:  verified,
+			Author:    accountIdHex,
+			CreatedAt: time.Now(),
+		})
+
+		inserted, err := database.InsertOne(dbQueryParams, food)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "a food with that barcode already exists"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.CREATE_FOOD,
+			Context:     []string{"food id: " + inserted, "food name: " + food.Name, fmt.Sprintf("verified: %t", verified)},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// UpdateFood replaces the fields of a food in the catalog. Accounts with the
+// author food permission can also verify or unverify the food
+//
+// If successful the response will return a 202 status accepted
+func (controller *AresController) UpdateFood() gin.HandlerFunc {
+	type Params struct {
+		ID       primitive.ObjectID `json:"id" binding:"required"`
+		Verified *bool              `json:"verified,omitempty"`
+		FoodParams
+	}
+
+	dbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		existing, err := database.FindDocumentById[model.Food](dbQueryParams, params.ID.Hex())
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if !canEditFood(existing, accountIdHex, attachedPermissions) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "missing permission to edit food"})
+			return
+		}
+
+		if params.Verified != nil && !util.ContainsPerm(model.AUTHOR_FOOD, attachedPermissions) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "missing permission to verify food"})
+			return
+		}
+
+		message := params.normalize()
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		food := params.FoodParams.apply(existing)
+		food.UpdatedAt = time.Now()
+
+		if params.Verified != nil {
+			food.Verified = *params.Verified
+		}
+
+		updated, err := database.UpdateOneByFilter(dbQueryParams, bson.M{"_id": food.ID}, importer.FoodUpdate(food))
+		if mongo.IsDuplicateKeyError(err) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "a food with that barcode already exists"})
+			return
+		}
+
+		if err != nil || updated.MatchedCount <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.UPDATE_FOOD,
+			Context:     []string{"food id: " + food.ID.Hex(), "food name: " + food.Name, fmt.Sprintf("verified: %t", food.Verified)},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+// DeleteFood removes a food from the catalog and creates a Deleted Food entry
+// in the deleted database. Diet entries keep their own copy of the food and
+// are left untouched
+func (controller *AresController) DeleteFood() gin.HandlerFunc {
+	dbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	return func(ctx *gin.Context) {
+		foodId := ctx.Param("foodId")
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		_, err = primitive.ObjectIDFromHex(foodId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad food id hex"})
+			return
+		}
+
+		existing, err := database.FindDocumentById[model.Food](dbQueryParams, foodId)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if !canEditFood(existing, accountIdHex, attachedPermissions) {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		_, err = database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName + "_deleted",
+		}, model.DeletedFood{
+			Food:      existing,
+			RemovalAt: time.Now().Add(time.Hour * 24 * 7 * time.Duration(4)),
+		})
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(dbQueryParams, bson.M{"_id": existing.ID})
+		if err != nil || deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.DELETE_FOOD,
+			Context:     []string{"food id: " + existing.ID.Hex(), "food name: " + existing.Name},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
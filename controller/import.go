@@ -0,0 +1,404 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/importer"
+	"ares/model"
+	"ares/records"
+	"ares/units"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ImportSessionsFromCSV accepts a Strong or Hevy CSV export as the "file" field
+// of a multipart form and creates a completed session for every workout in it.
+//
+// Exercise names are mapped onto known exercises, names that can't be matched
+// are returned in an unmatched report. Workouts that were already imported are
+// skipped, so the same export can be imported more than once. With ?dryRun=true
+// nothing is written and the sessions that would be created are returned instead
+func (controller *AresController) ImportSessionsFromCSV() gin.HandlerFunc {
+	type ImportResult struct {
+		Format    importer.Format      `json:"format"`
+		DryRun    bool                 `json:"dryRun"`
+		Rows      int                  `json:"rows"`
+		Inserted  []string             `json:"inserted,omitempty"`
+		Skipped   int                  `json:"skipped"`
+		Sessions  []model.Session      `json:"sessions,omitempty"`
+		Records   int                  `json:"records"`
+		Unmatched []importer.Unmatched `json:"unmatched"`
+	}
+
+	trainingDbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "failed to unmarshal account id"})
+			return
+		}
+
+		dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dryRun", "false"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid dry run flag: " + err.Error()})
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		if system == "" {
+			system = model.METRIC
+		}
+
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad multipart form data: " + err.Error()})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to read file header: " + err.Error()})
+			return
+		}
+
+		defer file.Close()
+
+		format, rows, err := importer.Parse(file, system)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to parse export: " + err.Error()})
+			return
+		}
+
+		infos, err := database.FindManyDocumentsByFilter[model.ExerciseInfo](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "exercise_info",
+		}, bson.M{})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up exercises: " + err.Error()})
+			return
+		}
+
+		matcher := importer.NewMatcher(infos)
+		sessions := importer.Group(rows, format, accountIdHex, matcher)
+
+		result := ImportResult{
+			Format:    format,
+			DryRun:    dryRun,
+			Rows:      len(rows),
+			Unmatched: matcher.Unmatched(),
+		}
+
+		if dryRun {
+			importKeys := make([]string, len(sessions))
+			for i, session := range sessions {
+				importKeys[i] = session.ImportKey
+			}
+
+			existing, err := database.FindManyDocumentsByFilter[model.Session](trainingDbQueryParams, bson.M{
+				"author":    accountIdHex,
+				"importKey": bson.M{"$in": importKeys},
+			})
+
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+				return
+			}
+
+			imported := map[string]bool{}
+			for _, session := range existing {
+				imported[session.ImportKey] = true
+			}
+
+			for _, session := range sessions {
+				if imported[session.ImportKey] {
+					result.Skipped++
+					continue
+				}
+
+				result.Sessions = append(result.Sessions, units.ConvertSession(session, system))
+			}
+
+			ctx.JSON(http.StatusOK, gin.H{"result": result})
+			return
+		}
+
+		inserted, skipped, err := database.InsertMany(trainingDbQueryParams, sessions)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert documents: " + err.Error()})
+			return
+		}
+
+		result.Inserted = inserted
+		result.Skipped = skipped
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.IMPORT_TRAINING_SESSIONS,
+			Context:     []string{"format: " + string(format), "inserted: " + strconv.Itoa(len(inserted)), "skipped: " + strconv.Itoa(skipped)},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		// sessions are read back so records are only computed for the
+		// sessions that were actually inserted, in the order they happened
+		if len(inserted) > 0 {
+			insertedIds := make([]primitive.ObjectID, len(inserted))
+			for i, id := range inserted {
+				insertedIds[i], _ = primitive.ObjectIDFromHex(id)
+			}
+
+			insertedSessions, err := database.FindManyDocumentsByFilterWithOpts[model.Session](trainingDbQueryParams, bson.M{
+				"_id": bson.M{"$in": insertedIds},
+			}, options.Find().SetSort(bson.M{"timestamp": 1}))
+
+			if err != nil {
+				fmt.Println("failed to look up imported sessions: ", err)
+			}
+
+			for _, session := range insertedSessions {
+				personalRecords, err := records.SaveCompletedSession(controller.DB, controller.DatabaseName, session)
+				if err != nil {
+					fmt.Println("failed to save personal records: ", err)
+					continue
+				}
+
+				result.Records += len(personalRecords)
+			}
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// findLocation looks up the location with the provided ID hex, aborting the
+// request if it does not exist
+func (controller *AresController) findLocation(ctx *gin.Context, locationId string) (primitive.ObjectID, bool) {
+	locationIdHex, err := primitive.ObjectIDFromHex(locationId)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad location id hex"})
+		return locationIdHex, false
+	}
+
+	_, err = database.FindDocumentById[model.Location](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: "location",
+	}, locationId)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "location not found"})
+			return locationIdHex, false
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up location: " + err.Error()})
+		return locationIdHex, false
+	}
+
+	return locationIdHex, true
+}
+
+// ImportActivity accepts a GPX, TCX or FIT file as the "file" field of a
+// multipart form and creates a completed DISTANCE_TIME session from it along
+// with the recorded route. The route can be linked to a location with
+// ?location=<id>. Importing the same activity twice returns a 409 Conflict
+func (controller *AresController) ImportActivity() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "failed to unmarshal account id"})
+			return
+		}
+
+		var locationIdHex primitive.ObjectID
+		if locationId, locationPresent := ctx.GetQuery("location"); locationPresent {
+			var ok bool
+			locationIdHex, ok = controller.findLocation(ctx, locationId)
+			if !ok {
+				return
+			}
+		}
+
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad multipart form data: " + err.Error()})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to read file header: " + err.Error()})
+			return
+		}
+
+		defer file.Close()
+
+		activity, err := importer.ParseActivity(fileHeader.Filename, file)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to parse activity: " + err.Error()})
+			return
+		}
+
+		session, route := importer.ActivitySession(activity, accountIdHex)
+		route.Location = locationIdHex
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, session)
+
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "activity already imported"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		_, err = database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "route",
+		}, route)
+
+		if err != nil {
+			// the session holds the import key, leaving it behind would make
+			// every retry of the import a conflict
+			_, deleteErr := database.DeleteOne(database.QueryParams{
+				MongoClient:    controller.DB,
+				DatabaseName:   controller.DatabaseName,
+				CollectionName: controller.CollectionName,
+			}, bson.M{"_id": session.ID})
+
+			if deleteErr != nil {
+				fmt.Println("failed to remove session of failed import: ", deleteErr)
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert route"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.IMPORT_TRAINING_SESSIONS,
+			Context:     []string{"format: " + string(activity.Format), "session id: " + inserted, "route id: " + route.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		_, err = records.SaveCompletedSession(controller.DB, controller.DatabaseName, session)
+		if err != nil {
+			fmt.Println("failed to save personal records: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted, "route": route.ID.Hex()})
+	}
+}
+
+// GetSessionRoute returns the route an imported session was recorded on
+func (controller *AresController) GetSessionRoute() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		session, _, ok := controller.findAccessibleSession(ctx)
+		if !ok {
+			return
+		}
+
+		route, err := database.FindDocumentByFilter[model.Route](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "route",
+		}, bson.M{"session": session.ID})
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "route not found"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": route})
+	}
+}
+
+// LinkRouteLocation links the route of a session to a location, only the
+// session author can link its route
+func (controller *AresController) LinkRouteLocation() gin.HandlerFunc {
+	type Params struct {
+		Location string `json:"location" binding:"required"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		session, accountIdHex, ok := controller.findAccessibleSession(ctx)
+		if !ok {
+			return
+		}
+
+		if session.Author != accountIdHex {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "only the session author can link its route"})
+			return
+		}
+
+		locationIdHex, ok := controller.findLocation(ctx, params.Location)
+		if !ok {
+			return
+		}
+
+		result, err := database.UpdateOneByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "route",
+		}, bson.M{"session": session.ID}, bson.M{"$set": bson.M{"location": locationIdHex}})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update route: " + err.Error()})
+			return
+		}
+
+		if result.MatchedCount == 0 {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "route not found"})
+			return
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
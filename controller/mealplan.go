@@ -0,0 +1,426 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/model"
+	"ares/notification"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// prepareMeals checks the days, names and items of planned meals and copies
+// the name of every food or recipe in to its item. Recipes must be viewable
+// by the coach assigning the plan. The returned message describes the first
+// problem found and is empty if the meals are valid
+func prepareMeals(
+	mongoClient *mongo.Client,
+	databaseName string,
+	coachId primitive.ObjectID,
+	meals []model.PlannedMeal,
+	attachedPermissions []model.Permission,
+) ([]model.PlannedMeal, string, error) {
+	if len(meals) == 0 {
+		return meals, "meal plan must contain at least one meal", nil
+	}
+
+	for i, meal := range meals {
+		if meal.Day == 0 {
+			return meals, "meal days start at 1", nil
+		}
+
+		if meal.Name == "" {
+			return meals, "meals must be named", nil
+		}
+
+		if len(meal.Items) == 0 {
+			return meals, "meal " + meal.Name + " must contain at least one food or recipe", nil
+		}
+
+		for j, item := range meal.Items {
+			if item.Food.IsZero() == item.Recipe.IsZero() {
+				return meals, "meal items must reference either a food or a recipe", nil
+			}
+
+			if item.Servings <= 0 {
+				return meals, "servings must be greater than zero", nil
+			}
+
+			food, err := findDietItem(mongoClient, databaseName, item.Food, item.Recipe, func(recipe model.Recipe) (bool, error) {
+				return canViewRecipe(mongoClient, databaseName, coachId, recipe, attachedPermissions)
+			})
+
+			if err != nil {
+				if err == mongo.ErrNoDocuments || err == errRecipeHidden {
+					return meals, "food or recipe in meal " + meal.Name + " not found", nil
+				}
+
+				return meals, "", err
+			}
+
+			meals[i].Items[j].Name = food.Name
+		}
+	}
+
+	return meals, "", nil
+}
+
+// findVisibleMealPlan looks up the meal plan in the path, aborting the
+// request unless the requesting account is the athlete or the coach who
+// assigned it
+func (controller *AresController) findVisibleMealPlan(ctx *gin.Context) (model.MealPlan, primitive.ObjectID, bool) {
+	accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+		return model.MealPlan{}, accountIdHex, false
+	}
+
+	planId := ctx.Param("planId")
+
+	_, err = primitive.ObjectIDFromHex(planId)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad plan id hex"})
+		return model.MealPlan{}, accountIdHex, false
+	}
+
+	plan, err := database.FindDocumentById[model.MealPlan](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}, planId)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return plan, accountIdHex, false
+		}
+
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return plan, accountIdHex, false
+	}
+
+	if plan.Account != accountIdHex && plan.AssignedBy != accountIdHex {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return plan, accountIdHex, false
+	}
+
+	return plan, accountIdHex, true
+}
+
+// AssignMealPlan assigns a meal plan to an athlete coached by the requesting
+// account. Every planned item references a food from the catalog or a recipe
+// the coach can view
+func (controller *AresController) AssignMealPlan() gin.HandlerFunc {
+	type Params struct {
+		Name        string              `json:"name" binding:"required"`
+		Description string              `json:"description,omitempty"`
+		StartDate   time.Time           `json:"startDate" binding:"required"`
+		Meals       []model.PlannedMeal `json:"meals" binding:"required"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		athlete, coachIdHex, ok := controller.findCoachedAthlete(ctx)
+		if !ok {
+			return
+		}
+
+		meals, message, err := prepareMeals(controller.DB, controller.DatabaseName, coachIdHex, params.Meals, attachedPermissions)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up foods: " + err.Error()})
+			return
+		}
+
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "meal_plan",
+		}, model.MealPlan{
+			Account:     athlete.ID,
+			AssignedBy:  coachIdHex,
+			Name:        params.Name,
+			Description: params.Description,
+			StartDate:   params.StartDate,
+			Meals:       meals,
+			CreatedAt:   time.Now(),
+		})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient:  controller.DB,
+			Initiator:    coachIdHex,
+			IP:           ctx.ClientIP(),
+			OtherParties: []primitive.ObjectID{athlete.ID},
+			EventName:    audit.ASSIGN_MEAL_PLAN,
+			Context:      []string{"plan id: " + inserted},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		planIdHex, _ := primitive.ObjectIDFromHex(inserted)
+
+		err = notification.Emit(notification.EmitParams{
+			MongoClient: controller.DB,
+			Recipient:   athlete.ID,
+			Actor:       coachIdHex,
+			Type:        notification.ASSIGNED_MEAL,
+			Subject:     planIdHex,
+			Text:        params.Name,
+		})
+
+		if err != nil {
+			fmt.Println("failed to emit notification: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// GetAthleteMealPlans returns the meal plans the requesting account assigned
+// to an athlete they coach, newest first
+func (controller *AresController) GetAthleteMealPlans() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		page := ctx.DefaultQuery("page", "0")
+
+		athlete, coachIdHex, ok := controller.findCoachedAthlete(ctx)
+		if !ok {
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(page)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.MealPlan](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "meal_plan",
+		}, bson.M{"account": athlete.ID, "assignedBy": coachIdHex}, options.Find().SetSort(bson.M{"startDate": -1}).SetLimit(25).SetSkip(int64(pageNumber*25)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// GetMealPlans returns the meal plans assigned to the requesting account,
+// newest first
+func (controller *AresController) GetMealPlans() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		page := ctx.DefaultQuery("page", "0")
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(page)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.MealPlan](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"account": accountIdHex}, options.Find().SetSort(bson.M{"startDate": -1}).SetLimit(25).SetSkip(int64(pageNumber*25)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// GetMealPlanById returns a single meal plan to the athlete it was assigned
+// to or the coach who assigned it
+func (controller *AresController) GetMealPlanById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		plan, _, ok := controller.findVisibleMealPlan(ctx)
+		if !ok {
+			return
+		}
+
+		ctx.JSON(http.StatusOK, plan)
+	}
+}
+
+// LogPlannedMeal logs every item of a meal in a meal plan as a diet entry of
+// the requesting athlete. Items are logged from the current catalog, so a
+// food or recipe removed since the plan was assigned fails the request. The
+// time the meal was eaten defaults to now
+func (controller *AresController) LogPlannedMeal() gin.HandlerFunc {
+	type Params struct {
+		AddedAt time.Time `json:"addedAt,omitempty"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+
+		// the body is optional
+		if ctx.Request.ContentLength > 0 {
+			err := ctx.ShouldBindJSON(&params)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+				return
+			}
+		}
+
+		plan, accountIdHex, ok := controller.findVisibleMealPlan(ctx)
+		if !ok {
+			return
+		}
+
+		if plan.Account != accountIdHex {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "only the athlete can log a planned meal"})
+			return
+		}
+
+		mealIndex, err := strconv.Atoi(ctx.Param("mealIndex"))
+		if err != nil || mealIndex < 0 || mealIndex >= len(plan.Meals) {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid meal index"})
+			return
+		}
+
+		if params.AddedAt.IsZero() {
+			params.AddedAt = time.Now()
+		}
+
+		var entries []model.DietEntry
+
+		for _, item := range plan.Meals[mealIndex].Items {
+			food, err := findDietItem(controller.DB, controller.DatabaseName, item.Food, item.Recipe, nil)
+			if err != nil {
+				if err == mongo.ErrNoDocuments {
+					ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": item.Name + " is no longer available"})
+					return
+				}
+
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up food: " + err.Error()})
+				return
+			}
+
+			entries = append(entries, model.DietEntry{
+				Account:  accountIdHex,
+				AddedAt:  params.AddedAt,
+				Servings: item.Servings,
+				Food:     food,
+				Recipe:   item.Recipe,
+				MealPlan: plan.ID,
+			})
+		}
+
+		inserted, _, err := database.InsertMany(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "diet_entry",
+		}, entries)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert documents"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.LOG_PLANNED_MEAL,
+			Context:     []string{"plan id: " + plan.ID.Hex(), "meal index: " + strconv.Itoa(mealIndex)},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"result": inserted})
+	}
+}
+
+// DeleteMealPlan removes a meal plan from the primary database and creates a
+// Deleted Meal Plan entry in the deleted database. Either the athlete or the
+// coach who assigned the plan can remove it
+func (controller *AresController) DeleteMealPlan() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		plan, accountIdHex, ok := controller.findVisibleMealPlan(ctx)
+		if !ok {
+			return
+		}
+
+		_, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName + "_deleted",
+		}, model.DeletedMealPlan{
+			Plan:      plan,
+			RemovalAt: time.Now().Add(time.Hour * 24 * 7 * time.Duration(4)),
+		})
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": plan.ID})
+
+		if err != nil || deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient:  controller.DB,
+			Initiator:    accountIdHex,
+			IP:           ctx.ClientIP(),
+			OtherParties: []primitive.ObjectID{plan.Account, plan.AssignedBy},
+			EventName:    audit.DELETE_MEAL_PLAN,
+			Context:      []string{"plan id: " + plan.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,673 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/config"
+	"ares/database"
+	"ares/model"
+	"ares/notification"
+	"ares/realtime"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CreateConversationParams struct {
+	Participants []string `json:"participants" binding:"required"`
+	Name         string   `json:"name,omitempty"`
+}
+
+type SendMessageParams struct {
+	Text        string   `json:"text,omitempty"`
+	Attachments []string `json:"attachments,omitempty"`
+}
+
+// canMessage returns true if the sender may message the recipient. Blocks in
+// either direction always deny, otherwise the message privacy of the
+// recipient decides: follower only requires an accepted follow of the
+// recipient by the sender and private denies everyone
+func canMessage(mongoClient *mongo.Client, databaseName string, sender primitive.ObjectID, recipient model.Account) (bool, error) {
+	blocked, err := IsBlocked(mongoClient, databaseName, sender, recipient.ID)
+	if err != nil || blocked {
+		return false, err
+	}
+
+	switch recipient.Preferences.Privacy.MessagePrivacy {
+	case model.PRIVATE:
+		return false, nil
+	case model.FOLLOWER_ONLY:
+		return IsFollowing(mongoClient, databaseName, "follow", sender, recipient.ID)
+	}
+
+	return true, nil
+}
+
+// findConversation looks up a conversation the account participates in,
+// aborting the request if it can't be found
+func (controller *AresController) findConversation(ctx *gin.Context, conversationId string, accountId primitive.ObjectID) (model.Conversation, bool) {
+	conversationIdHex, err := primitive.ObjectIDFromHex(conversationId)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad conversation id hex"})
+		return model.Conversation{}, false
+	}
+
+	conversation, err := database.FindDocumentByFilter[model.Conversation](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}, bson.M{"_id": conversationIdHex, "participants": accountId})
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return model.Conversation{}, false
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform conversation query: " + err.Error()})
+		return model.Conversation{}, false
+	}
+
+	return conversation, true
+}
+
+// hideBlockedLastMessages clears the last message of group conversations
+// written by an account that has a block with the reading account, the
+// messages themselves are left out when the conversation is read
+func hideBlockedLastMessages(mongoClient *mongo.Client, databaseName string, accountId primitive.ObjectID, conversations []model.Conversation) error {
+	var authors []primitive.ObjectID
+	for _, conversation := range conversations {
+		if conversation.Group && conversation.LastMessage != nil {
+			authors = append(authors, conversation.LastMessage.Author)
+		}
+	}
+
+	if len(authors) == 0 {
+		return nil
+	}
+
+	blocked, err := BlockedAmong(mongoClient, databaseName, accountId, authors)
+	if err != nil {
+		return err
+	}
+
+	for i := range conversations {
+		if conversations[i].Group && conversations[i].LastMessage != nil && blocked[conversations[i].LastMessage.Author] {
+			conversations[i].LastMessage = nil
+		}
+	}
+
+	return nil
+}
+
+// CreateConversation starts a conversation between the requesting account and
+// the provided participants. A conversation with a single other participant
+// is direct and an existing direct conversation between the two accounts is
+// returned instead of starting a new one. Every participant must accept
+// messages from the requesting account
+func (controller *AresController) CreateConversation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		var params CreateConversationParams
+		err = ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		participants := []primitive.ObjectID{accountIdHex}
+		seen := map[primitive.ObjectID]bool{accountIdHex: true}
+
+		for _, participant := range params.Participants {
+			participantHex, err := primitive.ObjectIDFromHex(participant)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad participant id hex"})
+				return
+			}
+
+			if !seen[participantHex] {
+				seen[participantHex] = true
+				participants = append(participants, participantHex)
+			}
+		}
+
+		if len(participants) < 2 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "a conversation needs another participant"})
+			return
+		}
+
+		if len(participants) > model.MaxConversationParticipants {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("a conversation can have at most %d participants", model.MaxConversationParticipants)})
+			return
+		}
+
+		group := len(participants) > 2
+
+		if !group {
+			existing, err := database.FindDocumentByFilter[model.Conversation](database.QueryParams{
+				MongoClient:    controller.DB,
+				DatabaseName:   controller.DatabaseName,
+				CollectionName: controller.CollectionName,
+			}, bson.M{"group": false, "participants": bson.M{"$all": participants}})
+
+			if err == nil {
+				ctx.JSON(http.StatusOK, gin.H{"message": existing.ID.Hex()})
+				return
+			}
+
+			if err != mongo.ErrNoDocuments {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform conversation query: " + err.Error()})
+				return
+			}
+		}
+
+		for _, participant := range participants[1:] {
+			account, err := database.FindDocumentById[model.Account](database.QueryParams{
+				MongoClient:    controller.DB,
+				DatabaseName:   controller.DatabaseName,
+				CollectionName: "account",
+			}, participant.Hex())
+
+			if err != nil {
+				if err == mongo.ErrNoDocuments {
+					ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "participant not found: " + participant.Hex()})
+					return
+				}
+
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up participant: " + err.Error()})
+				return
+			}
+
+			allowed, err := canMessage(controller.DB, controller.DatabaseName, accountIdHex, account)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to check message privacy: " + err.Error()})
+				return
+			}
+
+			if !allowed {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": account.Username + " does not accept messages from you"})
+				return
+			}
+		}
+
+		now := time.Now()
+		conversation := model.Conversation{
+			Participants: participants,
+			Creator:      accountIdHex,
+			Group:        group,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+
+		if group {
+			conversation.Name = strings.TrimSpace(params.Name)
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, conversation)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document: " + err.Error()})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.CREATE_CONVERSATION,
+			Context:     []string{"conversation: " + inserted, fmt.Sprintf("participants: %d", len(participants))},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// GetConversations returns the conversations of the requesting account, most
+// recently active first. The last message of a group is hidden when its
+// author and the requesting account have a block between them
+func (controller *AresController) GetConversations() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(ctx.DefaultQuery("page", "0"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.Conversation](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"participants": accountIdHex}, options.
+			Find().
+			SetLimit(25).
+			SetSkip(int64(pageNumber*25)).
+			SetSort(bson.M{"updatedAt": -1}))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		err = hideBlockedLastMessages(controller.DB, controller.DatabaseName, accountIdHex, result)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to check blocks: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// GetConversationById returns a single conversation of the requesting account
+func (controller *AresController) GetConversationById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		conversation, ok := controller.findConversation(ctx, ctx.Param("conversationId"), accountIdHex)
+		if !ok {
+			return
+		}
+
+		conversations := []model.Conversation{conversation}
+		err = hideBlockedLastMessages(controller.DB, controller.DatabaseName, accountIdHex, conversations)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to check blocks: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": conversations[0]})
+	}
+}
+
+// GetMessages returns the messages of a conversation, newest first, with
+// signed urls for their attachments. Pages are requested with the next cursor
+// of the previous page in the before query string and hold up to the limit
+// query string messages, 25 by default. Messages in a group by members that
+// have a block with the requesting account are left out
+func (controller *AresController) GetMessages() gin.HandlerFunc {
+	conf := config.Get()
+	bucket := conf.S3.Bucket
+
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		conversation, ok := controller.findConversation(ctx, ctx.Param("conversationId"), accountIdHex)
+		if !ok {
+			return
+		}
+
+		limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+		if err != nil || limit <= 0 || limit > 100 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "limit must be between 1 and 100"})
+			return
+		}
+
+		filter := bson.M{"conversation": conversation.ID}
+
+		if before, present := ctx.GetQuery("before"); present {
+			cursor, err := primitive.ObjectIDFromHex(before)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad cursor"})
+				return
+			}
+
+			filter["_id"] = bson.M{"$lt": cursor}
+		}
+
+		if conversation.Group {
+			blocked, err := BlockedAmong(controller.DB, controller.DatabaseName, accountIdHex, conversation.Participants)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to check blocks: " + err.Error()})
+				return
+			}
+
+			if len(blocked) > 0 {
+				var authors []primitive.ObjectID
+				for author := range blocked {
+					authors = append(authors, author)
+				}
+
+				filter["author"] = bson.M{"$nin": authors}
+			}
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.Message](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "message",
+		}, filter, options.Find().SetSort(bson.M{"_id": -1}).SetLimit(int64(limit)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		for i := range result {
+			for _, key := range result[i].Attachments {
+				signed, err := database.SignUrl(controller.S3, bucket, key)
+				if err != nil {
+					ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to sign url for attachment"})
+					return
+				}
+
+				result[i].AttachmentUrls = append(result[i].AttachmentUrls, signed)
+			}
+		}
+
+		next := ""
+		if len(result) == limit {
+			next = result[len(result)-1].ID.Hex()
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result, "next": next})
+	}
+}
+
+// SendMessage sends a message to a conversation of the requesting account.
+// Messages need text or attachments, attachments must be files the account
+// uploaded. Direct messages are checked against blocks and the message
+// privacy of the other participant on every send. Group members with a block
+// against the author are not sent the message, the rest are notified unless
+// they muted the conversation
+func (controller *AresController) SendMessage() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		conversation, ok := controller.findConversation(ctx, ctx.Param("conversationId"), accountIdHex)
+		if !ok {
+			return
+		}
+
+		var params SendMessageParams
+		err = ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		params.Text = strings.TrimSpace(params.Text)
+		if params.Text == "" && len(params.Attachments) == 0 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "a message needs text or attachments"})
+			return
+		}
+
+		owned, err := ownsFiles(controller.DB, controller.DatabaseName, accountIdHex, params.Attachments)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up attachments: " + err.Error()})
+			return
+		}
+
+		if !owned {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "attachments must be files you uploaded"})
+			return
+		}
+
+		if !conversation.Group {
+			for _, participant := range conversation.Participants {
+				if participant == accountIdHex {
+					continue
+				}
+
+				account, err := database.FindDocumentById[model.Account](database.QueryParams{
+					MongoClient:    controller.DB,
+					DatabaseName:   controller.DatabaseName,
+					CollectionName: "account",
+				}, participant.Hex())
+
+				if err != nil {
+					ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up participant: " + err.Error()})
+					return
+				}
+
+				allowed, err := canMessage(controller.DB, controller.DatabaseName, accountIdHex, account)
+				if err != nil {
+					ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to check message privacy: " + err.Error()})
+					return
+				}
+
+				if !allowed {
+					ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": account.Username + " does not accept messages from you"})
+					return
+				}
+			}
+		}
+
+		// a group stays usable when two of its members block each other,
+		// they just stop receiving each other's messages
+		blocked := map[primitive.ObjectID]bool{}
+		if conversation.Group {
+			blocked, err = BlockedAmong(controller.DB, controller.DatabaseName, accountIdHex, conversation.Participants)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to check blocks: " + err.Error()})
+				return
+			}
+		}
+
+		message := model.Message{
+			Conversation: conversation.ID,
+			Author:       accountIdHex,
+			Text:         params.Text,
+			Attachments:  params.Attachments,
+			CreatedAt:    time.Now(),
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "message",
+		}, message)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document: " + err.Error()})
+			return
+		}
+
+		message.ID, _ = primitive.ObjectIDFromHex(inserted)
+
+		_, err = database.UpdateOneByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": conversation.ID}, bson.M{"$set": bson.M{
+			"lastMessage":                 message,
+			"updatedAt":                   message.CreatedAt,
+			"reads." + accountIdHex.Hex(): model.ReadReceipt{Message: message.ID, ReadAt: message.CreatedAt},
+		}})
+
+		if err != nil {
+			fmt.Println("failed to update conversation: ", err)
+		}
+
+		for _, participant := range conversation.Participants {
+			if blocked[participant] {
+				continue
+			}
+
+			// every participant's streams receive the message, including the
+			// other devices of the author, muting only silences notifications
+			err = realtime.Publish(participant, realtime.MESSAGE, message)
+			if err != nil {
+				fmt.Println("failed to publish realtime event: ", err)
+			}
+
+			if participant == accountIdHex || conversation.IsMuted(participant) {
+				continue
+			}
+
+			err = notification.Emit(notification.EmitParams{
+				MongoClient: controller.DB,
+				Recipient:   participant,
+				Actor:       accountIdHex,
+				Type:        notification.NEW_MESSAGE,
+				Subject:     conversation.ID,
+				Text:        message.Text,
+			})
+
+			if err != nil {
+				fmt.Println("failed to emit notification: ", err)
+			}
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// MarkConversationRead records that the requesting account has read every
+// message in the conversation so far
+func (controller *AresController) MarkConversationRead() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		conversation, ok := controller.findConversation(ctx, ctx.Param("conversationId"), accountIdHex)
+		if !ok {
+			return
+		}
+
+		if conversation.LastMessage == nil {
+			ctx.Status(http.StatusOK)
+			return
+		}
+
+		_, err = database.UpdateOneByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": conversation.ID}, bson.M{"$set": bson.M{
+			"reads." + accountIdHex.Hex(): model.ReadReceipt{Message: conversation.LastMessage.ID, ReadAt: time.Now()},
+		}})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update conversation: " + err.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// MuteConversation mutes or unmutes the conversation for the requesting
+// account. Muted conversations do not notify about new messages
+func (controller *AresController) MuteConversation(mute bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		conversation, ok := controller.findConversation(ctx, ctx.Param("conversationId"), accountIdHex)
+		if !ok {
+			return
+		}
+
+		operator := "$pull"
+		if mute {
+			operator = "$addToSet"
+		}
+
+		_, err = database.UpdateOneByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": conversation.ID}, bson.M{operator: bson.M{"muted": accountIdHex}})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update conversation: " + err.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+// LeaveConversation removes the requesting account from a group conversation.
+// Their messages stay in the conversation
+func (controller *AresController) LeaveConversation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		conversation, ok := controller.findConversation(ctx, ctx.Param("conversationId"), accountIdHex)
+		if !ok {
+			return
+		}
+
+		if !conversation.Group {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "only group conversations can be left"})
+			return
+		}
+
+		_, err = database.UpdateOneByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": conversation.ID}, bson.M{
+			"$pull":  bson.M{"participants": accountIdHex, "muted": accountIdHex},
+			"$unset": bson.M{"reads." + accountIdHex.Hex(): ""},
+		})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update conversation: " + err.Error()})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.LEAVE_CONVERSATION,
+			Context:     []string{"conversation: " + conversation.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
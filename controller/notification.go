@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"ares/database"
+	"ares/notification"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetNotifications returns the inbox of the requesting account, newest first.
+// Pages are requested with the next cursor of the previous page in the before
+// query string and hold up to the limit query string notifications, 25 by
+// default. The optional unread query string only returns unread notifications
+func (controller *AresController) GetNotifications() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+		if err != nil || limit <= 0 || limit > 100 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "limit must be between 1 and 100"})
+			return
+		}
+
+		filter := bson.M{"recipient": accountIdHex}
+
+		if before, present := ctx.GetQuery("before"); present {
+			cursor, err := primitive.ObjectIDFromHex(before)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad cursor"})
+				return
+			}
+
+			filter["_id"] = bson.M{"$lt": cursor}
+		}
+
+		if ctx.Query("unread") == "true" {
+			filter["read"] = false
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[notification.Notification](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, filter, options.Find().SetSort(bson.M{"_id": -1}).SetLimit(int64(limit)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		next := ""
+		if len(result) == limit {
+			next = result[len(result)-1].ID.Hex()
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result, "next": next})
+	}
+}
+
+// GetUnreadNotificationCount returns the number of unread notifications in
+// the inbox of the requesting account
+func (controller *AresController) GetUnreadNotificationCount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		count, err := database.Count(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"recipient": accountIdHex, "read": false})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to count notifications: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": count})
+	}
+}
+
+// MarkNotificationsRead marks notifications in the inbox of the requesting
+// account as read. The notification in the path is marked when present,
+// otherwise every unread notification is
+//
+// If successful the response will contain the number of notifications marked
+func (controller *AresController) MarkNotificationsRead() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		filter := bson.M{"recipient": accountIdHex, "read": false}
+
+		if notificationId := ctx.Param("notificationId"); notificationId != "" {
+			notificationIdHex, err := primitive.ObjectIDFromHex(notificationId)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad notification id hex"})
+				return
+			}
+
+			filter["_id"] = notificationIdHex
+		}
+
+		result, err := database.UpdateManyByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, filter, bson.M{"$set": bson.M{"read": true, "readAt": time.Now()}})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update notifications: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result.ModifiedCount})
+	}
+}
+
+// DeleteNotification removes a notification from the inbox of the requesting
+// account
+func (controller *AresController) DeleteNotification() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		notificationIdHex, err := primitive.ObjectIDFromHex(ctx.Param("notificationId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad notification id hex"})
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"_id": notificationIdHex, "recipient": accountIdHex})
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
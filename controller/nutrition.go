@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/model"
+	"ares/nutrition"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetNutritionTarget returns the daily nutrition target of the requesting
+// account, or a 404 if no target has been set
+func (controller *AresController) GetNutritionTarget() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		target, err := database.FindDocumentByFilter[model.NutritionTarget](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"account": accountIdHex})
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, target)
+	}
+}
+
+// UpdateNutritionTarget sets the daily nutrition target of the requesting
+// account. With an activity level the target is derived from the account
+// biometrics and the optional calorie adjustment, otherwise the calories are
+// required and the macros default to the standard split of the calories
+//
+// If successful the response will contain the saved target
+func (controller *AresController) UpdateNutritionTarget() gin.HandlerFunc {
+	type Params struct {
+		Calories      float64             `json:"calories,omitempty"`
+		Protein       float64             `json:"protein,omitempty"`
+		Carbohydrates float64             `json:"carbohydrates,omitempty"`
+		Fat           float64             `json:"fat,omitempty"`
+		Fiber         float64             `json:"fiber,omitempty"`
+		ActivityLevel model.ActivityLevel `json:"activityLevel,omitempty"`
+		Adjustment    float64             `json:"adjustment,omitempty"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		account, err := database.FindDocumentById[model.Account](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "account",
+		}, ctx.GetString("accountId"))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "failed to find account attached to request account id"})
+			return
+		}
+
+		var target model.NutritionTarget
+
+		if params.ActivityLevel != "" {
+			target, err = nutrition.Derive(account, params.ActivityLevel, params.Adjustment, time.Now())
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				return
+			}
+		} else {
+			if params.Calories <= 0 || params.Protein < 0 || params.Carbohydrates < 0 || params.Fat < 0 || params.Fiber < 0 {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "calories must be greater than zero and macros can't be negative"})
+				return
+			}
+
+			target = model.NutritionTarget{
+				Account:       account.ID,
+				Calories:      params.Calories,
+				Protein:       params.Protein,
+				Carbohydrates: params.Carbohydrates,
+				Fat:           params.Fat,
+				Fiber:         params.Fiber,
+				UpdatedAt:     time.Now(),
+			}
+
+			if params.Protein == 0 && params.Carbohydrates == 0 && params.Fat == 0 {
+				split := nutrition.Split(params.Calories)
+				target.Protein = split.Protein
+				target.Carbohydrates = split.Carbohydrates
+				target.Fat = split.Fat
+			}
+		}
+
+		_, err = database.FindOneAndUpsert[model.NutritionTarget](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{"account": account.ID}, bson.M{"$set": target})
+
+		if err != nil && err != mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to save target: " + err.Error()})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   account.ID,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.UPDATE_NUTRITION_TARGET,
+			Context:     []string{fmt.Sprintf("calories: %.0f", target.Calories), fmt.Sprintf("derived: %t", target.Derived)},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": target})
+	}
+}
+
+// GetNutritionSummary compares the macros the requesting account logged in
+// the provided number of days, starting at the date in the path (MM-DD-YYYY),
+// against its nutrition target. Accounts without a target still receive what
+// they logged with an empty target
+func (controller *AresController) GetNutritionSummary(days int) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		location, err := time.LoadLocation(ctx.DefaultQuery("timezone", "UTC"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid timezone: " + err.Error()})
+			return
+		}
+
+		from, err := time.ParseInLocation("01-02-2006", ctx.Param("date"), location)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid date: " + err.Error()})
+			return
+		}
+
+		target, err := database.FindDocumentByFilter[model.NutritionTarget](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "nutrition_target",
+		}, bson.M{"account": accountIdHex})
+
+		if err != nil && err != mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up target: " + err.Error()})
+			return
+		}
+
+		entries, err := database.FindManyDocumentsByFilter[model.DietEntry](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, bson.M{
+			"account": accountIdHex,
+			"addedAt": bson.M{"$gte": from, "$lt": from.AddDate(0, 0, days)},
+		})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": nutrition.Summarize(from, days, entries, target.Macros(), location)})
+	}
+}
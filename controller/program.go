@@ -0,0 +1,895 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/model"
+	"ares/programs"
+	"ares/util"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// canViewProgram returns true if the requesting account can view and enroll
+// in the program
+func canViewProgram(
+	mongoClient *mongo.Client,
+	databaseName string,
+	requestAccountId primitive.ObjectID,
+	program model.Program,
+	attachedPermissions []model.Permission,
+) (bool, error) {
+	if program.Author == requestAccountId || util.ContainsPerm(model.BYPASS_PRIVACY, attachedPermissions) {
+		return true, nil
+	}
+
+	switch program.Privacy {
+	case model.PUBLIC:
+		return true, nil
+	case model.FOLLOWER_ONLY:
+		return IsFollowing(mongoClient, databaseName, "follow", requestAccountId, program.Author)
+	}
+
+	return false, nil
+}
+
+// validateProgramParams checks the name, privacy and schedule of a program.
+// Every referenced template must exist and either belong to the program
+// author or be public, and every load must target an exercise of its
+// template. The returned message describes the first problem found and is
+// empty if the params are valid
+func validateProgramParams(
+	mongoClient *mongo.Client,
+	databaseName string,
+	authorId primitive.ObjectID,
+	name string,
+	privacy model.PrivacyLevel,
+	weeks []model.ProgramWeek,
+) (string, error) {
+	match := util.IsAlphanumericWithWhitespace(name)
+	if match {
+		return "program name must be alphanumeric", nil
+	}
+
+	if privacy != model.PUBLIC && privacy != model.FOLLOWER_ONLY && privacy != model.PRIVATE {
+		return "unknown privacy level " + string(privacy), nil
+	}
+
+	if len(weeks) == 0 {
+		return "program must contain at least one week", nil
+	}
+
+	var templateIds []primitive.ObjectID
+	for weekIndex, week := range weeks {
+		var scheduled []uint8
+
+		for _, day := range week.Days {
+			if day.Day < 1 || day.Day > 7 {
+				return "week " + strconv.Itoa(weekIndex+1) + " has a day outside of 1-7", nil
+			}
+
+			if util.Contains(day.Day, scheduled) {
+				return "week " + strconv.Itoa(weekIndex+1) + " schedules day " + strconv.Itoa(int(day.Day)) + " twice", nil
+			}
+
+			scheduled = append(scheduled, day.Day)
+			templateIds = append(templateIds, day.Template)
+		}
+	}
+
+	templates, err := database.FindManyDocumentsByFilter[model.WorkoutTemplate](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "workout_template",
+	}, bson.M{"_id": bson.M{"$in": templateIds}})
+
+	if err != nil {
+		return "", err
+	}
+
+	templateById := map[primitive.ObjectID]model.WorkoutTemplate{}
+	for _, template := range templates {
+		templateById[template.ID] = template
+	}
+
+	for _, week := range weeks {
+		for _, day := range week.Days {
+			template, exists := templateById[day.Template]
+			if !exists || (template.Author != authorId && template.Privacy != model.PUBLIC) {
+				return "template " + day.Template.Hex() + " not found", nil
+			}
+
+			for _, load := range day.Loads {
+				if load.Percentage < 0 {
+					return "load percentage must be positive", nil
+				}
+
+				found := false
+				for _, exercise := range template.Exercises {
+					if exercise.Exercise == load.Exercise {
+						found = true
+						break
+					}
+				}
+
+				if !found {
+					return "exercise " + load.Exercise.Hex() + " is not part of template " + template.ID.Hex(), nil
+				}
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// GetProgramById returns a single program matching the provided document ID
+// if the requesting account is allowed to view it
+func (controller *AresController) GetProgramById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		programId := ctx.Param("programId")
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		_, err = primitive.ObjectIDFromHex(programId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad program id hex"})
+			return
+		}
+
+		program, err := database.FindDocumentById[model.Program](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, programId)
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		canView, err := canViewProgram(controller.DB, controller.DatabaseName, accountIdHex, program, attachedPermissions)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+			return
+		}
+
+		if !canView {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, program)
+	}
+}
+
+// GetProgramsByAccount returns the programs authored by the provided account
+// id. Accounts other than the author only see the programs shared with them
+func (controller *AresController) GetProgramsByAccount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorId := ctx.Param("accountId")
+		page := ctx.DefaultQuery("page", "0")
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		authorIdHex, err := primitive.ObjectIDFromHex(authorId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad author id hex"})
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(page)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		filter := bson.M{"author": authorIdHex}
+
+		if authorIdHex != accountIdHex && !util.ContainsPerm(model.BYPASS_PRIVACY, attachedPermissions) {
+			visible := bson.A{model.PUBLIC}
+
+			following, err := IsFollowing(controller.DB, controller.DatabaseName, "follow", accountIdHex, authorIdHex)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+				return
+			}
+
+			if following {
+				visible = append(visible, model.FOLLOWER_ONLY)
+			}
+
+			filter["privacy"] = bson.M{"$in": visible}
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.Program](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, filter, options.Find().SetSort(bson.M{"name": 1}).SetLimit(25).SetSkip(int64(pageNumber*25)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// CreateProgram creates a new program authored by the requesting account and
+// returns the newly created document ID
+func (controller *AresController) CreateProgram() gin.HandlerFunc {
+	type Params struct {
+		Name        string              `json:"name" binding:"required"`
+		Description string              `json:"description,omitempty"`
+		Privacy     model.PrivacyLevel  `json:"privacy,omitempty"`
+		Weeks       []model.ProgramWeek `json:"weeks" binding:"required,dive"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		if params.Privacy == "" {
+			params.Privacy = model.PRIVATE
+		}
+
+		message, err := validateProgramParams(controller.DB, controller.DatabaseName, accountIdHex, params.Name, params.Privacy, params.Weeks)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up templates: " + err.Error()})
+			return
+		}
+
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		program := model.Program{
+			Author:      accountIdHex,
+			Name:        params.Name,
+			Description: params.Description,
+			Privacy:     params.Privacy,
+			Weeks:       params.Weeks,
+			CreatedAt:   time.Now(),
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, program)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.CREATE_PROGRAM,
+			Context:     []string{"program id: " + inserted, "program name: " + program.Name},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// UpdateProgram replaces the name, description, privacy and schedule of an
+// existing program, only the program author can make edits. Active
+// enrollments follow the new schedule from their original start date
+//
+// If successful the response will return a 202 status accepted
+func (controller *AresController) UpdateProgram() gin.HandlerFunc {
+	type Params struct {
+		ID          primitive.ObjectID  `json:"id" binding:"required"`
+		Name        string              `json:"name" binding:"required"`
+		Description string              `json:"description,omitempty"`
+		Privacy     model.PrivacyLevel  `json:"privacy" binding:"required"`
+		Weeks       []model.ProgramWeek `json:"weeks" binding:"required,dive"`
+	}
+
+	dbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		existing, err := database.FindDocumentById[model.Program](dbQueryParams, params.ID.Hex())
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if existing.Author != accountIdHex {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "must be program author to make edits"})
+			return
+		}
+
+		message, err := validateProgramParams(controller.DB, controller.DatabaseName, accountIdHex, params.Name, params.Privacy, params.Weeks)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up templates: " + err.Error()})
+			return
+		}
+
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		existing.Name = params.Name
+		existing.Description = params.Description
+		existing.Privacy = params.Privacy
+		existing.Weeks = params.Weeks
+		existing.UpdatedAt = time.Now()
+
+		updated, err := database.UpdateOne(dbQueryParams, existing.ID, existing)
+		if err != nil || updated <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.UPDATE_PROGRAM,
+			Context:     []string{"program id: " + existing.ID.Hex(), "program name: " + existing.Name},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+// DeleteProgram removes a program from the primary database and creates a
+// Deleted Program entry in the deleted database. Active enrollments in the
+// program are cancelled
+func (controller *AresController) DeleteProgram() gin.HandlerFunc {
+	dbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	return func(ctx *gin.Context) {
+		programId := ctx.Param("programId")
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		_, err = primitive.ObjectIDFromHex(programId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad program id hex"})
+			return
+		}
+
+		existing, err := database.FindDocumentById[model.Program](dbQueryParams, programId)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if existing.Author != accountIdHex {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		deletedProgram := model.DeletedProgram{
+			Program:   existing,
+			RemovalAt: time.Now().Add(time.Hour * 24 * 7 * time.Duration(4)),
+		}
+
+		_, err = database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName + "_deleted",
+		}, deletedProgram)
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(dbQueryParams, bson.M{"_id": existing.ID})
+		if err != nil || deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		_, err = database.UpdateManyByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "program_enrollment",
+		}, bson.M{"program": existing.ID, "status": model.ENROLLMENT_ACTIVE}, bson.M{
+			"$set": bson.M{"status": model.ENROLLMENT_CANCELLED, "endedAt": time.Now()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to cancel program enrollments: ", err)
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.DELETE_PROGRAM,
+			Context:     []string{"program id: " + existing.ID.Hex(), "program name: " + existing.Name},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// EnrollInProgram enrolls the requesting account in a program they can view.
+// The schedule starts on the provided start date, or today if omitted.
+// An account can only hold one active enrollment per program
+func (controller *AresController) EnrollInProgram() gin.HandlerFunc {
+	type Params struct {
+		StartDate string `json:"startDate,omitempty"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		programId := ctx.Param("programId")
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		_, err = primitive.ObjectIDFromHex(programId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad program id hex"})
+			return
+		}
+
+		startDate := time.Now().UTC().Truncate(24 * time.Hour)
+		if params.StartDate != "" {
+			startDate, err = time.Parse("01-02-2006", params.StartDate)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid start date: " + err.Error()})
+				return
+			}
+		}
+
+		program, err := database.FindDocumentById[model.Program](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, programId)
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		canView, err := canViewProgram(controller.DB, controller.DatabaseName, accountIdHex, program, attachedPermissions)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+			return
+		}
+
+		if !canView {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		enrollment := model.ProgramEnrollment{
+			Account:    accountIdHex,
+			Program:    program.ID,
+			Status:     model.ENROLLMENT_ACTIVE,
+			StartDate:  startDate,
+			EnrolledAt: time.Now(),
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "program_enrollment",
+		}, enrollment)
+
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "already enrolled in program"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient:  controller.DB,
+			Initiator:    accountIdHex,
+			IP:           ctx.ClientIP(),
+			OtherParties: []primitive.ObjectID{program.Author},
+			EventName:    audit.ENROLL_PROGRAM,
+			Context:      []string{"enrollment id: " + inserted, "program id: " + program.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// GetEnrollments returns the program enrollments of the requesting account,
+// only active enrollments are returned unless 'all' is set
+func (controller *AresController) GetEnrollments() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		all := ctx.Query("all") == "true"
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		filter := bson.M{"account": accountIdHex}
+		if !all {
+			filter["status"] = model.ENROLLMENT_ACTIVE
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.ProgramEnrollment](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "program_enrollment",
+		}, filter, options.Find().SetSort(bson.M{"enrolledAt": -1}))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// findActiveEnrollment looks up an active enrollment of the requesting
+// account, aborting the request if it can't be found
+func (controller *AresController) findActiveEnrollment(ctx *gin.Context) (model.ProgramEnrollment, bool) {
+	enrollmentIdHex, err := primitive.ObjectIDFromHex(ctx.Param("enrollmentId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad enrollment id hex"})
+		return model.ProgramEnrollment{}, false
+	}
+
+	accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+		return model.ProgramEnrollment{}, false
+	}
+
+	enrollment, err := database.FindDocumentByFilter[model.ProgramEnrollment](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: "program_enrollment",
+	}, bson.M{"_id": enrollmentIdHex, "account": accountIdHex, "status": model.ENROLLMENT_ACTIVE})
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "enrollment not found"})
+			return enrollment, false
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up enrollment: " + err.Error()})
+		return enrollment, false
+	}
+
+	return enrollment, true
+}
+
+// CancelEnrollment ends an active enrollment of the requesting account
+func (controller *AresController) CancelEnrollment() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		enrollment, ok := controller.findActiveEnrollment(ctx)
+		if !ok {
+			return
+		}
+
+		result, err := database.UpdateOneByFilter(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "program_enrollment",
+		}, bson.M{"_id": enrollment.ID, "status": model.ENROLLMENT_ACTIVE}, bson.M{
+			"$set": bson.M{"status": model.ENROLLMENT_CANCELLED, "endedAt": time.Now()},
+		})
+
+		if err != nil || result.ModifiedCount <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   enrollment.Account,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.UNENROLL_PROGRAM,
+			Context:     []string{"enrollment id: " + enrollment.ID.Hex(), "program id: " + enrollment.Program.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// scheduledSession builds the next session scheduled for an enrollment with
+// loads calculated from the estimated one rep maxes of the enrolled account.
+// The returned names are exercises loaded by percentage that the account
+// holds no estimated one rep max for. Days that already have a session are
+// skipped, if the program is over the returned bool is false
+func (controller *AresController) scheduledSession(
+	enrollment model.ProgramEnrollment,
+	system model.MeasurementSystem,
+) (programs.ScheduledDay, model.Session, []string, bool, error) {
+	program, err := database.FindDocumentById[model.Program](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}, enrollment.Program.Hex())
+
+	if err != nil {
+		return programs.ScheduledDay{}, model.Session{}, nil, false, err
+	}
+
+	sessions, err := database.FindManyDocumentsByFilter[model.Session](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: "exercise_sessions",
+	}, bson.M{"enrollment": enrollment.ID, "programWeek": bson.M{"$exists": true}})
+
+	if err != nil {
+		return programs.ScheduledDay{}, model.Session{}, nil, false, err
+	}
+
+	started := map[programs.DayKey]bool{}
+	for _, session := range sessions {
+		started[programs.DayKey{Week: session.ProgramWeek, Day: session.ProgramDay}] = true
+	}
+
+	scheduled, found := programs.Next(program, enrollment.StartDate, time.Now(), started)
+	if !found {
+		return scheduled, model.Session{}, nil, false, nil
+	}
+
+	template, err := database.FindDocumentById[model.WorkoutTemplate](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: "workout_template",
+	}, scheduled.Day.Template.Hex())
+
+	if err != nil {
+		return scheduled, model.Session{}, nil, false, err
+	}
+
+	var exerciseNames []string
+	for _, exercise := range template.Exercises {
+		exerciseNames = append(exerciseNames, exercise.ExerciseName)
+	}
+
+	oneRepMaxRecords, err := database.FindManyDocumentsByFilter[model.PersonalRecord](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: "personal_record",
+	}, bson.M{
+		"account":      enrollment.Account,
+		"type":         model.ESTIMATED_ONE_REP_MAX,
+		"exerciseName": bson.M{"$in": exerciseNames},
+	})
+
+	if err != nil {
+		return scheduled, model.Session{}, nil, false, err
+	}
+
+	oneRepMaxes := map[string]float64{}
+	for _, record := range oneRepMaxRecords {
+		oneRepMaxes[record.ExerciseName] = record.Value
+	}
+
+	loaded, missing := programs.ApplyLoads(template, scheduled.Day, oneRepMaxes, system)
+
+	session := sessionFromTemplate(loaded, enrollment.Account)
+	session.Enrollment = enrollment.ID
+	session.ProgramWeek = scheduled.Week
+	session.ProgramDay = scheduled.Day.Day
+	session.SessionName = program.Name + " - week " + strconv.Itoa(scheduled.Week) + " day " + strconv.Itoa(int(scheduled.Day.Day))
+
+	if scheduled.Day.Name != "" {
+		session.SessionName = scheduled.Day.Name
+	}
+
+	return scheduled, session, missing, true, nil
+}
+
+// GetTodaysWorkout returns the next session scheduled for an enrollment of
+// the requesting account without starting it. If the scheduled date is after
+// today the day is a rest day
+func (controller *AresController) GetTodaysWorkout() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		enrollment, ok := controller.findActiveEnrollment(ctx)
+		if !ok {
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		scheduled, session, missing, found, err := controller.scheduledSession(enrollment, system)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to build scheduled session: " + err.Error()})
+			return
+		}
+
+		if !found {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "program has no remaining scheduled days"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"scheduled":      scheduled,
+			"today":          !scheduled.Date.After(time.Now()),
+			"session":        session,
+			"missingRecords": missing,
+		})
+	}
+}
+
+// StartScheduledWorkout creates an in-progress session from the next session
+// scheduled for an enrollment and returns the newly created session ID. A
+// program day is only ever started once
+func (controller *AresController) StartScheduledWorkout() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		enrollment, ok := controller.findActiveEnrollment(ctx)
+		if !ok {
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		scheduled, session, missing, found, err := controller.scheduledSession(enrollment, system)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to build scheduled session: " + err.Error()})
+			return
+		}
+
+		if !found {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "program has no remaining scheduled days"})
+			return
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "exercise_sessions",
+		}, session)
+
+		if err != nil {
+			// a session started concurrently for the same program day
+			if mongo.IsDuplicateKeyError(err) {
+				ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "scheduled workout has already been started"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   enrollment.Account,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.CREATE_TRAINING_SESSION,
+			Context: []string{
+				"session id: " + inserted,
+				"enrollment id: " + enrollment.ID.Hex(),
+				"program week: " + strconv.Itoa(scheduled.Week),
+				"program day: " + strconv.Itoa(int(scheduled.Day.Day)),
+			},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted, "missingRecords": missing})
+	}
+}
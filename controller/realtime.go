@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"ares/database"
+	"ares/model"
+	"ares/realtime"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/net/websocket"
+)
+
+// streamKeepAlive is how often an idle stream is written to, so proxies
+// don't close it and dead clients are noticed
+const streamKeepAlive = 30 * time.Second
+
+// IssueStreamTicket returns a single use ticket opening an event stream with
+// ?ticket=<ticket>, for clients that can't set the Authorization header on
+// WebSocket and EventSource requests. The ticket has to be redeemed within
+// realtime.TicketTTL
+func (controller *AresController) IssueStreamTicket() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ticket, err := realtime.IssueTicket(ctx.GetString("accountId"), ctx.GetTime("tokenExpiresAt"))
+		if err != nil {
+			if err == realtime.ErrNotConfigured {
+				ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"message": err.Error()})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to issue stream ticket: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"result": gin.H{
+			"ticket":    ticket,
+			"expiresAt": time.Now().Add(realtime.TicketTTL),
+		}})
+	}
+}
+
+// streamOpen returns false once a stream should be closed, when the access
+// token it was opened with has expired or its account was deleted. Lookup
+// failures keep the stream open
+func (controller *AresController) streamOpen(accountIdHex primitive.ObjectID, expiresAt time.Time) bool {
+	if !time.Now().Before(expiresAt) {
+		return false
+	}
+
+	_, err := database.FindDocumentById[model.Account](database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: "account",
+	}, accountIdHex.Hex())
+
+	return err != mongo.ErrNoDocuments
+}
+
+// StreamEventsWebSocket streams the realtime events of the requesting account
+// over a WebSocket, one JSON encoded event per text frame. Anything the
+// client sends is ignored. The stream is closed once the access token it was
+// opened with expires
+func (controller *AresController) StreamEventsWebSocket() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		expiresAt := ctx.GetTime("tokenExpiresAt")
+
+		subscription, err := realtime.Subscribe(ctx.Request.Context(), accountIdHex)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"message": "failed to subscribe to events: " + err.Error()})
+			return
+		}
+
+		defer subscription.Close()
+
+		server := websocket.Server{
+			// requests are authenticated with the access token rather than a
+			// cookie, so the origin doesn't need to be checked
+			Handshake: func(config *websocket.Config, request *http.Request) error {
+				return nil
+			},
+			Handler: func(conn *websocket.Conn) {
+				closed := make(chan struct{})
+
+				go func() {
+					defer close(closed)
+
+					// reading is the only way to notice the client closing
+					var discard string
+					for websocket.Message.Receive(conn, &discard) == nil {
+					}
+				}()
+
+				ticker := time.NewTicker(streamKeepAlive)
+				defer ticker.Stop()
+
+				expiry := time.NewTimer(time.Until(expiresAt))
+				defer expiry.Stop()
+
+				for {
+					select {
+					case <-closed:
+						return
+					case <-expiry.C:
+						return
+					case message, ok := <-subscription.Events:
+						if !ok || websocket.Message.Send(conn, message.Payload) != nil {
+							return
+						}
+					case <-ticker.C:
+						if !controller.streamOpen(accountIdHex, expiresAt) {
+							return
+						}
+
+						if websocket.Message.Send(conn, `{"type":"ping"}`) != nil {
+							return
+						}
+					}
+				}
+			},
+		}
+
+		server.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
+
+// StreamEventsSSE streams the realtime events of the requesting account as
+// server-sent events, for clients that can't open a WebSocket. The data of
+// every server-sent event is the JSON encoded event. The stream is closed
+// once the access token it was opened with expires
+func (controller *AresController) StreamEventsSSE() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		expiresAt := ctx.GetTime("tokenExpiresAt")
+
+		subscription, err := realtime.Subscribe(ctx.Request.Context(), accountIdHex)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"message": "failed to subscribe to events: " + err.Error()})
+			return
+		}
+
+		defer subscription.Close()
+
+		ctx.Header("Content-Type", "text/event-stream")
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Connection", "keep-alive")
+		ctx.Header("X-Accel-Buffering", "no")
+
+		ticker := time.NewTicker(streamKeepAlive)
+		defer ticker.Stop()
+
+		expiry := time.NewTimer(time.Until(expiresAt))
+		defer expiry.Stop()
+
+		ctx.Stream(func(w io.Writer) bool {
+			select {
+			case <-ctx.Request.Context().Done():
+				return false
+			case <-expiry.C:
+				return false
+			case message, ok := <-subscription.Events:
+				if !ok {
+					return false
+				}
+
+				_, err := io.WriteString(w, "data: "+message.Payload+"\n\n")
+				return err == nil
+			case <-ticker.C:
+				if !controller.streamOpen(accountIdHex, expiresAt) {
+					return false
+				}
+
+				_, err := io.WriteString(w, ": ping\n\n")
+				return err == nil
+			}
+		})
+	}
+}
@@ -0,0 +1,510 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/model"
+	"ares/nutrition"
+	"ares/util"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// canViewRecipe returns true if the requesting account can view and log the
+// recipe
+func canViewRecipe(
+	mongoClient *mongo.Client,
+	databaseName string,
+	requestAccountId primitive.ObjectID,
+	recipe model.Recipe,
+	attachedPermissions []model.Permission,
+) (bool, error) {
+	if recipe.Author == requestAccountId || util.ContainsPerm(model.BYPASS_PRIVACY, attachedPermissions) {
+		return true, nil
+	}
+
+	switch recipe.Privacy {
+	case model.PUBLIC:
+		return true, nil
+	case model.FOLLOWER_ONLY:
+		return IsFollowing(mongoClient, databaseName, "follow", requestAccountId, recipe.Author)
+	}
+
+	return false, nil
+}
+
+// prepareRecipe checks the name, privacy, servings and ingredients of a
+// recipe, copies the name and measurement of every ingredient food in to the
+// recipe and calculates the nutrients of a serving. The returned message
+// describes the first problem found and is empty if the recipe is valid
+func prepareRecipe(mongoClient *mongo.Client, databaseName string, recipe model.Recipe) (model.Recipe, string, error) {
+	if util.IsAlphanumericWithWhitespace(recipe.Name) {
+		return recipe, "recipe name must be alphanumeric", nil
+	}
+
+	if recipe.Privacy != model.PUBLIC && recipe.Privacy != model.FOLLOWER_ONLY && recipe.Privacy != model.PRIVATE {
+		return recipe, "unknown privacy level " + string(recipe.Privacy), nil
+	}
+
+	if recipe.Servings == 0 {
+		return recipe, "recipe must make at least one serving", nil
+	}
+
+	if len(recipe.Ingredients) == 0 {
+		return recipe, "recipe must contain at least one ingredient", nil
+	}
+
+	var ids []primitive.ObjectID
+	for _, ingredient := range recipe.Ingredients {
+		if ingredient.Food.IsZero() {
+			return recipe, "ingredients must reference a food", nil
+		}
+
+		if ingredient.Quantity <= 0 {
+			return recipe, "ingredient quantities must be greater than zero", nil
+		}
+
+		ids = append(ids, ingredient.Food)
+	}
+
+	foods, err := database.FindManyDocumentsByFilter[model.Food](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "food",
+	}, bson.M{"_id": bson.M{"$in": ids}})
+
+	if err != nil {
+		return recipe, "", err
+	}
+
+	foodById := map[string]model.Food{}
+	for _, food := range foods {
+		foodById[food.ID.Hex()] = food
+	}
+
+	for i, ingredient := range recipe.Ingredients {
+		food, exists := foodById[ingredient.Food.Hex()]
+		if !exists {
+			return recipe, "food " + ingredient.Food.Hex() + " not found", nil
+		}
+
+		recipe.Ingredients[i].Name = food.Name
+		recipe.Ingredients[i].Measurement = food.Measurement
+	}
+
+	recipe.PerServing, err = nutrition.PerServing(recipe, foodById)
+	if err != nil {
+		return recipe, err.Error(), nil
+	}
+
+	return recipe, "", nil
+}
+
+// errRecipeHidden is returned by findDietItem when the requesting account
+// can't view the recipe
+var errRecipeHidden = errors.New("recipe not found")
+
+// findDietItem returns what a diet entry for either the food or the recipe
+// should hold as its food. Recipes are logged as a serving of the recipe and
+// are only returned if canView allows it
+func findDietItem(
+	mongoClient *mongo.Client,
+	databaseName string,
+	foodId primitive.ObjectID,
+	recipeId primitive.ObjectID,
+	canView func(recipe model.Recipe) (bool, error),
+) (model.Food, error) {
+	if recipeId.IsZero() {
+		return database.FindDocumentById[model.Food](database.QueryParams{
+			MongoClient:    mongoClient,
+			DatabaseName:   databaseName,
+			CollectionName: "food",
+		}, foodId.Hex())
+	}
+
+	recipe, err := database.FindDocumentById[model.Recipe](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "recipe",
+	}, recipeId.Hex())
+
+	if err != nil {
+		return model.Food{}, err
+	}
+
+	if canView != nil {
+		visible, err := canView(recipe)
+		if err != nil {
+			return model.Food{}, err
+		}
+
+		if !visible {
+			return model.Food{}, errRecipeHidden
+		}
+	}
+
+	return recipe.PerServing, nil
+}
+
+// GetRecipeById returns a single recipe matching the provided document ID if
+// the requesting account is allowed to view it
+func (controller *AresController) GetRecipeById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		recipeId := ctx.Param("recipeId")
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		_, err = primitive.ObjectIDFromHex(recipeId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad recipe id hex"})
+			return
+		}
+
+		recipe, err := database.FindDocumentById[model.Recipe](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, recipeId)
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		canView, err := canViewRecipe(controller.DB, controller.DatabaseName, accountIdHex, recipe, attachedPermissions)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+			return
+		}
+
+		if !canView {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, recipe)
+	}
+}
+
+// GetRecipesByAccount returns the recipes authored by the provided account
+// id. Accounts other than the author only see the recipes shared with them
+func (controller *AresController) GetRecipesByAccount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorId := ctx.Param("accountId")
+		page := ctx.DefaultQuery("page", "0")
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		authorIdHex, err := primitive.ObjectIDFromHex(authorId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad author id hex"})
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(page)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		filter := bson.M{"author": authorIdHex}
+
+		if authorIdHex != accountIdHex && !util.ContainsPerm(model.BYPASS_PRIVACY, attachedPermissions) {
+			visible := bson.A{model.PUBLIC}
+
+			following, err := IsFollowing(controller.DB, controller.DatabaseName, "follow", accountIdHex, authorIdHex)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+				return
+			}
+
+			if following {
+				visible = append(visible, model.FOLLOWER_ONLY)
+			}
+
+			filter["privacy"] = bson.M{"$in": visible}
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.Recipe](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, filter, options.Find().SetSort(bson.M{"name": 1}).SetLimit(25).SetSkip(int64(pageNumber*25)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// CreateRecipe creates a new recipe authored by the requesting account and
+// returns the newly created document ID
+func (controller *AresController) CreateRecipe() gin.HandlerFunc {
+	type Params struct {
+		Name        string             `json:"name" binding:"required"`
+		Description string             `json:"description,omitempty"`
+		Privacy     model.PrivacyLevel `json:"privacy,omitempty"`
+		Servings    uint16             `json:"servings" binding:"required"`
+		Ingredients []model.Ingredient `json:"ingredients" binding:"required"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		if params.Privacy == "" {
+			params.Privacy = model.PRIVATE
+		}
+
+		recipe, message, err := prepareRecipe(controller.DB, controller.DatabaseName, model.Recipe{
+			Author:      accountIdHex,
+			Name:        params.Name,
+			Description: params.Description,
+			Privacy:     params.Privacy,
+			Servings:    params.Servings,
+			Ingredients: params.Ingredients,
+			CreatedAt:   time.Now(),
+		})
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up foods: " + err.Error()})
+			return
+		}
+
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, recipe)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.CREATE_RECIPE,
+			Context:     []string{"recipe id: " + inserted, "recipe name: " + recipe.Name},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// UpdateRecipe replaces the fields and ingredients of an existing recipe and
+// recalculates its nutrients, only the recipe author can make edits. Diet
+// entries already logged from the recipe keep the nutrients they were logged
+// with
+//
+// If successful the response will return a 202 status accepted
+func (controller *AresController) UpdateRecipe() gin.HandlerFunc {
+	type Params struct {
+		ID          primitive.ObjectID `json:"id" binding:"required"`
+		Name        string             `json:"name" binding:"required"`
+		Description string             `json:"description,omitempty"`
+		Privacy     model.PrivacyLevel `json:"privacy" binding:"required"`
+		Servings    uint16             `json:"servings" binding:"required"`
+		Ingredients []model.Ingredient `json:"ingredients" binding:"required"`
+	}
+
+	dbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		existing, err := database.FindDocumentById[model.Recipe](dbQueryParams, params.ID.Hex())
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if existing.Author != accountIdHex {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "must be recipe author to make edits"})
+			return
+		}
+
+		existing.Name = params.Name
+		existing.Description = params.Description
+		existing.Privacy = params.Privacy
+		existing.Servings = params.Servings
+		existing.Ingredients = params.Ingredients
+		existing.UpdatedAt = time.Now()
+
+		recipe, message, err := prepareRecipe(controller.DB, controller.DatabaseName, existing)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up foods: " + err.Error()})
+			return
+		}
+
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		updated, err := database.UpdateOne(dbQueryParams, recipe.ID, recipe)
+		if err != nil || updated <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.UPDATE_RECIPE,
+			Context:     []string{"recipe id: " + recipe.ID.Hex(), "recipe name: " + recipe.Name},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+// DeleteRecipe removes a recipe from the primary database and creates a
+// Deleted Recipe entry in the deleted database. Diet entries and meal plans
+// referencing the recipe are left untouched
+func (controller *AresController) DeleteRecipe() gin.HandlerFunc {
+	dbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	return func(ctx *gin.Context) {
+		recipeId := ctx.Param("recipeId")
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		_, err = primitive.ObjectIDFromHex(recipeId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad recipe id hex"})
+			return
+		}
+
+		existing, err := database.FindDocumentById[model.Recipe](dbQueryParams, recipeId)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if existing.Author != accountIdHex {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		_, err = database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName + "_deleted",
+		}, model.DeletedRecipe{
+			Recipe:    existing,
+			RemovalAt: time.Now().Add(time.Hour * 24 * 7 * time.Duration(4)),
+		})
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(dbQueryParams, bson.M{"_id": existing.ID})
+		if err != nil || deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.DELETE_RECIPE,
+			Context:     []string{"recipe id: " + existing.ID.Hex(), "recipe name: " + existing.Name},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"ares/database"
+	"ares/model"
+	"ares/records"
+	"ares/units"
+	"ares/util"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// announcePersonalRecords creates a post on behalf of the session author
+// listing the personal records they set during the session
+func announcePersonalRecords(
+	mongoClient *mongo.Client,
+	databaseName string,
+	session model.Session,
+	personalRecords []model.PersonalRecord,
+) (string, error) {
+	post := model.Post{
+		Author:    session.Author,
+		Session:   session.ID,
+		Text:      records.Announcement(personalRecords),
+		CreatedAt: time.Now(),
+		Tags:      []string{"pr"},
+	}
+
+	return database.InsertOne(database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "post",
+	}, post)
+}
+
+// GetPersonalRecordsByAccount returns every personal record held by the
+// provided account id, optionally filtered by exercise name and record type
+//
+// Records of accounts with a private profile are only visible to the owner
+// or accounts with the bypass privacy permission. Records are returned in
+// kilograms and meters unless imperial units are requested
+func (controller *AresController) GetPersonalRecordsByAccount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountId := ctx.Param("accountId")
+		exerciseName, exerciseNamePresent := ctx.GetQuery("exercise")
+		recordType, recordTypePresent := ctx.GetQuery("type")
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		requestAccountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad request account id hex"})
+			return
+		}
+
+		account, err := database.FindDocumentById[model.Account](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "account",
+		}, accountId)
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "account not found"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up account: " + err.Error()})
+			return
+		}
+
+		if !util.ContainsPerm(model.BYPASS_PRIVACY, attachedPermissions) {
+			canView, err := canViewAccount(controller.DB, controller.DatabaseName, requestAccountIdHex, account)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+				return
+			}
+
+			if !canView {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+		}
+
+		filter := bson.M{"account": account.ID}
+
+		if exerciseNamePresent {
+			filter["exerciseName"] = exerciseName
+		}
+
+		if recordTypePresent {
+			filter["type"] = model.PersonalRecordType(recordType)
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.PersonalRecord](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, filter, options.Find().SetSort(bson.D{{Key: "exerciseName", Value: 1}, {Key: "type", Value: 1}, {Key: "bracket", Value: 1}}))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		system, err := preferredMeasurementSystem(ctx, controller.DB, controller.DatabaseName)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to resolve measurement system: " + err.Error()})
+			return
+		}
+
+		for i, record := range result {
+			result[i] = units.ConvertPersonalRecord(record, system)
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
@@ -0,0 +1,330 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/deletion"
+	"ares/model"
+	"ares/util"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// restoreParams describes how a document queued for deletion is moved back
+// out of the _deleted version of its collection
+type restoreParams[D any, K any] struct {
+	// field of the deleted document holding the original document
+	field string
+	// unwrap returns the original document
+	unwrap func(deleted D) K
+	// owner returns the account allowed to restore the document, documents
+	// without an owner can only be restored with the permission
+	owner func(document K) primitive.ObjectID
+	// permission allows restoring documents owned by other accounts
+	permission model.Permission
+	eventName  audit.EntryType
+	// afterRestore optionally restores anything deleted along with the
+	// document
+	afterRestore func(document K) error
+}
+
+// restoreDocument moves a document back in to its collection as long as its
+// removal date has not passed yet. The document keeps its original ID
+func restoreDocument[D any, K any](controller *AresController, ctx *gin.Context, documentId primitive.ObjectID, params restoreParams[D, K]) {
+	accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+		return
+	}
+
+	deletedDbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName + "_deleted",
+	}
+
+	filter := bson.M{
+		params.field + "._id": documentId,
+		"removalAt":           bson.M{"$gt": time.Now()},
+	}
+
+	deleted, err := database.FindDocumentByFilter[D](deletedDbQueryParams, filter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "deleted document not found"})
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+		return
+	}
+
+	document := params.unwrap(deleted)
+
+	// accounts restoring themselves have no permissions attached
+	attachedPermissions, _ := ctx.Keys["attachedPermissions"].([]model.Permission)
+	isOwner := params.owner != nil && params.owner(document) == accountIdHex
+
+	if !isOwner && !util.ContainsPerm(params.permission, attachedPermissions) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "deleted document not found"})
+		return
+	}
+
+	_, err = database.InsertOne(database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}, document)
+
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "document already exists"})
+			return
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to restore document"})
+		return
+	}
+
+	_, err = database.DeleteManyByFilter(deletedDbQueryParams, bson.M{params.field + "._id": documentId})
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to remove deleted document: " + err.Error()})
+		return
+	}
+
+	if params.afterRestore != nil {
+		err = params.afterRestore(document)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to restore dependent documents: " + err.Error()})
+			return
+		}
+	}
+
+	err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+		MongoClient: controller.DB,
+		Initiator:   accountIdHex,
+		IP:          ctx.ClientIP(),
+		EventName:   params.eventName,
+		Context:     []string{params.field + " id: " + documentId.Hex()},
+	})
+
+	if err != nil {
+		fmt.Println("failed to save audit entry: ", err)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": documentId.Hex()})
+}
+
+// parseRestoreId reads the ID of the document to restore from the path
+func parseRestoreId(ctx *gin.Context, param string) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(ctx.Param(param))
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad " + param + " hex"})
+		return id, false
+	}
+
+	return id, true
+}
+
+// restoreAccount restores a deleted account along with everything deleted
+// with it for its owner or a moderator
+func (controller *AresController) restoreAccount(ctx *gin.Context, accountIdHex primitive.ObjectID) {
+	orchestrator := deletion.Orchestrator{
+		MongoClient:  controller.DB,
+		DatabaseName: controller.DatabaseName,
+		RedisClient:  controller.RedisCache,
+	}
+
+	restoreDocument(controller, ctx, accountIdHex, restoreParams[model.DeletedAccount, model.Account]{
+		field:      "account",
+		unwrap:     func(deleted model.DeletedAccount) model.Account { return deleted.Account },
+		owner:      func(account model.Account) primitive.ObjectID { return account.ID },
+		permission: model.MODERATE_USERS,
+		eventName:  audit.RESTORE_ACCOUNT,
+		afterRestore: func(account model.Account) error {
+			_, err := orchestrator.RestoreAccount(account.ID)
+			return err
+		},
+	})
+}
+
+// RestoreAccount restores a deleted account along with everything deleted
+// with it. Without an ID in the path the requesting account is restored,
+// moderators can restore any account
+func (controller *AresController) RestoreAccount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		accountId := ctx.GetString("accountId")
+		if ctx.Param("id") != "" {
+			accountId = ctx.Param("id")
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(accountId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		controller.restoreAccount(ctx, accountIdHex)
+	}
+}
+
+// RestoreAccountWithCredentials restores a deleted account for an owner
+// signing in with their email and password. Deleting an account revokes its
+// tokens, so this is how an owner undoes a deletion until its removal date.
+// The account can be signed in to as usual once it is restored
+func (controller *AresController) RestoreAccountWithCredentials() gin.HandlerFunc {
+	type Params struct {
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal credentials object"})
+			return
+		}
+
+		deleted, err := database.FindDocumentByFilter[model.DeletedAccount](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName + "_deleted",
+		}, bson.M{
+			"account.email": params.Email,
+			"removalAt":     bson.M{"$gt": time.Now()},
+		})
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "deleted account not found"})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		err = bcrypt.CompareHashAndPassword([]byte(deleted.Account.Password), []byte(params.Password))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "password does not match"})
+			return
+		}
+
+		// the credentials prove ownership, the account is restored as if its
+		// owner had requested it with a token
+		ctx.Set("accountId", deleted.Account.ID.Hex())
+
+		controller.restoreAccount(ctx, deleted.Account.ID)
+	}
+}
+
+// RestorePost restores a deleted post for its author or a moderator
+func (controller *AresController) RestorePost() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		postIdHex, ok := parseRestoreId(ctx, "id")
+		if !ok {
+			return
+		}
+
+		restoreDocument(controller, ctx, postIdHex, restoreParams[model.DeletedPost, model.Post]{
+			field:      "post",
+			unwrap:     func(deleted model.DeletedPost) model.Post { return deleted.Post },
+			owner:      func(post model.Post) primitive.ObjectID { return post.Author },
+			permission: model.MODERATE_POSTS,
+			eventName:  audit.RESTORE_POST,
+		})
+	}
+}
+
+// RestoreComment restores a deleted comment for its author or a moderator
+func (controller *AresController) RestoreComment() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		commentIdHex, ok := parseRestoreId(ctx, "id")
+		if !ok {
+			return
+		}
+
+		restoreDocument(controller, ctx, commentIdHex, restoreParams[model.DeletedComment, model.Comment]{
+			field:      "comment",
+			unwrap:     func(deleted model.DeletedComment) model.Comment { return deleted.Comment },
+			owner:      func(comment model.Comment) primitive.ObjectID { return comment.Author },
+			permission: model.MODERATE_POSTS,
+			eventName:  audit.RESTORE_COMMENT,
+			afterRestore: func(comment model.Comment) error {
+				// a drifting counter is corrected later and shouldn't fail
+				// the restore
+				err := incrementCount(controller.DB, controller.DatabaseName, comment.PostType, comment.Post, commentCountField(comment.PostType), 1)
+				if err != nil {
+					fmt.Println("failed to update comment count: ", err)
+				}
+
+				return nil
+			},
+		})
+	}
+}
+
+// RestoreLocation restores a deleted location for its author or an account
+// allowed to author locations
+func (controller *AresController) RestoreLocation() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		locationIdHex, ok := parseRestoreId(ctx, "id")
+		if !ok {
+			return
+		}
+
+		restoreDocument(controller, ctx, locationIdHex, restoreParams[model.DeletedLocation, model.Location]{
+			field:      "location",
+			unwrap:     func(deleted model.DeletedLocation) model.Location { return deleted.Location },
+			owner:      func(location model.Location) primitive.ObjectID { return location.Author },
+			permission: model.AUTHOR_LOCATION,
+			eventName:  audit.RESTORE_LOCATION,
+		})
+	}
+}
+
+// RestoreExerciseSession restores a deleted session for its author or a
+// moderator
+func (controller *AresController) RestoreExerciseSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sessionIdHex, ok := parseRestoreId(ctx, "sessionId")
+		if !ok {
+			return
+		}
+
+		restoreDocument(controller, ctx, sessionIdHex, restoreParams[model.DeletedSession, model.Session]{
+			field:      "session",
+			unwrap:     func(deleted model.DeletedSession) model.Session { return deleted.Session },
+			owner:      func(session model.Session) primitive.ObjectID { return session.Author },
+			permission: model.MODERATE_USERS,
+			eventName:  audit.RESTORE_TRAINING_SESSION,
+		})
+	}
+}
+
+// RestoreBlog restores a deleted blog post, like deleting one this requires
+// permission to author blogs
+func (controller *AresController) RestoreBlog() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		blogIdHex, ok := parseRestoreId(ctx, "id")
+		if !ok {
+			return
+		}
+
+		restoreDocument(controller, ctx, blogIdHex, restoreParams[model.DeletedBlogPost, model.BlogPost]{
+			field:      "blog",
+			unwrap:     func(deleted model.DeletedBlogPost) model.BlogPost { return deleted.Blog },
+			permission: model.AUTHOR_BLOGS,
+			eventName:  audit.RESTORE_BLOG,
+		})
+	}
+}
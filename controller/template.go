@@ -0,0 +1,564 @@
+package controller
+
+import (
+	"ares/audit"
+	"ares/database"
+	"ares/model"
+	"ares/units"
+	"ares/util"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// canViewTemplate returns true if the requesting account can view and start
+// sessions from the template
+func canViewTemplate(
+	mongoClient *mongo.Client,
+	databaseName string,
+	requestAccountId primitive.ObjectID,
+	template model.WorkoutTemplate,
+	attachedPermissions []model.Permission,
+) (bool, error) {
+	if template.Author == requestAccountId || util.ContainsPerm(model.BYPASS_PRIVACY, attachedPermissions) {
+		return true, nil
+	}
+
+	switch template.Privacy {
+	case model.PUBLIC:
+		return true, nil
+	case model.FOLLOWER_ONLY:
+		return IsFollowing(mongoClient, databaseName, "follow", requestAccountId, template.Author)
+	}
+
+	return false, nil
+}
+
+// validateTemplateParams checks the name and privacy of a template, and
+// copies the name and type of every referenced exercise info document in to
+// the template exercises. The returned message describes the first problem
+// found and is empty if the params are valid
+func validateTemplateParams(
+	mongoClient *mongo.Client,
+	databaseName string,
+	name string,
+	privacy model.PrivacyLevel,
+	exercises []model.TemplateExercise,
+) ([]model.TemplateExercise, string, error) {
+	match := util.IsAlphanumericWithWhitespace(name)
+	if match {
+		return exercises, "template name must be alphanumeric", nil
+	}
+
+	if privacy != model.PUBLIC && privacy != model.FOLLOWER_ONLY && privacy != model.PRIVATE {
+		return exercises, "unknown privacy level " + string(privacy), nil
+	}
+
+	if len(exercises) == 0 {
+		return exercises, "template must contain at least one exercise", nil
+	}
+
+	var ids []primitive.ObjectID
+	for _, exercise := range exercises {
+		if exercise.Sets == 0 {
+			return exercises, "template exercises must have at least one set", nil
+		}
+
+		ids = append(ids, exercise.Exercise)
+	}
+
+	infos, err := database.FindManyDocumentsByFilter[model.ExerciseInfo](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "exercise_info",
+	}, bson.M{"_id": bson.M{"$in": ids}})
+
+	if err != nil {
+		return exercises, "", err
+	}
+
+	infoById := map[primitive.ObjectID]model.ExerciseInfo{}
+	for _, info := range infos {
+		infoById[info.ID] = info
+	}
+
+	for i, exercise := range exercises {
+		info, exists := infoById[exercise.Exercise]
+		if !exists {
+			return exercises, "exercise " + exercise.Exercise.Hex() + " not found", nil
+		}
+
+		exercises[i].ExerciseName = info.Name
+		exercises[i].Type = info.Type
+		exercises[i].Weight = units.CanonicalizeWeight(exercise.Weight)
+	}
+
+	return exercises, "", nil
+}
+
+// GetWorkoutTemplateById returns a single workout template matching the
+// provided document ID if the requesting account is allowed to view it
+func (controller *AresController) GetWorkoutTemplateById() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		templateId := ctx.Param("templateId")
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		_, err = primitive.ObjectIDFromHex(templateId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad template id hex"})
+			return
+		}
+
+		template, err := database.FindDocumentById[model.WorkoutTemplate](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, templateId)
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		canView, err := canViewTemplate(controller.DB, controller.DatabaseName, accountIdHex, template, attachedPermissions)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+			return
+		}
+
+		if !canView {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, template)
+	}
+}
+
+// GetWorkoutTemplatesByAccount returns the templates authored by the provided
+// account id. Accounts other than the author only see the templates shared
+// with them
+func (controller *AresController) GetWorkoutTemplatesByAccount() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorId := ctx.Param("accountId")
+		page := ctx.DefaultQuery("page", "0")
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		authorIdHex, err := primitive.ObjectIDFromHex(authorId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad author id hex"})
+			return
+		}
+
+		pageNumber, err := strconv.Atoi(page)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "invalid page number"})
+			return
+		}
+
+		filter := bson.M{"author": authorIdHex}
+
+		if authorIdHex != accountIdHex && !util.ContainsPerm(model.BYPASS_PRIVACY, attachedPermissions) {
+			visible := bson.A{model.PUBLIC}
+
+			following, err := IsFollowing(controller.DB, controller.DatabaseName, "follow", accountIdHex, authorIdHex)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+				return
+			}
+
+			if following {
+				visible = append(visible, model.FOLLOWER_ONLY)
+			}
+
+			filter["privacy"] = bson.M{"$in": visible}
+		}
+
+		result, err := database.FindManyDocumentsByFilterWithOpts[model.WorkoutTemplate](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, filter, options.Find().SetSort(bson.M{"name": 1}).SetLimit(25).SetSkip(int64(pageNumber*25)))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to perform database query: " + err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"result": result})
+	}
+}
+
+// CreateWorkoutTemplate creates a new workout template authored by the
+// requesting account and returns the newly created document ID
+func (controller *AresController) CreateWorkoutTemplate() gin.HandlerFunc {
+	type Params struct {
+		Name        string                   `json:"name" binding:"required"`
+		Description string                   `json:"description,omitempty"`
+		Privacy     model.PrivacyLevel       `json:"privacy,omitempty"`
+		Exercises   []model.TemplateExercise `json:"exercises" binding:"required,dive"`
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		if params.Privacy == "" {
+			params.Privacy = model.PRIVATE
+		}
+
+		exercises, message, err := validateTemplateParams(controller.DB, controller.DatabaseName, params.Name, params.Privacy, params.Exercises)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up exercises: " + err.Error()})
+			return
+		}
+
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		template := model.WorkoutTemplate{
+			Author:      accountIdHex,
+			Name:        params.Name,
+			Description: params.Description,
+			Privacy:     params.Privacy,
+			Exercises:   exercises,
+			CreatedAt:   time.Now(),
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, template)
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.CREATE_WORKOUT_TEMPLATE,
+			Context:     []string{"template id: " + inserted, "template name: " + template.Name},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
+
+// UpdateWorkoutTemplate replaces the name, description, privacy and exercises
+// of an existing template, only the template author can make edits
+//
+// If successful the response will return a 202 status accepted
+func (controller *AresController) UpdateWorkoutTemplate() gin.HandlerFunc {
+	type Params struct {
+		ID          primitive.ObjectID       `json:"id" binding:"required"`
+		Name        string                   `json:"name" binding:"required"`
+		Description string                   `json:"description,omitempty"`
+		Privacy     model.PrivacyLevel       `json:"privacy" binding:"required"`
+		Exercises   []model.TemplateExercise `json:"exercises" binding:"required,dive"`
+	}
+
+	dbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	return func(ctx *gin.Context) {
+		var params Params
+		err := ctx.ShouldBindJSON(&params)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "failed to unmarshal params: " + err.Error()})
+			return
+		}
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		existing, err := database.FindDocumentById[model.WorkoutTemplate](dbQueryParams, params.ID.Hex())
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if existing.Author != accountIdHex {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "must be template author to make edits"})
+			return
+		}
+
+		exercises, message, err := validateTemplateParams(controller.DB, controller.DatabaseName, params.Name, params.Privacy, params.Exercises)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up exercises: " + err.Error()})
+			return
+		}
+
+		if message != "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": message})
+			return
+		}
+
+		existing.Name = params.Name
+		existing.Description = params.Description
+		existing.Privacy = params.Privacy
+		existing.Exercises = exercises
+		existing.UpdatedAt = time.Now()
+
+		updated, err := database.UpdateOne(dbQueryParams, existing.ID, existing)
+		if err != nil || updated <= 0 {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to update document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.UPDATE_WORKOUT_TEMPLATE,
+			Context:     []string{"template id: " + existing.ID.Hex(), "template name: " + existing.Name},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+// DeleteWorkoutTemplate removes a template from the primary database and
+// creates a Deleted Workout Template entry in the deleted database. Sessions
+// already started from the template are left untouched
+func (controller *AresController) DeleteWorkoutTemplate() gin.HandlerFunc {
+	dbQueryParams := database.QueryParams{
+		MongoClient:    controller.DB,
+		DatabaseName:   controller.DatabaseName,
+		CollectionName: controller.CollectionName,
+	}
+
+	return func(ctx *gin.Context) {
+		templateId := ctx.Param("templateId")
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		_, err = primitive.ObjectIDFromHex(templateId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad template id hex"})
+			return
+		}
+
+		existing, err := database.FindDocumentById[model.WorkoutTemplate](dbQueryParams, templateId)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		if existing.Author != accountIdHex {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		deletedTemplate := model.DeletedWorkoutTemplate{
+			Template:  existing,
+			RemovalAt: time.Now().Add(time.Hour * 24 * 7 * time.Duration(4)),
+		}
+
+		_, err = database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName + "_deleted",
+		}, deletedTemplate)
+
+		if err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		deleteResult, err := database.DeleteOne(dbQueryParams, bson.M{"_id": existing.ID})
+		if err != nil || deleteResult.DeletedCount <= 0 {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.DELETE_WORKOUT_TEMPLATE,
+			Context:     []string{"template id: " + existing.ID.Hex(), "template name: " + existing.Name},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// sessionFromTemplate builds an in-progress session for the provided author
+// with every template exercise pre-filled with its target sets
+func sessionFromTemplate(template model.WorkoutTemplate, authorId primitive.ObjectID) model.Session {
+	now := time.Now()
+
+	var exercises []model.Exercise
+	for _, target := range template.Exercises {
+		sets := make([]model.ExerciseSet, 0, target.Sets)
+		for i := uint8(0); i < target.Sets; i++ {
+			sets = append(sets, model.ExerciseSet{
+				Reps:   target.Reps,
+				Weight: target.Weight,
+			})
+		}
+
+		exercises = append(exercises, prepareExercise(model.Exercise{
+			ExerciseName: target.ExerciseName,
+			AddedAt:      now,
+			Sets:         sets,
+			Type:         target.Type,
+			Rest:         target.Rest,
+		}))
+	}
+
+	return model.Session{
+		SessionName: template.Name,
+		Author:      authorId,
+		Status:      model.IN_PROGRESS,
+		Timestamp:   now,
+		Exercises:   exercises,
+		Template:    template.ID,
+	}
+}
+
+// StartSessionFromTemplate creates an in-progress session for the requesting
+// account from a template they can view, with the target sets, reps and
+// weights pre-filled, and returns the newly created session ID
+func (controller *AresController) StartSessionFromTemplate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		templateId := ctx.Param("templateId")
+		attachedPermissions := ctx.Keys["attachedPermissions"].([]model.Permission)
+
+		accountIdHex, err := primitive.ObjectIDFromHex(ctx.GetString("accountId"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad account id hex"})
+			return
+		}
+
+		_, err = primitive.ObjectIDFromHex(templateId)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "bad template id hex"})
+			return
+		}
+
+		template, err := database.FindDocumentById[model.WorkoutTemplate](database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: controller.CollectionName,
+		}, templateId)
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		canView, err := canViewTemplate(controller.DB, controller.DatabaseName, accountIdHex, template, attachedPermissions)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to look up follow record: " + err.Error()})
+			return
+		}
+
+		if !canView {
+			ctx.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		inserted, err := database.InsertOne(database.QueryParams{
+			MongoClient:    controller.DB,
+			DatabaseName:   controller.DatabaseName,
+			CollectionName: "exercise_sessions",
+		}, sessionFromTemplate(template, accountIdHex))
+
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to insert document"})
+			return
+		}
+
+		err = audit.CreateAndSaveEntry(audit.CreateEntryParams{
+			MongoClient: controller.DB,
+			Initiator:   accountIdHex,
+			IP:          ctx.ClientIP(),
+			EventName:   audit.CREATE_TRAINING_SESSION,
+			Context:     []string{"session id: " + inserted, "template id: " + template.ID.Hex()},
+		})
+
+		if err != nil {
+			fmt.Println("failed to save audit entry: ", err)
+		}
+
+		ctx.JSON(http.StatusCreated, gin.H{"message": inserted})
+	}
+}
@@ -123,3 +123,41 @@ func Exists(s3Client *s3.Client, bucket string, filename string) (bool, error) {
 
 	return true, nil
 }
+
+// UploadObject accepts an S3 client instance, a bucket name, and a key to
+// store the provided file buffer under with the provided content type.
+//
+// Unlike UploadFile the key is chosen by the caller, so generated objects
+// can be stored under a predictable path
+func UploadObject(s3Client *s3.Client, bucket string, key string, contentType string, file []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(60)*time.Second)
+	defer cancel()
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(file),
+		ContentType: aws.String(contentType),
+	}
+
+	_, err := s3Client.PutObject(ctx, input)
+
+	return err
+}
+
+// DeleteObject accepts an S3 client instance, a bucket name, and
+// a file name to remove from the S3 instance. Removing an object
+// that does not exist is not an error
+func DeleteObject(s3Client *s3.Client, bucket string, filename string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(60)*time.Second)
+	defer cancel()
+
+	input := &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(filename),
+	}
+
+	_, err := s3Client.DeleteObject(ctx, input)
+
+	return err
+}
@@ -179,3 +179,161 @@ func Count(params QueryParams, filter interface{}) (int64, error) {
 
 	return count, err
 }
+
+// UpdateOneByFilter applies a raw BSON update document to the first
+// document matching the provided filter
+func UpdateOneByFilter(params QueryParams, filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	collection := params.MongoClient.Database(params.DatabaseName).Collection(params.CollectionName)
+
+	defer cancel()
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+
+	return result, err
+}
+
+// FindOneAndUpsert updates the first document matching the provided filter,
+// inserting a new document if nothing matched. The document is returned as it
+// was before the update, or mongo.ErrNoDocuments if a new document was inserted
+func FindOneAndUpsert[K any](params QueryParams, filter interface{}, update interface{}) (K, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	collection := params.MongoClient.Database(params.DatabaseName).Collection(params.CollectionName)
+
+	defer cancel()
+
+	var document K
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&document)
+
+	return document, err
+}
+
+// Aggregate runs the provided aggregation pipeline against the collection
+// and decodes every resulting document
+func Aggregate[K any](params QueryParams, pipeline interface{}) ([]K, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	collection := params.MongoClient.Database(params.DatabaseName).Collection(params.CollectionName)
+
+	defer cancel()
+
+	var documents []K
+	aggregateCursor, aggregateErr := collection.Aggregate(ctx, pipeline)
+
+	if aggregateErr != nil {
+		return documents, aggregateErr
+	}
+
+	traverseErr := aggregateCursor.All(ctx, &documents)
+
+	return documents, traverseErr
+}
+
+// UpdateManyByFilter applies a raw BSON update document to every
+// document matching the provided filter
+func UpdateManyByFilter(params QueryParams, filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	collection := params.MongoClient.Database(params.DatabaseName).Collection(params.CollectionName)
+
+	defer cancel()
+
+	result, err := collection.UpdateMany(ctx, filter, update)
+
+	return result, err
+}
+
+// InsertMany adds every provided document to the database without stopping
+// at the first failure. The IDs of the inserted documents are returned along
+// with the number of documents rejected as duplicates of a unique index, any
+// other write error is returned as is
+func InsertMany[K any](params QueryParams, documents []K) ([]string, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	collection := params.MongoClient.Database(params.DatabaseName).Collection(params.CollectionName)
+
+	defer cancel()
+
+	if len(documents) == 0 {
+		return nil, 0, nil
+	}
+
+	values := make([]interface{}, len(documents))
+	for i, document := range documents {
+		values[i] = document
+	}
+
+	result, err := collection.InsertMany(ctx, values, options.InsertMany().SetOrdered(false))
+
+	// the result holds an ID for every document, including the ones that
+	// failed to insert
+	failed := map[int]bool{}
+
+	if err != nil {
+		bulkErr, ok := err.(mongo.BulkWriteException)
+		if !ok || result == nil {
+			return nil, 0, err
+		}
+
+		for _, writeErr := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(writeErr.WriteError) {
+				return nil, 0, err
+			}
+
+			failed[writeErr.Index] = true
+		}
+	}
+
+	var ids []string
+	for i, id := range result.InsertedIDs {
+		if failed[i] {
+			continue
+		}
+
+		ids = append(ids, id.(primitive.ObjectID).Hex())
+	}
+
+	return ids, len(failed), nil
+}
+
+// BulkWrite applies every provided write model without stopping at the first
+// failure. The number of writes rejected as duplicates of a unique index is
+// returned along with the result, any other write error is returned as is
+func BulkWrite(params QueryParams, models []mongo.WriteModel) (*mongo.BulkWriteResult, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	collection := params.MongoClient.Database(params.DatabaseName).Collection(params.CollectionName)
+
+	defer cancel()
+
+	if len(models) == 0 {
+		return &mongo.BulkWriteResult{}, 0, nil
+	}
+
+	result, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		bulkErr, ok := err.(mongo.BulkWriteException)
+		if !ok || result == nil {
+			return nil, 0, err
+		}
+
+		for _, writeErr := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(writeErr.WriteError) {
+				return nil, 0, err
+			}
+		}
+
+		return result, len(bulkErr.WriteErrors), nil
+	}
+
+	return result, 0, nil
+}
+
+// DeleteManyByFilter removes every document matching the provided filter
+func DeleteManyByFilter(params QueryParams, filter interface{}) (*mongo.DeleteResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	collection := params.MongoClient.Database(params.DatabaseName).Collection(params.CollectionName)
+
+	defer cancel()
+
+	result, err := collection.DeleteMany(ctx, filter)
+
+	return result, err
+}
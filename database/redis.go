@@ -65,3 +65,51 @@ func DeleteCacheValue(params RedisClientParams, key string) (int64, error) {
 	deleteResult := params.RedisClient.Del(ctx, key)
 	return deleteResult.Result()
 }
+
+// RefreshTokensKey returns the key of the set holding every refresh token
+// issued to an account, used to revoke them all at once
+func RefreshTokensKey(accountId string) string {
+	return "refresh_tokens:" + accountId
+}
+
+// AddSetMember adds a member to the set stored at key. The ttl of the set is
+// refreshed so it lives as long as its newest member
+func AddSetMember(params RedisClientParams, key string, member string, ttl int) error {
+	if params.RedisClient == nil {
+		panic("attempted to access redis client but was nil")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	pipe := params.RedisClient.TxPipeline()
+	pipe.SAdd(ctx, key, member)
+	pipe.Expire(ctx, key, time.Duration(ttl)*time.Minute)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// RemoveSetMember removes a member from the set stored at key
+func RemoveSetMember(params RedisClientParams, key string, member string) (int64, error) {
+	if params.RedisClient == nil {
+		panic("attempted to access redis client but was nil")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	return params.RedisClient.SRem(ctx, key, member).Result()
+}
+
+// GetSetMembers returns every member of the set stored at key
+func GetSetMembers(params RedisClientParams, key string) ([]string, error) {
+	if params.RedisClient == nil {
+		panic("attempted to access redis client but was nil")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	return params.RedisClient.SMembers(ctx, key).Result()
+}
@@ -0,0 +1,311 @@
+package deletion
+
+import (
+	"ares/database"
+	"ares/model"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// dependent is a collection holding documents that belong to an account
+type dependent struct {
+	collectionName string
+	// field of the _deleted document holding the original document
+	field  string
+	filter func(accountId primitive.ObjectID) bson.M
+}
+
+func byField(field string) func(accountId primitive.ObjectID) bson.M {
+	return func(accountId primitive.ObjectID) bson.M {
+		return bson.M{field: accountId}
+	}
+}
+
+func byEither(a string, b string) func(accountId primitive.ObjectID) bson.M {
+	return func(accountId primitive.ObjectID) bson.M {
+		return bson.M{"$or": bson.A{bson.M{a: accountId}, bson.M{b: accountId}}}
+	}
+}
+
+// dependents are soft deleted along with an account. Locations are shared
+// places used by other accounts and audit entries are a security record, both
+// are left in place
+var dependents = []dependent{
+	{"post", "post", byField("author")},
+	{"comment", "comment", byField("author")},
+	{"like", "like", byField("author")},
+	{"follow", "follow", byEither("followingId", "followedId")},
+	{"exercise_sessions", "session", byField("author")},
+	{"route", "route", byField("author")},
+	{"personal_record", "record", byField("account")},
+	{"workout_template", "template", byField("author")},
+	{"program", "program", byField("author")},
+	{"program_enrollment", "enrollment", byField("account")},
+	{"coach_link", "link", byEither("coach", "athlete")},
+	{"session_comment", "comment", byField("author")},
+	{"file", "file", byField("owner")},
+	{"export_job", "job", byField("account")},
+	{"diet_entry", "entry", byField("account")},
+	{"nutrition_target", "target", byField("account")},
+	{"recipe", "recipe", byField("author")},
+	{"meal_plan", "plan", byField("account")},
+	{"body_measurement", "measurement", byField("account")},
+	{"notification", "notification", byField("recipient")},
+	{"device_token", "device", byField("account")},
+	{"message", "message", byField("author")},
+	{"block", "block", byEither("blocker", "blocked")},
+}
+
+// Receipt records everything removed along with an account
+type Receipt struct {
+	Account       primitive.ObjectID `json:"account"`
+	DeletedId     string             `json:"deletedId"`
+	DeletedAt     time.Time          `json:"deletedAt"`
+	RemovalAt     time.Time          `json:"removalAt"`
+	Documents     map[string]int64   `json:"documents"`
+	RevokedTokens int                `json:"revokedTokens"`
+	Transaction   bool               `json:"transaction"`
+}
+
+// Context returns the receipt as audit entry context
+func (receipt Receipt) Context() []string {
+	context := []string{
+		"deleted id: " + receipt.DeletedId,
+		"removal at: " + receipt.RemovalAt.Format(time.RFC3339),
+		fmt.Sprintf("revoked refresh tokens: %d", receipt.RevokedTokens),
+		fmt.Sprintf("transaction: %t", receipt.Transaction),
+	}
+
+	for _, dependent := range dependents {
+		if count := receipt.Documents[dependent.collectionName]; count > 0 {
+			context = append(context, fmt.Sprintf("%s: %d", dependent.collectionName, count))
+		}
+	}
+
+	return context
+}
+
+// Orchestrator deletes an account along with everything that belongs to it
+type Orchestrator struct {
+	MongoClient  *mongo.Client
+	DatabaseName string
+	RedisClient  *redis.Client
+}
+
+// transactionsUnsupported returns true if the error means the deployment
+// can't run transactions, e.g. a standalone server rather than a replica set
+func transactionsUnsupported(err error) bool {
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) {
+		// IllegalOperation and OperationNotSupportedInTransaction
+		return commandErr.Code == 20 || commandErr.Code == 263
+	}
+
+	return false
+}
+
+// run applies fn in a transaction where the deployment supports them, and
+// without one otherwise. fn may be called more than once
+func (orchestrator Orchestrator) run(fn func(ctx context.Context) error) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	session, err := orchestrator.MongoClient.StartSession()
+	if err != nil {
+		return false, err
+	}
+
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+
+	if err == nil || !transactionsUnsupported(err) {
+		return err == nil, err
+	}
+
+	return false, fn(ctx)
+}
+
+// move soft deletes the documents matching the filter by wrapping them in the
+// _deleted version of the collection
+func move(ctx context.Context, db *mongo.Database, collectionName string, field string, filter bson.M, extra bson.M) (int64, error) {
+	cursor, err := db.Collection(collectionName).Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var documents []bson.M
+	err = cursor.All(ctx, &documents)
+	if err != nil || len(documents) == 0 {
+		return 0, err
+	}
+
+	wrapped := make([]interface{}, len(documents))
+	ids := make(bson.A, len(documents))
+
+	for i, document := range documents {
+		deleted := bson.M{field: document}
+		for key, value := range extra {
+			deleted[key] = value
+		}
+
+		wrapped[i] = deleted
+		ids[i] = document["_id"]
+	}
+
+	_, err = db.Collection(collectionName+"_deleted").InsertMany(ctx, wrapped)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.Collection(collectionName).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// DeleteAccount moves an account and every document belonging to it to the
+// _deleted collections until removalAt, then revokes every refresh token
+// issued to the account
+func (orchestrator Orchestrator) DeleteAccount(account model.Account, removalAt time.Time) (Receipt, error) {
+	db := orchestrator.MongoClient.Database(orchestrator.DatabaseName)
+	receipt := Receipt{
+		Account:   account.ID,
+		DeletedAt: time.Now(),
+		RemovalAt: removalAt,
+	}
+
+	transaction, err := orchestrator.run(func(ctx context.Context) error {
+		receipt.Documents = map[string]int64{}
+
+		inserted, err := db.Collection("account_deleted").InsertOne(ctx, model.DeletedAccount{
+			Account:   account,
+			RemovalAt: removalAt,
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to insert deleted account: %w", err)
+		}
+
+		receipt.DeletedId = inserted.InsertedID.(primitive.ObjectID).Hex()
+
+		result, err := db.Collection("account").DeleteOne(ctx, bson.M{"_id": account.ID})
+		if err != nil {
+			return fmt.Errorf("failed to delete account: %w", err)
+		}
+
+		if result.DeletedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+
+		for _, dependent := range dependents {
+			count, err := move(ctx, db, dependent.collectionName, dependent.field, dependent.filter(account.ID), bson.M{
+				"removalAt": removalAt,
+				"cascade":   account.ID,
+			})
+
+			if err != nil {
+				return fmt.Errorf("failed to delete %s: %w", dependent.collectionName, err)
+			}
+
+			receipt.Documents[dependent.collectionName] = count
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	receipt.Transaction = transaction
+	receipt.RevokedTokens, err = orchestrator.RevokeRefreshTokens(account.ID)
+
+	return receipt, err
+}
+
+// RestoreAccount moves the documents deleted along with an account back in
+// to their collections. The account itself is restored separately
+func (orchestrator Orchestrator) RestoreAccount(accountId primitive.ObjectID) (map[string]int64, error) {
+	db := orchestrator.MongoClient.Database(orchestrator.DatabaseName)
+	restored := map[string]int64{}
+
+	_, err := orchestrator.run(func(ctx context.Context) error {
+		for _, dependent := range dependents {
+			deletedCollection := db.Collection(dependent.collectionName + "_deleted")
+
+			cursor, err := deletedCollection.Find(ctx, bson.M{"cascade": accountId})
+			if err != nil {
+				return err
+			}
+
+			var documents []bson.M
+			err = cursor.All(ctx, &documents)
+			if err != nil {
+				return err
+			}
+
+			if len(documents) == 0 {
+				continue
+			}
+
+			originals := make([]interface{}, len(documents))
+			for i, document := range documents {
+				originals[i] = document[dependent.field]
+			}
+
+			_, err = db.Collection(dependent.collectionName).InsertMany(ctx, originals)
+			if err != nil {
+				return fmt.Errorf("failed to restore %s: %w", dependent.collectionName, err)
+			}
+
+			result, err := deletedCollection.DeleteMany(ctx, bson.M{"cascade": accountId})
+			if err != nil {
+				return err
+			}
+
+			restored[dependent.collectionName] = result.DeletedCount
+		}
+
+		return nil
+	})
+
+	return restored, err
+}
+
+// RevokeRefreshTokens removes every refresh token issued to an account from
+// the cache, returning the number of tokens revoked
+func (orchestrator Orchestrator) RevokeRefreshTokens(accountId primitive.ObjectID) (int, error) {
+	params := database.RedisClientParams{RedisClient: orchestrator.RedisClient}
+	key := database.RefreshTokensKey(accountId.Hex())
+
+	tokens, err := database.GetSetMembers(params, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up refresh tokens: %w", err)
+	}
+
+	revoked := 0
+	for _, token := range tokens {
+		count, err := database.DeleteCacheValue(params, token)
+		if err != nil {
+			return revoked, fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+
+		revoked += int(count)
+	}
+
+	_, err = database.DeleteCacheValue(params, key)
+
+	return revoked, err
+}
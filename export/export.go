@@ -0,0 +1,374 @@
+package export
+
+import (
+	"archive/zip"
+	"ares/audit"
+	"ares/database"
+	"ares/model"
+	"ares/notification"
+	"ares/units"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Dataset is a named set of documents held about an account, written to the
+// archive as <name>.json and <name>.csv
+type Dataset struct {
+	Name      string
+	Documents interface{}
+}
+
+// find loads every document of a collection matching the filter into a
+// dataset, a missing result is written as an empty array
+func find[K any](mongoClient *mongo.Client, databaseName string, collectionName string, filter bson.M) (Dataset, error) {
+	documents, err := database.FindManyDocumentsByFilter[K](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: collectionName,
+	}, filter)
+
+	if documents == nil {
+		documents = []K{}
+	}
+
+	return Dataset{Name: collectionName, Documents: documents}, err
+}
+
+// Collect loads everything held about an account. The account password hash
+// is never included
+func Collect(mongoClient *mongo.Client, databaseName string, accountId primitive.ObjectID) ([]Dataset, error) {
+	account, err := database.FindDocumentById[model.Account](database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "account",
+	}, accountId.Hex())
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up account: %w", err)
+	}
+
+	account.Password = ""
+	datasets := []Dataset{{Name: "account", Documents: []model.Account{account}}}
+
+	loaders := []func() (Dataset, error){
+		func() (Dataset, error) {
+			return find[model.Session](mongoClient, databaseName, "exercise_sessions", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.Route](mongoClient, databaseName, "route", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.PersonalRecord](mongoClient, databaseName, "personal_record", bson.M{"account": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.WorkoutTemplate](mongoClient, databaseName, "workout_template", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.Program](mongoClient, databaseName, "program", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.ProgramEnrollment](mongoClient, databaseName, "program_enrollment", bson.M{"account": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.CoachLink](mongoClient, databaseName, "coach_link", bson.M{"$or": bson.A{
+				bson.M{"coach": accountId},
+				bson.M{"athlete": accountId},
+			}})
+		},
+		func() (Dataset, error) {
+			return find[model.SessionComment](mongoClient, databaseName, "session_comment", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.Post](mongoClient, databaseName, "post", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.Comment](mongoClient, databaseName, "comment", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.Like](mongoClient, databaseName, "like", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.Follow](mongoClient, databaseName, "follow", bson.M{"$or": bson.A{
+				bson.M{"followingId": accountId},
+				bson.M{"followedId": accountId},
+			}})
+		},
+		func() (Dataset, error) {
+			return find[model.Location](mongoClient, databaseName, "location", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.BlogPost](mongoClient, databaseName, "blog", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.File](mongoClient, databaseName, "file", bson.M{"owner": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.DietEntry](mongoClient, databaseName, "diet_entry", bson.M{"account": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.NutritionTarget](mongoClient, databaseName, "nutrition_target", bson.M{"account": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.Recipe](mongoClient, databaseName, "recipe", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.MealPlan](mongoClient, databaseName, "meal_plan", bson.M{"account": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.BodyMeasurement](mongoClient, databaseName, "body_measurement", bson.M{"account": accountId})
+		},
+		func() (Dataset, error) {
+			return find[notification.Notification](mongoClient, databaseName, "notification", bson.M{"recipient": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.DeviceToken](mongoClient, databaseName, "device_token", bson.M{"account": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.Conversation](mongoClient, databaseName, "conversation", bson.M{"participants": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.Message](mongoClient, databaseName, "message", bson.M{"author": accountId})
+		},
+		func() (Dataset, error) {
+			return find[model.Block](mongoClient, databaseName, "block", bson.M{"blocker": accountId})
+		},
+		func() (Dataset, error) {
+			return find[audit.Entry](mongoClient, databaseName, "audit", bson.M{"initiator": accountId})
+		},
+	}
+
+	for _, load := range loaders {
+		dataset, err := load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect %s: %w", dataset.Name, err)
+		}
+
+		datasets = append(datasets, dataset)
+	}
+
+	return datasets, nil
+}
+
+// flatten converts documents into CSV rows. Every top level field becomes a
+// column, nested values are written as JSON
+func flatten(documents interface{}) ([][]string, error) {
+	encoded, err := json.Marshal(documents)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []map[string]json.RawMessage
+	err = json.Unmarshal(encoded, &objects)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]bool{}
+	for _, object := range objects {
+		for key := range object {
+			columns[key] = true
+		}
+	}
+
+	header := make([]string, 0, len(columns))
+	for key := range columns {
+		header = append(header, key)
+	}
+
+	sort.Strings(header)
+	rows := [][]string{header}
+
+	for _, object := range objects {
+		row := make([]string, len(header))
+
+		for i, key := range header {
+			value, exists := object[key]
+			if !exists {
+				continue
+			}
+
+			// strings are written without their quotes
+			var s string
+			if json.Unmarshal(value, &s) == nil {
+				row[i] = s
+			} else {
+				row[i] = string(value)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// sessionRows converts sessions into one CSV row per set, using the same
+// columns as a Strong export so the file can be imported again. Weights are
+// written in kilograms and distances in kilometers
+func sessionRows(sessions []model.Session) [][]string {
+	rows := [][]string{{"Date", "Workout Name", "Exercise Name", "Set Order", "Weight", "Reps", "Distance", "Seconds", "RPE"}}
+
+	for _, session := range sessions {
+		for _, exercise := range session.Exercises {
+			order := 0
+
+			for _, set := range exercise.Sets {
+				setOrder := "W"
+				if !set.Warmup {
+					order++
+					setOrder = fmt.Sprint(order)
+				}
+
+				rows = append(rows, []string{
+					session.Timestamp.UTC().Format("2006-01-02 15:04:05"),
+					session.SessionName,
+					exercise.ExerciseName,
+					setOrder,
+					fmt.Sprint(units.Kilograms(set.Weight)),
+					fmt.Sprint(set.Reps),
+					fmt.Sprint(units.Meters(set.Distance) / units.MetersPerKilometer),
+					fmt.Sprint(set.Time.Value / 1000),
+					fmt.Sprint(set.RPE),
+				})
+			}
+		}
+	}
+
+	return rows
+}
+
+// Archive writes the datasets to a zip file as JSON and CSV
+func Archive(datasets []Dataset) ([]byte, error) {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+
+	for _, dataset := range datasets {
+		jsonFile, err := archive.Create(dataset.Name + ".json")
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(jsonFile)
+		encoder.SetIndent("", "  ")
+
+		err = encoder.Encode(dataset.Documents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", dataset.Name, err)
+		}
+
+		var rows [][]string
+		if sessions, ok := dataset.Documents.([]model.Session); ok {
+			rows = sessionRows(sessions)
+		} else {
+			rows, err = flatten(dataset.Documents)
+			if err != nil {
+				return nil, fmt.Errorf("failed to flatten %s: %w", dataset.Name, err)
+			}
+		}
+
+		csvFile, err := archive.Create(dataset.Name + ".csv")
+		if err != nil {
+			return nil, err
+		}
+
+		err = csv.NewWriter(csvFile).WriteAll(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", dataset.Name, err)
+		}
+	}
+
+	err := archive.Close()
+
+	return buffer.Bytes(), err
+}
+
+// Timeout is how long an export can stay pending. Exports run in the
+// process that accepted them, a job still pending after the timeout was
+// interrupted and is never completed
+const Timeout = 30 * time.Minute
+
+// FailStale marks the exports pending for longer than Timeout as failed, so
+// the account they were requested for can request a new export
+func FailStale(mongoClient *mongo.Client, databaseName string, now time.Time) (int64, error) {
+	result, err := database.UpdateManyByFilter(database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "export_job",
+	}, bson.M{
+		"status":      model.EXPORT_PENDING,
+		"requestedAt": bson.M{"$lt": now.Add(-Timeout)},
+	}, bson.M{"$set": bson.M{
+		"status":      model.EXPORT_FAILED,
+		"error":       "export was interrupted",
+		"completedAt": now,
+	}})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Run collects and archives the data of the account the job was requested
+// for, uploads the archive to the bucket and marks the job as ready. Failures
+// are recorded on the job. A job FailStale marked as failed in the meantime
+// is left failed
+func Run(mongoClient *mongo.Client, databaseName string, s3Client *s3.Client, bucket string, job model.ExportJob) {
+	jobParams := database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "export_job",
+	}
+
+	fail := func(err error) {
+		_, updateErr := database.UpdateOneByFilter(jobParams, bson.M{"_id": job.ID, "status": model.EXPORT_PENDING}, bson.M{"$set": bson.M{
+			"status":      model.EXPORT_FAILED,
+			"error":       err.Error(),
+			"completedAt": time.Now(),
+		}})
+
+		if updateErr != nil {
+			fmt.Println("failed to update export job: ", updateErr)
+		}
+	}
+
+	datasets, err := Collect(mongoClient, databaseName, job.Account)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	archive, err := Archive(datasets)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	key := "export/" + job.Account.Hex() + "/" + job.ID.Hex() + ".zip"
+
+	err = database.UploadObject(s3Client, bucket, key, "application/zip", archive)
+	if err != nil {
+		fail(fmt.Errorf("failed to upload archive: %w", err))
+		return
+	}
+
+	_, err = database.UpdateOneByFilter(jobParams, bson.M{"_id": job.ID, "status": model.EXPORT_PENDING}, bson.M{"$set": bson.M{
+		"status":      model.EXPORT_READY,
+		"key":         key,
+		"completedAt": time.Now(),
+	}})
+
+	if err != nil {
+		fmt.Println("failed to update export job: ", err)
+	}
+}
@@ -0,0 +1,233 @@
+package importer
+
+import (
+	"ares/model"
+	"ares/units"
+	"errors"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	GPX Format = "GPX"
+	TCX Format = "TCX"
+	FIT Format = "FIT"
+)
+
+// earthRadius is the mean radius of the earth in meters
+const earthRadius = 6371008.8
+
+// Point is a single sample of a recorded activity. Values that were not
+// recorded are left as zero, HasPosition is false for samples without a fix
+type Point struct {
+	Time        time.Time
+	Latitude    float64
+	Longitude   float64
+	HasPosition bool
+	Elevation   float64
+	HeartRate   uint8
+	// Distance is the cumulative distance in meters as recorded by the
+	// device, zero if the device did not record it
+	Distance float64
+}
+
+// Activity is a recorded activity parsed from a GPX, TCX or FIT file
+type Activity struct {
+	Format Format
+	Sport  string
+	Points []Point
+}
+
+// Summary holds the totals of an activity. Distance and elevation gain are in
+// meters, duration in milliseconds and pace in seconds per kilometer
+type Summary struct {
+	Distance         float64
+	Duration         uint64
+	Pace             float64
+	ElevationGain    float64
+	AverageHeartRate uint8
+	MaxHeartRate     uint8
+}
+
+// ParseActivity parses an activity file, detecting the format from the
+// extension of the provided file name
+func ParseActivity(filename string, r io.Reader) (Activity, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gpx":
+		return ParseGPX(r)
+	case ".tcx":
+		return ParseTCX(r)
+	case ".fit":
+		return ParseFIT(r)
+	}
+
+	return Activity{}, errors.New("unsupported activity file, expected a GPX, TCX or FIT file")
+}
+
+// haversine returns the great circle distance between two points in meters
+func haversine(a, b Point) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// Summarize computes the totals of an activity. The distance recorded by the
+// device is preferred, activities without one fall back to the distance
+// between consecutive positions
+func (activity Activity) Summarize() Summary {
+	var summary Summary
+	var previous *Point
+	var heartRateTotal, heartRateSamples uint64
+	var recorded, measured float64
+
+	for i := range activity.Points {
+		point := activity.Points[i]
+
+		if point.Distance > recorded {
+			recorded = point.Distance
+		}
+
+		if point.HeartRate > 0 {
+			heartRateTotal += uint64(point.HeartRate)
+			heartRateSamples++
+
+			if point.HeartRate > summary.MaxHeartRate {
+				summary.MaxHeartRate = point.HeartRate
+			}
+		}
+
+		if !point.HasPosition {
+			continue
+		}
+
+		if previous != nil {
+			measured += haversine(*previous, point)
+
+			if point.Elevation > previous.Elevation {
+				summary.ElevationGain += point.Elevation - previous.Elevation
+			}
+		}
+
+		previous = &activity.Points[i]
+	}
+
+	summary.Distance = measured
+	if recorded > 0 {
+		summary.Distance = recorded
+	}
+
+	if len(activity.Points) > 1 {
+		first := activity.Points[0].Time
+		last := activity.Points[len(activity.Points)-1].Time
+		summary.Duration = uint64(last.Sub(first).Milliseconds())
+	}
+
+	if summary.Distance > 0 {
+		summary.Pace = float64(summary.Duration) / 1000 / (summary.Distance / 1000)
+	}
+
+	if heartRateSamples > 0 {
+		summary.AverageHeartRate = uint8(heartRateTotal / heartRateSamples)
+	}
+
+	return summary
+}
+
+// StartedAt returns the time of the first sample of the activity
+func (activity Activity) StartedAt() time.Time {
+	if len(activity.Points) == 0 {
+		return time.Time{}
+	}
+
+	return activity.Points[0].Time
+}
+
+// Path returns the positions of the activity as [longitude, latitude]
+// pairs, the coordinate order used by GeoJSON
+func (activity Activity) Path() [][]float64 {
+	var path [][]float64
+
+	for _, point := range activity.Points {
+		if point.HasPosition {
+			path = append(path, []float64{point.Longitude, point.Latitude})
+		}
+	}
+
+	return path
+}
+
+// ActivitySession turns an activity into a completed session with a single
+// DISTANCE_TIME exercise and the route it was recorded on. The session and
+// route reference each other, so both are given new IDs
+func ActivitySession(activity Activity, author primitive.ObjectID) (model.Session, model.Route) {
+	summary := activity.Summarize()
+	startedAt := activity.StartedAt()
+
+	name := "Activity"
+	if activity.Sport != "" {
+		name = strings.ToUpper(activity.Sport[:1]) + strings.ToLower(activity.Sport[1:])
+	}
+
+	route := model.Route{
+		ID:               primitive.NewObjectID(),
+		Author:           author,
+		Source:           string(activity.Format),
+		Distance:         units.Round(summary.Distance),
+		Duration:         summary.Duration,
+		Pace:             units.Round(summary.Pace),
+		ElevationGain:    units.Round(summary.ElevationGain),
+		AverageHeartRate: summary.AverageHeartRate,
+		MaxHeartRate:     summary.MaxHeartRate,
+		StartedAt:        startedAt,
+		CreatedAt:        time.Now(),
+	}
+
+	if path := activity.Path(); len(path) > 1 {
+		route.Path = &model.LineString{Type: "LineString", Coordinates: path}
+	}
+
+	for _, point := range activity.Points {
+		if point.HeartRate > 0 {
+			route.HeartRate = append(route.HeartRate, model.HeartRateSample{Time: point.Time, BPM: point.HeartRate})
+		}
+	}
+
+	session := model.Session{
+		ID:          primitive.NewObjectID(),
+		SessionName: name,
+		Author:      author,
+		Status:      model.COMPLETED,
+		Timestamp:   startedAt,
+		Route:       route.ID,
+		ImportKey:   ImportKey(author, activity.Format, startedAt, activity.Sport),
+		Exercises: []model.Exercise{{
+			ExerciseName: name,
+			AddedAt:      startedAt,
+			Type:         model.DISTANCE_TIME,
+			Sets: []model.ExerciseSet{{
+				Distance: model.ExerciseValueDistance{
+					Value:       uint32(math.Round(summary.Distance)),
+					Measurement: model.METER,
+					Meters:      route.Distance,
+				},
+				Time:        model.ExerciseValueTime{Value: summary.Duration},
+				Completed:   true,
+				CompletedAt: startedAt.Add(time.Duration(summary.Duration) * time.Millisecond),
+			}},
+		}},
+	}
+
+	route.Session = session.ID
+
+	return session, route
+}
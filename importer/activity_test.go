@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseActivity(t *testing.T) {
+	tests := []struct {
+		file             string
+		format           Format
+		sport            string
+		startedAt        time.Time
+		points           int
+		positions        int
+		distance         float64
+		duration         uint64
+		elevationGain    float64
+		averageHeartRate uint8
+		maxHeartRate     uint8
+	}{
+		{
+			file:             "run.gpx",
+			format:           GPX,
+			sport:            "running",
+			startedAt:        time.Date(2022, 6, 1, 7, 0, 0, 0, time.UTC),
+			points:           3,
+			positions:        3,
+			distance:         222.39,
+			duration:         60000,
+			elevationGain:    2.5,
+			averageHeartRate: 140,
+			maxHeartRate:     160,
+		},
+		{
+			file:             "ride.tcx",
+			format:           TCX,
+			sport:            "Biking",
+			startedAt:        time.Date(2022, 6, 2, 18, 0, 0, 0, time.UTC),
+			points:           3,
+			positions:        2,
+			distance:         1000,
+			duration:         240000,
+			elevationGain:    10,
+			averageHeartRate: 130,
+			maxHeartRate:     150,
+		},
+		{
+			file:      "indoor.tcx",
+			format:    TCX,
+			sport:     "Running",
+			startedAt: time.Date(2022, 6, 3, 6, 0, 0, 0, time.UTC),
+			points:    2,
+			positions: 0,
+			distance:  1000,
+			duration:  300000,
+		},
+		{
+			file:             "walk.fit",
+			format:           FIT,
+			sport:            "walking",
+			startedAt:        time.Date(2022, 6, 1, 7, 0, 0, 0, time.UTC),
+			points:           3,
+			positions:        2,
+			distance:         220,
+			duration:         120000,
+			elevationGain:    5,
+			averageHeartRate: 110,
+			maxHeartRate:     120,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			file, err := os.Open(filepath.Join("testdata", test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer file.Close()
+
+			activity, err := ParseActivity(test.file, file)
+			if err != nil {
+				t.Fatalf("failed to parse activity: %v", err)
+			}
+
+			if activity.Format != test.format {
+				t.Errorf("format = %s, want %s", activity.Format, test.format)
+			}
+
+			if activity.Sport != test.sport {
+				t.Errorf("sport = %q, want %q", activity.Sport, test.sport)
+			}
+
+			if !activity.StartedAt().Equal(test.startedAt) {
+				t.Errorf("started at = %s, want %s", activity.StartedAt(), test.startedAt)
+			}
+
+			if len(activity.Points) != test.points {
+				t.Errorf("points = %d, want %d", len(activity.Points), test.points)
+			}
+
+			if len(activity.Path()) != test.positions {
+				t.Errorf("positions = %d, want %d", len(activity.Path()), test.positions)
+			}
+
+			summary := activity.Summarize()
+
+			if math.Abs(summary.Distance-test.distance) > 0.01 {
+				t.Errorf("distance = %f, want %f", summary.Distance, test.distance)
+			}
+
+			if summary.Duration != test.duration {
+				t.Errorf("duration = %d, want %d", summary.Duration, test.duration)
+			}
+
+			if math.Abs(summary.ElevationGain-test.elevationGain) > 0.01 {
+				t.Errorf("elevation gain = %f, want %f", summary.ElevationGain, test.elevationGain)
+			}
+
+			if summary.AverageHeartRate != test.averageHeartRate {
+				t.Errorf("average heart rate = %d, want %d", summary.AverageHeartRate, test.averageHeartRate)
+			}
+
+			if summary.MaxHeartRate != test.maxHeartRate {
+				t.Errorf("max heart rate = %d, want %d", summary.MaxHeartRate, test.maxHeartRate)
+			}
+
+			_, route := ActivitySession(activity, primitive.NewObjectID())
+			if (route.Path != nil) != (test.positions > 1) {
+				t.Errorf("route path set = %t, want %t", route.Path != nil, test.positions > 1)
+			}
+		})
+	}
+}
+
+func TestParseActivityRejectsUnknownFormats(t *testing.T) {
+	_, err := ParseActivity("activity.csv", nil)
+	if err == nil {
+		t.Error("expected an error for an unsupported file")
+	}
+}
@@ -0,0 +1,286 @@
+package importer
+
+import (
+	"ares/model"
+	"ares/units"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	STRONG Format = "STRONG"
+	HEVY   Format = "HEVY"
+)
+
+// Row is a single set parsed from an export. Weights are in the system
+// they were logged in, distances in meters and times in seconds
+type Row struct {
+	Line         int
+	StartedAt    time.Time
+	WorkoutName  string
+	ExerciseName string
+	SetOrder     int
+	Weight       float64
+	WeightSystem model.MeasurementSystem
+	Reps         uint8
+	Distance     float64
+	Seconds      float64
+	RPE          float32
+	Warmup       bool
+}
+
+// column returns the value of a named column, or an empty string if the
+// export does not have the column
+type column func(record []string, name string) string
+
+// normalizeHeader lowercases a header name, exports saved by spreadsheet
+// apps may also start with a byte order mark
+func normalizeHeader(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+func indexColumns(header []string) column {
+	indexes := map[string]int{}
+	for i, name := range header {
+		indexes[normalizeHeader(name)] = i
+	}
+
+	return func(record []string, name string) string {
+		i, exists := indexes[name]
+		if !exists || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+}
+
+// detect returns the format of an export from its header row
+func detect(header []string) (Format, error) {
+	columns := map[string]bool{}
+	for _, name := range header {
+		columns[normalizeHeader(name)] = true
+	}
+
+	switch {
+	case columns["exercise name"] && columns["set order"]:
+		return STRONG, nil
+	case columns["exercise_title"] && columns["set_index"]:
+		return HEVY, nil
+	}
+
+	return "", errors.New("unrecognised export format, expected a Strong or Hevy CSV export")
+}
+
+func parseFloat(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseReps(s string) (uint8, error) {
+	value, err := parseFloat(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if value < 0 || value > 255 {
+		return 0, errors.New("reps out of range")
+	}
+
+	return uint8(value), nil
+}
+
+// parseStrong parses a row of a Strong export. Strong does not record the
+// units weights and distances were logged in, these follow the provided
+// system with distances in kilometers or miles
+func parseStrong(get column, record []string, system model.MeasurementSystem) (Row, error) {
+	var row Row
+	var err error
+
+	row.StartedAt, err = time.Parse("2006-01-02 15:04:05", get(record, "date"))
+	if err != nil {
+		return row, errors.New("invalid date: " + err.Error())
+	}
+
+	row.WorkoutName = get(record, "workout name")
+	row.ExerciseName = get(record, "exercise name")
+
+	// strong marks warmup, drop and failure sets with a letter instead of
+	// their position, these keep the order they were written in
+	setOrder := get(record, "set order")
+	row.Warmup = strings.EqualFold(setOrder, "w")
+	row.SetOrder, _ = strconv.Atoi(setOrder)
+
+	weight, err := parseFloat(get(record, "weight"))
+	if err != nil {
+		return row, errors.New("invalid weight: " + err.Error())
+	}
+
+	distance, err := parseFloat(get(record, "distance"))
+	if err != nil {
+		return row, errors.New("invalid distance: " + err.Error())
+	}
+
+	distanceMeasurement := model.KILOMETER
+	if system == model.IMPERIAL {
+		distanceMeasurement = model.MILE
+	}
+
+	row.Weight = weight
+	row.WeightSystem = system
+	row.Distance = units.ToMeters(distance, distanceMeasurement)
+
+	row.Reps, err = parseReps(get(record, "reps"))
+	if err != nil {
+		return row, errors.New("invalid reps: " + err.Error())
+	}
+
+	row.Seconds, err = parseFloat(get(record, "seconds"))
+	if err != nil {
+		return row, errors.New("invalid seconds: " + err.Error())
+	}
+
+	rpe, err := parseFloat(get(record, "rpe"))
+	if err != nil {
+		return row, errors.New("invalid rpe: " + err.Error())
+	}
+
+	row.RPE = float32(rpe)
+
+	return row, nil
+}
+
+// parseHevy parses a row of a Hevy export. Hevy names its weight and
+// distance columns after the units of the export
+func parseHevy(get column, record []string) (Row, error) {
+	var row Row
+	var err error
+
+	row.StartedAt, err = time.Parse("2 Jan 2006, 15:04", get(record, "start_time"))
+	if err != nil {
+		return row, errors.New("invalid start time: " + err.Error())
+	}
+
+	row.WorkoutName = get(record, "title")
+	row.ExerciseName = get(record, "exercise_title")
+	row.Warmup = get(record, "set_type") == "warmup"
+
+	row.SetOrder, err = strconv.Atoi(get(record, "set_index"))
+	if err != nil {
+		return row, errors.New("invalid set index: " + err.Error())
+	}
+
+	row.WeightSystem = model.METRIC
+	weight := get(record, "weight_kg")
+
+	if value := get(record, "weight_lbs"); value != "" {
+		row.WeightSystem = model.IMPERIAL
+		weight = value
+	}
+
+	row.Weight, err = parseFloat(weight)
+	if err != nil {
+		return row, errors.New("invalid weight: " + err.Error())
+	}
+
+	if value := get(record, "distance_miles"); value != "" {
+		distance, err := parseFloat(value)
+		if err != nil {
+			return row, errors.New("invalid distance: " + err.Error())
+		}
+
+		row.Distance = units.ToMeters(distance, model.MILE)
+	} else {
+		distance, err := parseFloat(get(record, "distance_km"))
+		if err != nil {
+			return row, errors.New("invalid distance: " + err.Error())
+		}
+
+		row.Distance = units.ToMeters(distance, model.KILOMETER)
+	}
+
+	row.Reps, err = parseReps(get(record, "reps"))
+	if err != nil {
+		return row, errors.New("invalid reps: " + err.Error())
+	}
+
+	row.Seconds, err = parseFloat(get(record, "duration_seconds"))
+	if err != nil {
+		return row, errors.New("invalid duration: " + err.Error())
+	}
+
+	rpe, err := parseFloat(get(record, "rpe"))
+	if err != nil {
+		return row, errors.New("invalid rpe: " + err.Error())
+	}
+
+	row.RPE = float32(rpe)
+
+	return row, nil
+}
+
+// Parse reads a Strong or Hevy CSV export, detecting the format from the
+// header row. The provided system is used for exports that do not record
+// the units they were logged in
+func Parse(r io.Reader, system model.MeasurementSystem) (Format, []Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return "", nil, errors.New("failed to read header: " + err.Error())
+	}
+
+	format, err := detect(header)
+	if err != nil {
+		return "", nil, err
+	}
+
+	get := indexColumns(header)
+
+	var rows []Row
+	line := 1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		line++
+
+		if err != nil {
+			return format, rows, errors.New("line " + strconv.Itoa(line) + ": " + err.Error())
+		}
+
+		var row Row
+		if format == STRONG {
+			row, err = parseStrong(get, record, system)
+		} else {
+			row, err = parseHevy(get, record)
+		}
+
+		if err != nil {
+			return format, rows, errors.New("line " + strconv.Itoa(line) + ": " + err.Error())
+		}
+
+		// strong writes rest timer rows without an exercise
+		if row.ExerciseName == "" {
+			continue
+		}
+
+		row.Line = line
+		rows = append(rows, row)
+	}
+
+	return format, rows, nil
+}
@@ -0,0 +1,267 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// fitEpoch is the unix time of the FIT epoch, 1989-12-31 00:00:00 UTC
+const fitEpoch = 631065600
+
+// semicircles converts a FIT position to degrees
+const semicircles = 180 / float64(1<<31)
+
+// global message and field numbers of the FIT profile read by the parser
+const (
+	fitMessageSession = 18
+	fitMessageRecord  = 20
+
+	fitFieldTimestamp        = 253
+	fitFieldPositionLat      = 0
+	fitFieldPositionLong     = 1
+	fitFieldAltitude         = 2
+	fitFieldHeartRate        = 3
+	fitFieldDistance         = 5
+	fitFieldEnhancedAltitude = 78
+	fitFieldSport            = 5
+)
+
+var fitSports = map[uint64]string{
+	1:  "running",
+	2:  "cycling",
+	5:  "swimming",
+	11: "walking",
+	17: "hiking",
+}
+
+type fitField struct {
+	number   byte
+	size     byte
+	baseType byte
+}
+
+type fitDefinition struct {
+	bigEndian      bool
+	globalMessage  uint16
+	fields         []fitField
+	developerBytes int
+}
+
+// value reads a numeric field, returning false if the field holds the
+// invalid value of its base type or is not numeric
+func (field fitField) value(data []byte, bigEndian bool) (int64, bool) {
+	var order binary.ByteOrder = binary.LittleEndian
+	if bigEndian {
+		order = binary.BigEndian
+	}
+
+	switch field.baseType {
+	case 0x00, 0x02, 0x0A, 0x0D:
+		if field.size < 1 || (field.baseType != 0x0A && data[0] == 0xFF) || (field.baseType == 0x0A && data[0] == 0) {
+			return 0, false
+		}
+
+		return int64(data[0]), true
+	case 0x01:
+		if field.size < 1 || data[0] == 0x7F {
+			return 0, false
+		}
+
+		return int64(int8(data[0])), true
+	case 0x83:
+		if field.size < 2 {
+			return 0, false
+		}
+
+		value := order.Uint16(data)
+		return int64(int16(value)), value != 0x7FFF
+	case 0x84, 0x8B:
+		if field.size < 2 {
+			return 0, false
+		}
+
+		value := order.Uint16(data)
+		return int64(value), (field.baseType == 0x84 && value != 0xFFFF) || (field.baseType == 0x8B && value != 0)
+	case 0x85:
+		if field.size < 4 {
+			return 0, false
+		}
+
+		value := order.Uint32(data)
+		return int64(int32(value)), value != 0x7FFFFFFF
+	case 0x86, 0x8C:
+		if field.size < 4 {
+			return 0, false
+		}
+
+		value := order.Uint32(data)
+		return int64(value), (field.baseType == 0x86 && value != 0xFFFFFFFF) || (field.baseType == 0x8C && value != 0)
+	}
+
+	return 0, false
+}
+
+// ParseFIT parses the record and session messages of a FIT activity file.
+// Only the fields needed for a route and summary are read, every other
+// message is skipped
+func ParseFIT(r io.Reader) (Activity, error) {
+	activity := Activity{Format: FIT}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return activity, errors.New("failed to read fit file: " + err.Error())
+	}
+
+	if len(data) < 12 || !bytes.Equal(data[8:12], []byte(".FIT")) {
+		return activity, errors.New("not a fit file")
+	}
+
+	headerSize := int(data[0])
+	end := headerSize + int(binary.LittleEndian.Uint32(data[4:8]))
+	if headerSize < 12 || end > len(data) {
+		return activity, errors.New("truncated fit file")
+	}
+
+	definitions := map[byte]fitDefinition{}
+	var lastTimestamp uint32
+	offset := headerSize
+
+	for offset < end {
+		header := data[offset]
+		offset++
+
+		// compressed timestamp headers are data messages carrying the low
+		// five bits of the timestamp in the header itself
+		compressed := header&0x80 != 0
+		localMessage := header & 0x0F
+		if compressed {
+			localMessage = (header >> 5) & 0x03
+		}
+
+		if !compressed && header&0x40 != 0 {
+			if offset+5 > end {
+				return activity, errors.New("truncated fit definition message")
+			}
+
+			definition := fitDefinition{bigEndian: data[offset+1] == 1}
+			if definition.bigEndian {
+				definition.globalMessage = binary.BigEndian.Uint16(data[offset+2:])
+			} else {
+				definition.globalMessage = binary.LittleEndian.Uint16(data[offset+2:])
+			}
+
+			fieldCount := int(data[offset+4])
+			offset += 5
+
+			if offset+fieldCount*3 > end {
+				return activity, errors.New("truncated fit definition message")
+			}
+
+			for i := 0; i < fieldCount; i++ {
+				definition.fields = append(definition.fields, fitField{
+					number:   data[offset],
+					size:     data[offset+1],
+					baseType: data[offset+2],
+				})
+				offset += 3
+			}
+
+			if header&0x20 != 0 {
+				if offset >= end {
+					return activity, errors.New("truncated fit definition message")
+				}
+
+				developerCount := int(data[offset])
+				offset++
+
+				if offset+developerCount*3 > end {
+					return activity, errors.New("truncated fit definition message")
+				}
+
+				for i := 0; i < developerCount; i++ {
+					definition.developerBytes += int(data[offset+1])
+					offset += 3
+				}
+			}
+
+			definitions[localMessage] = definition
+			continue
+		}
+
+		definition, exists := definitions[localMessage]
+		if !exists {
+			return activity, errors.New("fit data message without a definition")
+		}
+
+		values := map[byte]int64{}
+
+		for _, field := range definition.fields {
+			if offset+int(field.size) > end {
+				return activity, errors.New("truncated fit data message")
+			}
+
+			value, valid := field.value(data[offset:offset+int(field.size)], definition.bigEndian)
+			if valid {
+				values[field.number] = value
+			}
+
+			offset += int(field.size)
+		}
+
+		offset += definition.developerBytes
+
+		if timestamp, exists := values[fitFieldTimestamp]; exists {
+			lastTimestamp = uint32(timestamp)
+		} else if compressed {
+			timeOffset := uint32(header & 0x1F)
+			timestamp := lastTimestamp&^0x1F + timeOffset
+			if timeOffset < lastTimestamp&0x1F {
+				timestamp += 0x20
+			}
+
+			lastTimestamp = timestamp
+		}
+
+		switch definition.globalMessage {
+		case fitMessageSession:
+			if sport, exists := values[fitFieldSport]; exists && activity.Sport == "" {
+				activity.Sport = fitSports[uint64(sport)]
+			}
+		case fitMessageRecord:
+			point := Point{Time: time.Unix(int64(lastTimestamp)+fitEpoch, 0).UTC()}
+
+			lat, latExists := values[fitFieldPositionLat]
+			long, longExists := values[fitFieldPositionLong]
+			if latExists && longExists {
+				point.Latitude = float64(lat) * semicircles
+				point.Longitude = float64(long) * semicircles
+				point.HasPosition = true
+			}
+
+			if altitude, exists := values[fitFieldEnhancedAltitude]; exists {
+				point.Elevation = float64(altitude)/5 - 500
+			} else if altitude, exists := values[fitFieldAltitude]; exists {
+				point.Elevation = float64(altitude)/5 - 500
+			}
+
+			if heartRate, exists := values[fitFieldHeartRate]; exists {
+				point.HeartRate = uint8(heartRate)
+			}
+
+			if distance, exists := values[fitFieldDistance]; exists {
+				point.Distance = float64(distance) / 100
+			}
+
+			activity.Points = append(activity.Points, point)
+		}
+	}
+
+	if len(activity.Points) == 0 {
+		return activity, errors.New("fit file has no records")
+	}
+
+	return activity, nil
+}
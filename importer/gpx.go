@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+)
+
+type gpxFile struct {
+	Tracks []struct {
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []struct {
+				Latitude  float64   `xml:"lat,attr"`
+				Longitude float64   `xml:"lon,attr"`
+				Elevation float64   `xml:"ele"`
+				Time      time.Time `xml:"time"`
+				HeartRate uint8     `xml:"extensions>TrackPointExtension>hr"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// ParseGPX parses a GPX track. Heart rate is read from the Garmin track point
+// extension most devices and apps write it to
+func ParseGPX(r io.Reader) (Activity, error) {
+	var file gpxFile
+	activity := Activity{Format: GPX}
+
+	err := xml.NewDecoder(r).Decode(&file)
+	if err != nil {
+		return activity, errors.New("failed to decode gpx: " + err.Error())
+	}
+
+	for _, track := range file.Tracks {
+		if activity.Sport == "" {
+			activity.Sport = track.Type
+		}
+
+		for _, segment := range track.Segments {
+			for _, point := range segment.Points {
+				activity.Points = append(activity.Points, Point{
+					Time:        point.Time,
+					Latitude:    point.Latitude,
+					Longitude:   point.Longitude,
+					HasPosition: true,
+					Elevation:   point.Elevation,
+					HeartRate:   point.HeartRate,
+				})
+			}
+		}
+	}
+
+	if len(activity.Points) == 0 {
+		return activity, errors.New("gpx file has no track points")
+	}
+
+	return activity, nil
+}
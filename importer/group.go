@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"ares/model"
+	"ares/units"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ImportKey identifies an imported session so importing the same export
+// twice does not create duplicates
+func ImportKey(author primitive.ObjectID, format Format, startedAt time.Time, workoutName string) string {
+	hash := sha256.Sum256([]byte(author.Hex() + "|" + string(format) + "|" + strconv.FormatInt(startedAt.Unix(), 10) + "|" + workoutName))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// inferType guesses the type of an exercise that could not be matched from
+// the values logged in its sets
+func inferType(rows []Row) model.ExerciseType {
+	var weight, reps, distance, seconds bool
+
+	for _, row := range rows {
+		weight = weight || row.Weight > 0
+		reps = reps || row.Reps > 0
+		distance = distance || row.Distance > 0
+		seconds = seconds || row.Seconds > 0
+	}
+
+	switch {
+	case distance:
+		return model.DISTANCE_TIME
+	case weight && reps:
+		return model.WEIGHTED_REPS
+	case weight && seconds:
+		return model.WEIGHTED_TIME
+	case reps:
+		return model.REPS
+	case seconds:
+		return model.TIME
+	}
+
+	return model.WEIGHTED_REPS
+}
+
+// toSet converts a row into a completed set. Weights keep the system they
+// were logged in, distances are stored in meters as exports log them in
+// fractions of a kilometer or mile
+func toSet(row Row) model.ExerciseSet {
+	set := model.ExerciseSet{
+		Reps:        row.Reps,
+		RPE:         row.RPE,
+		Warmup:      row.Warmup,
+		Completed:   true,
+		CompletedAt: row.StartedAt,
+	}
+
+	if row.Weight > 0 {
+		set.Weight = units.CanonicalizeWeight(model.ExerciseValueWeight{
+			Value:       float32(units.Round(row.Weight)),
+			Measurement: row.WeightSystem,
+		})
+	}
+
+	if row.Distance > 0 {
+		set.Distance = model.ExerciseValueDistance{
+			Value:       uint32(math.Round(row.Distance)),
+			Measurement: model.METER,
+			Meters:      units.Round(row.Distance),
+		}
+	}
+
+	if row.Seconds > 0 {
+		set.Time = model.ExerciseValueTime{Value: uint64(math.Round(row.Seconds * 1000))}
+	}
+
+	return set
+}
+
+// Group turns parsed rows into completed sessions for the provided author,
+// one for every workout in the export. Exercise names are mapped onto known
+// exercises where possible, names that can't be matched keep their original
+// name and are reported by the matcher
+func Group(rows []Row, format Format, author primitive.ObjectID, matcher *Matcher) []model.Session {
+	type workoutKey struct {
+		startedAt int64
+		name      string
+	}
+
+	workouts := map[workoutKey][]Row{}
+	var order []workoutKey
+
+	for _, row := range rows {
+		key := workoutKey{row.StartedAt.Unix(), row.WorkoutName}
+		if _, exists := workouts[key]; !exists {
+			order = append(order, key)
+		}
+
+		workouts[key] = append(workouts[key], row)
+	}
+
+	sessions := make([]model.Session, 0, len(order))
+
+	for _, key := range order {
+		workoutRows := workouts[key]
+		startedAt := workoutRows[0].StartedAt
+
+		var exerciseOrder []string
+		exerciseRows := map[string][]Row{}
+
+		for _, row := range workoutRows {
+			if _, exists := exerciseRows[row.ExerciseName]; !exists {
+				exerciseOrder = append(exerciseOrder, row.ExerciseName)
+			}
+
+			exerciseRows[row.ExerciseName] = append(exerciseRows[row.ExerciseName], row)
+		}
+
+		exercises := make([]model.Exercise, 0, len(exerciseOrder))
+
+		for _, name := range exerciseOrder {
+			sets := exerciseRows[name]
+
+			// warmups first, then working sets by their position in the export
+			sort.SliceStable(sets, func(i, j int) bool {
+				if sets[i].Warmup != sets[j].Warmup {
+					return sets[i].Warmup
+				}
+
+				return sets[i].SetOrder < sets[j].SetOrder
+			})
+
+			exercise := model.Exercise{
+				ExerciseName: name,
+				AddedAt:      startedAt,
+				Type:         inferType(sets),
+			}
+
+			if match := matcher.Match(name, len(sets)); match != nil {
+				exercise.ExerciseName = match.Info.Name
+				if match.Info.Type != "" {
+					exercise.Type = match.Info.Type
+				}
+			}
+
+			for _, row := range sets {
+				exercise.Sets = append(exercise.Sets, toSet(row))
+			}
+
+			exercises = append(exercises, exercise)
+		}
+
+		sessionName := key.name
+		if sessionName == "" {
+			sessionName = "Imported workout"
+		}
+
+		sessions = append(sessions, model.Session{
+			SessionName: sessionName,
+			Author:      author,
+			Status:      model.COMPLETED,
+			Timestamp:   startedAt,
+			Exercises:   exercises,
+			ImportKey:   ImportKey(author, format, startedAt, key.name),
+		})
+	}
+
+	return sessions
+}
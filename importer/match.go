@@ -0,0 +1,182 @@
+package importer
+
+import (
+	"ares/model"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// MatchThreshold is the minimum similarity between an imported exercise name
+// and an ExerciseInfo name for the two to be considered the same exercise
+const MatchThreshold = 0.8
+
+// SuggestionThreshold is the minimum similarity for the closest name to be
+// suggested for an exercise that could not be matched
+const SuggestionThreshold = 0.5
+
+// Match is the ExerciseInfo an imported exercise name was mapped onto
+type Match struct {
+	Info       model.ExerciseInfo
+	Similarity float64
+}
+
+// Unmatched reports an imported exercise name that could not be mapped onto
+// an ExerciseInfo. Suggestion is the closest name below the threshold, if any
+type Unmatched struct {
+	ExerciseName string  `json:"exerciseName"`
+	Suggestion   string  `json:"suggestion,omitempty"`
+	Similarity   float64 `json:"similarity,omitempty"`
+	Rows         int     `json:"rows"`
+}
+
+// normalize lowercases a name and strips punctuation so names only differing
+// in formatting, e.g. "Bench Press (Barbell)" and "bench press - barbell",
+// compare equal
+func normalize(name string) string {
+	var builder strings.Builder
+
+	for _, field := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if builder.Len() > 0 {
+			builder.WriteRune(' ')
+		}
+
+		builder.WriteString(field)
+	}
+
+	return builder.String()
+}
+
+// levenshtein returns the number of single character edits needed to turn
+// one string into the other
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = previous[j] + 1
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+
+			if previous[j-1]+cost < current[j] {
+				current[j] = previous[j-1] + cost
+			}
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// similarity returns how alike two normalized names are, from 0 for
+// completely different names to 1 for identical ones
+func similarity(a, b string) float64 {
+	ar, br := []rune(a), []rune(b)
+
+	longest := len(ar)
+	if len(br) > longest {
+		longest = len(br)
+	}
+
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(levenshtein(ar, br))/float64(longest)
+}
+
+// Matcher maps imported exercise names onto known ExerciseInfo documents,
+// caching the result of every name it has seen
+type Matcher struct {
+	infos      []model.ExerciseInfo
+	normalized []string
+	matches    map[string]*Match
+	unmatched  map[string]*Unmatched
+	order      []string
+}
+
+func NewMatcher(infos []model.ExerciseInfo) *Matcher {
+	normalized := make([]string, len(infos))
+	for i, info := range infos {
+		normalized[i] = normalize(info.Name)
+	}
+
+	return &Matcher{
+		infos:      infos,
+		normalized: normalized,
+		matches:    map[string]*Match{},
+		unmatched:  map[string]*Unmatched{},
+	}
+}
+
+// Match returns the ExerciseInfo closest to the provided name, or nil if no
+// name is similar enough. Verified exercises win ties. Rows is the number of
+// rows logged under the name, used for the unmatched report
+func (matcher *Matcher) Match(name string, rows int) *Match {
+	if match, seen := matcher.matches[name]; seen {
+		return match
+	}
+
+	if unmatched, seen := matcher.unmatched[name]; seen {
+		unmatched.Rows += rows
+		return nil
+	}
+
+	target := normalize(name)
+	best := -1
+	bestSimilarity := 0.0
+
+	for i, candidate := range matcher.normalized {
+		score := similarity(target, candidate)
+
+		if score > bestSimilarity || (score == bestSimilarity && best >= 0 && matcher.infos[i].Verified && !matcher.infos[best].Verified) {
+			best = i
+			bestSimilarity = score
+		}
+	}
+
+	if best >= 0 && bestSimilarity >= MatchThreshold {
+		match := &Match{Info: matcher.infos[best], Similarity: bestSimilarity}
+		matcher.matches[name] = match
+
+		return match
+	}
+
+	unmatched := &Unmatched{ExerciseName: name, Rows: rows}
+	if best >= 0 && bestSimilarity >= SuggestionThreshold {
+		unmatched.Suggestion = matcher.infos[best].Name
+		unmatched.Similarity = math.Round(bestSimilarity*100) / 100
+	}
+
+	matcher.unmatched[name] = unmatched
+	matcher.order = append(matcher.order, name)
+
+	return nil
+}
+
+// Unmatched returns every name that could not be matched in the order they
+// were first seen
+func (matcher *Matcher) Unmatched() []Unmatched {
+	report := make([]Unmatched, len(matcher.order))
+	for i, name := range matcher.order {
+		report[i] = *matcher.unmatched[name]
+	}
+
+	return report
+}
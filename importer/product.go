@@ -0,0 +1,418 @@
+package importer
+
+import (
+	"ares/model"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// OPEN_FOOD_FACTS is the source set on foods loaded from a product dump
+const OPEN_FOOD_FACTS = "openfoodfacts"
+
+// FoodUpdate returns the update saving the editable fields of a food. The
+// Food fields are omitempty, so every nutrient is set on its own for a zero
+// to replace the previous value. A food without a barcode has it unset to
+// keep it out of the unique barcode index
+func FoodUpdate(food model.Food) bson.M {
+	set := bson.M{
+		"name":          food.Name,
+		"verified":      food.Verified,
+		"measurement":   food.Measurement,
+		"calories":      food.Calories,
+		"protein":       food.Protein,
+		"carbohydrates": food.Carbohydrates,
+		"fiber":         food.Fiber,
+		"sugar":         food.Sugar,
+		"fat":           food.Fat,
+		"cholesterol":   food.Cholesterol,
+		"sodium":        food.Sodium,
+		"potassium":     food.Potassium,
+		"vitaminA":      food.VitaminA,
+		"vitaminc":      food.VitaminC,
+		"calcium":       food.Calcium,
+		"iron":          food.Iron,
+		"updatedAt":     food.UpdatedAt,
+	}
+
+	update := bson.M{"$set": set}
+
+	if food.Barcode != "" {
+		set["barcode"] = food.Barcode
+	} else {
+		update["$unset"] = bson.M{"barcode": ""}
+	}
+
+	return update
+}
+
+// ProductError describes a product in a dump that could not be converted to
+// a food. Bad products are skipped rather than failing the whole import
+type ProductError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (err *ProductError) Error() string {
+	return fmt.Sprintf("line %d: %s", err.Line, err.Message)
+}
+
+// ProductReader reads foods from a product dump one at a time. Next returns
+// io.EOF once the dump is exhausted and a *ProductError for products that
+// should be skipped
+type ProductReader interface {
+	Next() (model.Food, error)
+}
+
+// NewProductReader returns a reader for an Open Food Facts style dump, picked
+// by the file extension. JSONL dumps hold one product object per line, CSV
+// dumps may be separated by commas or tabs
+func NewProductReader(filename string, r io.Reader) (ProductReader, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jsonl", ".json", ".ndjson":
+		return &jsonProducts{reader: bufio.NewReader(r)}, nil
+	case ".csv", ".tsv":
+		return newCSVProducts(r)
+	}
+
+	return nil, errors.New("unsupported product dump " + filename + ", expected .jsonl or .csv")
+}
+
+// product is the part of an Open Food Facts product used to build a food.
+// Nutriments are keyed like "proteins_100g" and "proteins_serving"
+type product struct {
+	code            string
+	name            string
+	servingSize     string
+	servingQuantity float64
+	nutriments      map[string]float64
+}
+
+type jsonProducts struct {
+	reader *bufio.Reader
+	line   int
+}
+
+// number reads a JSON value that may be a number or a numeric string
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(strings.ReplaceAll(v, ",", ".")), 64)
+		return parsed, err == nil
+	}
+
+	return 0, false
+}
+
+func (products *jsonProducts) Next() (model.Food, error) {
+	for {
+		data, err := products.reader.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(data) == 0) {
+			return model.Food{}, err
+		}
+
+		products.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		var raw struct {
+			Code            string                 `json:"code"`
+			ProductName     string                 `json:"product_name"`
+			ServingSize     string                 `json:"serving_size"`
+			ServingQuantity interface{}            `json:"serving_quantity"`
+			Nutriments      map[string]interface{} `json:"nutriments"`
+		}
+
+		err = json.Unmarshal(data, &raw)
+		if err != nil {
+			return model.Food{}, &ProductError{Line: products.line, Message: "invalid product json: " + err.Error()}
+		}
+
+		p := product{
+			code:        raw.Code,
+			name:        raw.ProductName,
+			servingSize: raw.ServingSize,
+			nutriments:  map[string]float64{},
+		}
+
+		p.servingQuantity, _ = number(raw.ServingQuantity)
+
+		for key, value := range raw.Nutriments {
+			if n, ok := number(value); ok {
+				p.nutriments[key] = n
+			}
+		}
+
+		return toFood(p, products.line)
+	}
+}
+
+type csvProducts struct {
+	reader *csv.Reader
+	column column
+	header []string
+}
+
+func newCSVProducts(r io.Reader) (*csvProducts, error) {
+	buffered := bufio.NewReader(r)
+
+	// Open Food Facts publishes its CSV export separated by tabs
+	firstLine, err := buffered.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	if end := bytes.IndexByte(firstLine, '\n'); end >= 0 {
+		firstLine = firstLine[:end]
+	}
+
+	if bytes.Count(firstLine, []byte{'\t'}) > bytes.Count(firstLine, []byte{','}) {
+		reader.Comma = '\t'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("failed to read header: " + err.Error())
+	}
+
+	for i, name := range header {
+		header[i] = normalizeHeader(name)
+	}
+
+	return &csvProducts{reader: reader, column: indexColumns(header), header: header}, nil
+}
+
+func (products *csvProducts) Next() (model.Food, error) {
+	record, err := products.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return model.Food{}, &ProductError{Line: parseErr.Line, Message: parseErr.Err.Error()}
+		}
+
+		return model.Food{}, err
+	}
+
+	line, _ := products.reader.FieldPos(0)
+
+	p := product{
+		code:        products.column(record, "code"),
+		name:        products.column(record, "product_name"),
+		servingSize: products.column(record, "serving_size"),
+		nutriments:  map[string]float64{},
+	}
+
+	p.servingQuantity, _ = number(products.column(record, "serving_quantity"))
+
+	for i, name := range products.header {
+		if i >= len(record) || (!strings.HasSuffix(name, "_100g") && !strings.HasSuffix(name, "_serving")) {
+			continue
+		}
+
+		if n, ok := number(record[i]); ok {
+			p.nutriments[name] = n
+		}
+	}
+
+	return toFood(p, line)
+}
+
+var barcodeSpacing = regexp.MustCompile(`[\s-]`)
+
+// NormalizeBarcode strips spacing from a barcode and returns it as EAN-13
+// when it is a 12 digit UPC-A code, so both forms match the same food
+func NormalizeBarcode(barcode string) (string, error) {
+	barcode = barcodeSpacing.ReplaceAllString(barcode, "")
+
+	if len(barcode) < 8 || len(barcode) > 14 {
+		return "", errors.New("barcode must be between 8 and 14 digits")
+	}
+
+	for _, r := range barcode {
+		if r < '0' || r > '9' {
+			return "", errors.New("barcode must only contain digits")
+		}
+	}
+
+	if len(barcode) == 12 {
+		barcode = "0" + barcode
+	}
+
+	return barcode, nil
+}
+
+var servingPattern = regexp.MustCompile(`^\s*([0-9]+(?:[.,][0-9]+)?)\s*([a-zA-Z]*)`)
+
+// servingGramsPattern finds the weight of a serving described in other units,
+// e.g. the 40 in "1 bar (40 g)"
+var servingGramsPattern = regexp.MustCompile(`([0-9]+(?:[.,][0-9]+)?)\s*g\b`)
+
+// servingUnits maps the unit of a serving size on to a food measurement
+var servingUnits = map[string]model.FoodMeasurement{
+	"g":      model.GRAM,
+	"gr":     model.GRAM,
+	"gram":   model.GRAM,
+	"grams":  model.GRAM,
+	"kg":     model.KILOGRAM,
+	"oz":     model.OUNCE,
+	"ounce":  model.OUNCE,
+	"ounces": model.OUNCE,
+	"lb":     model.POUND,
+	"lbs":    model.POUND,
+	"pound":  model.POUND,
+	"cup":    model.CUP,
+	"cups":   model.CUP,
+}
+
+// serving parses a serving size like "30 g" or "1 bar (40 g)" in to a
+// measurement. Sizes that aren't whole numbers can't be stored and are
+// rejected
+func serving(servingSize string) (model.FoodMeasureable, bool) {
+	match := servingPattern.FindStringSubmatch(servingSize)
+	if match == nil {
+		return model.FoodMeasureable{}, false
+	}
+
+	size, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", "."), 64)
+	if err != nil || size < 1 || size > math.MaxUint16 || math.Abs(size-math.Round(size)) > 0.01 {
+		return model.FoodMeasureable{}, false
+	}
+
+	measurement, known := servingUnits[strings.ToLower(match[2])]
+	if !known {
+		measurement = model.SERVING
+	}
+
+	return model.FoodMeasureable{Measurement: measurement, Size: uint16(math.Round(size))}, true
+}
+
+// nutrients maps Open Food Facts nutriments, which are grams unless noted, on
+// to food fields along with the factor converting them to the field unit
+var nutrients = []struct {
+	key    string
+	factor float64
+	field  func(food *model.Food) *uint16
+}{
+	{"proteins", 1, func(food *model.Food) *uint16 { return &food.Protein }},
+	{"carbohydrates", 1, func(food *model.Food) *uint16 { return &food.Carbohydrates }},
+	{"fiber", 1, func(food *model.Food) *uint16 { return &food.Fiber }},
+	{"sugars", 1, func(food *model.Food) *uint16 { return &food.Sugar }},
+	{"fat", 1, func(food *model.Food) *uint16 { return &food.Fat }},
+	{"cholesterol", 1000, func(food *model.Food) *uint16 { return &food.Cholesterol }},
+	{"sodium", 1000, func(food *model.Food) *uint16 { return &food.Sodium }},
+	{"potassium", 1000, func(food *model.Food) *uint16 { return &food.Potassium }},
+	{"vitamin-a", 1000000, func(food *model.Food) *uint16 { return &food.VitaminA }},
+	{"vitamin-c", 1000, func(food *model.Food) *uint16 { return &food.VitaminC }},
+	{"calcium", 1000, func(food *model.Food) *uint16 { return &food.Calcium }},
+	{"iron", 1000, func(food *model.Food) *uint16 { return &food.Iron }},
+}
+
+// toUint16 rounds a nutrient amount, clamping it to the range of the field
+func toUint16(value float64) uint16 {
+	value = math.Round(value)
+	if value <= 0 || math.IsNaN(value) {
+		return 0
+	}
+
+	if value > math.MaxUint16 {
+		return math.MaxUint16
+	}
+
+	return uint16(value)
+}
+
+// toFood converts a product in to a verified food. Nutrients are stored per
+// serving when the serving size can be represented, per 100 grams otherwise
+func toFood(p product, line int) (model.Food, error) {
+	barcode, err := NormalizeBarcode(p.code)
+	if err != nil {
+		return model.Food{}, &ProductError{Line: line, Message: err.Error()}
+	}
+
+	name := strings.TrimSpace(p.name)
+	if name == "" {
+		return model.Food{}, &ProductError{Line: line, Message: "product " + barcode + " has no name"}
+	}
+
+	measurement, perServing := serving(p.servingSize)
+
+	// grams in one serving, used to scale values only given per 100g
+	quantity := p.servingQuantity
+	if quantity <= 0 {
+		if match := servingGramsPattern.FindStringSubmatch(p.servingSize); match != nil {
+			quantity, _ = strconv.ParseFloat(strings.ReplaceAll(match[1], ",", "."), 64)
+		}
+	}
+
+	amount := func(key string) (float64, bool) {
+		if perServing {
+			if value, ok := p.nutriments[key+"_serving"]; ok {
+				return value, true
+			}
+
+			if value, ok := p.nutriments[key+"_100g"]; ok && quantity > 0 {
+				return value * quantity / 100, true
+			}
+
+			return 0, false
+		}
+
+		value, ok := p.nutriments[key+"_100g"]
+		return value, ok
+	}
+
+	// without a usable serving fall back to 100g so the per 100g values apply
+	if perServing {
+		_, hasEnergy := amount("energy-kcal")
+		_, hasProtein := amount("proteins")
+		if !hasEnergy && !hasProtein {
+			perServing = false
+		}
+	}
+
+	if !perServing {
+		measurement = model.FoodMeasureable{Measurement: model.GRAM, Size: 100}
+	}
+
+	food := model.Food{
+		Name:        name,
+		Barcode:     barcode,
+		Source:      OPEN_FOOD_FACTS,
+		Verified:    true,
+		Measurement: measurement,
+	}
+
+	if calories, ok := amount("energy-kcal"); ok {
+		food.Calories = toUint16(calories)
+	} else if kilojoules, ok := amount("energy"); ok {
+		food.Calories = toUint16(kilojoules / 4.184)
+	}
+
+	for _, nutrient := range nutrients {
+		if value, ok := amount(nutrient.key); ok {
+			*nutrient.field(&food) = toUint16(value * nutrient.factor)
+		}
+	}
+
+	return food, nil
+}
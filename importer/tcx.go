@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+)
+
+type tcxFile struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		Laps  []struct {
+			Points []struct {
+				Time     time.Time `xml:"Time"`
+				Position *struct {
+					Latitude  float64 `xml:"LatitudeDegrees"`
+					Longitude float64 `xml:"LongitudeDegrees"`
+				} `xml:"Position"`
+				Altitude  float64 `xml:"AltitudeMeters"`
+				Distance  float64 `xml:"DistanceMeters"`
+				HeartRate uint8   `xml:"HeartRateBpm>Value"`
+			} `xml:"Track>Trackpoint"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+// ParseTCX parses the first activity of a Training Center file
+func ParseTCX(r io.Reader) (Activity, error) {
+	var file tcxFile
+	activity := Activity{Format: TCX}
+
+	err := xml.NewDecoder(r).Decode(&file)
+	if err != nil {
+		return activity, errors.New("failed to decode tcx: " + err.Error())
+	}
+
+	if len(file.Activities) == 0 {
+		return activity, errors.New("tcx file has no activities")
+	}
+
+	activity.Sport = file.Activities[0].Sport
+
+	for _, lap := range file.Activities[0].Laps {
+		for _, point := range lap.Points {
+			parsed := Point{
+				Time:      point.Time,
+				Elevation: point.Altitude,
+				HeartRate: point.HeartRate,
+				Distance:  point.Distance,
+			}
+
+			if point.Position != nil {
+				parsed.Latitude = point.Position.Latitude
+				parsed.Longitude = point.Position.Longitude
+				parsed.HasPosition = true
+			}
+
+			activity.Points = append(activity.Points, parsed)
+		}
+	}
+
+	if len(activity.Points) == 0 {
+		return activity, errors.New("tcx file has no track points")
+	}
+
+	return activity, nil
+}
@@ -3,12 +3,62 @@ package main
 import (
 	"ares/config"
 	"ares/database"
+	"ares/migration"
+	"ares/notification"
+	"ares/push"
+	"ares/realtime"
 	"ares/routing"
 	"ares/util"
+	"ares/worker"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"os"
+	"time"
 )
 
+// pushProviders builds the push providers from the configuration, skipping
+// providers without credentials
+func pushProviders(conf config.Push) (map[string]push.Provider, error) {
+	if conf.Fake {
+		return map[string]push.Provider{
+			push.APNS: push.NewFake(push.APNS, false, true),
+			push.FCM:  push.NewFake(push.FCM, false, true),
+		}, nil
+	}
+
+	providers := map[string]push.Provider{}
+
+	if conf.APNs.KeyFile != "" {
+		key, err := os.ReadFile(conf.APNs.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		apns, err := push.NewAPNs(conf.APNs.KeyID, conf.APNs.TeamID, conf.APNs.Topic, key, conf.APNs.Production)
+		if err != nil {
+			return nil, err
+		}
+
+		providers[push.APNS] = apns
+	}
+
+	if conf.FCM.CredentialsFile != "" {
+		credentials, err := os.ReadFile(conf.FCM.CredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		fcm, err := push.NewFCM(credentials)
+		if err != nil {
+			return nil, err
+		}
+
+		providers[push.FCM] = fcm
+	}
+
+	return providers, nil
+}
+
 func main() {
 	conf := config.Get()
 	mongoClient, err := database.GetMongoClient(conf.Mongo.URI)
@@ -78,6 +128,74 @@ func main() {
 
 	util.ConfigureAdminAccount(mongoClient, "prod", "account")
 
+	err = migration.Run(mongoClient, "prod")
+	if err != nil {
+		panic("failed to apply database migrations: " + err.Error())
+	}
+
+	if conf.Worker.Enabled {
+		purgeInterval := time.Duration(conf.Worker.PurgeInterval) * time.Minute
+		if purgeInterval <= 0 {
+			purgeInterval = time.Hour
+		}
+
+		worker.Start(worker.Purger{
+			MongoClient:  mongoClient,
+			DatabaseName: "prod",
+			S3:           s3Client,
+			Bucket:       conf.S3.Bucket,
+		}.Task(purgeInterval))
+
+		counterInterval := time.Duration(conf.Worker.CounterInterval) * time.Minute
+		if counterInterval <= 0 {
+			counterInterval = 6 * time.Hour
+		}
+
+		worker.Start(worker.CounterReconciler{
+			MongoClient:  mongoClient,
+			DatabaseName: "prod",
+		}.Task(counterInterval))
+	}
+
+	realtime.Configure(redisClient)
+	notification.RegisterDispatcher("realtime", realtime.NotificationDispatcher)
+
+	if conf.Push.Enabled {
+		queue := push.Queue{RedisClient: redisClient}
+		notification.RegisterDispatcher("push", push.Dispatcher(queue, mongoClient, "prod"))
+
+		if conf.Worker.Enabled {
+			providers, err := pushProviders(conf.Push)
+			if err != nil {
+				panic("failed to configure push providers: " + err.Error())
+			}
+
+			pushInterval := time.Duration(conf.Push.Interval) * time.Second
+			if pushInterval <= 0 {
+				pushInterval = 5 * time.Second
+			}
+
+			batchSize := conf.Push.BatchSize
+			if batchSize <= 0 {
+				batchSize = 100
+			}
+
+			maxAttempts := conf.Push.MaxAttempts
+			if maxAttempts <= 0 {
+				maxAttempts = 5
+			}
+
+			worker.Start(worker.PushDelivery{
+				Queue:        queue,
+				Providers:    providers,
+				MongoClient:  mongoClient,
+				DatabaseName: "prod",
+				BatchSize:    batchSize,
+				MaxAttempts:  maxAttempts,
+			}.Task(pushInterval))
+		}
+	}
+
 	routing.ApplyRoutes(router, mongoClient, s3Client, redisClient)
 
 	err = router.Run(":" + conf.Gin.Port)
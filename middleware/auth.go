@@ -2,11 +2,15 @@ package middleware
 
 import (
 	"ares/config"
+	"ares/realtime"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // ValidateToken validates the provided encoded token against the
@@ -21,12 +25,51 @@ func ValidateToken(encodedToken string, publicKey string) (*jwt.Token, error) {
 	})
 }
 
+// ParseAccessToken validates an encoded access token and returns the id of
+// the account it was issued to along with its expiry
+func ParseAccessToken(tokenString string) (string, time.Time, error) {
+	conf := config.Get()
+	accessTokenPublicKey := conf.Auth.AccessTokenPublicKey
+
+	var err error
+
+	// if the request is sent with a prefixed double-quote we need
+	// to unquote the token before attempting to verify it
+	if strings.HasPrefix(tokenString, `"`) {
+		tokenString, err = strconv.Unquote(tokenString)
+	}
+
+	if err != nil {
+		return "", time.Time{}, errors.New("failed to unquote token")
+	}
+
+	token, err := ValidateToken(tokenString, accessTokenPublicKey)
+	if err != nil {
+		return "", time.Time{}, errors.New("token invalid: " + err.Error())
+	}
+
+	if !token.Valid {
+		return "", time.Time{}, errors.New("token invalid")
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	id, ok := claims["accountId"].(string)
+	if !ok {
+		return "", time.Time{}, errors.New("token invalid: missing account id")
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return "", time.Time{}, errors.New("token invalid: missing expiry")
+	}
+
+	return id, time.Unix(int64(exp), 0), nil
+}
+
 func ValidateRequest() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		const BearerSchema = "Bearer "
 
-		conf := config.Get()
-		accessTokenPublicKey := conf.Auth.AccessTokenPublicKey
 		authHeader := ctx.GetHeader("Authorization")
 
 		if len(authHeader) < 7 {
@@ -34,31 +77,61 @@ func ValidateRequest() gin.HandlerFunc {
 			return
 		}
 
-		var tokenString string
-		var err error
-		tokenString = authHeader[len(BearerSchema):]
-
-		// if the request is sent with a prefixed double-quote we need
-		// to unquote the token before attempting to verify it
-		if string(tokenString[0]) == `"` {
-			tokenString, err = strconv.Unquote(tokenString)
+		id, expiresAt, err := ParseAccessToken(authHeader[len(BearerSchema):])
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+			return
 		}
 
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "failed to unquote token"})
+		ctx.Set("accountId", id)
+		ctx.Set("tokenExpiresAt", expiresAt)
+		ctx.Next()
+	}
+}
+
+// ValidateStreamRequest validates requests opening an event stream. Browsers
+// can't set headers on WebSocket and EventSource requests, so instead of the
+// access token they send a single use ticket in the ticket query string. See
+// realtime.IssueTicket
+func ValidateStreamRequest() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		const BearerSchema = "Bearer "
+
+		if authHeader := ctx.GetHeader("Authorization"); len(authHeader) >= 7 {
+			id, expiresAt, err := ParseAccessToken(authHeader[len(BearerSchema):])
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+				return
+			}
+
+			ctx.Set("accountId", id)
+			ctx.Set("tokenExpiresAt", expiresAt)
+			ctx.Next()
 			return
 		}
 
-		token, err := ValidateToken(tokenString, accessTokenPublicKey)
-		if err != nil || !token.Valid {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "token invalid: " + err.Error()})
+		ticket := ctx.Query("ticket")
+		if ticket == "" {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "missing stream ticket"})
 			return
 		}
 
-		claims := token.Claims.(jwt.MapClaims)
-		id := claims["accountId"].(string)
+		id, expiresAt, err := realtime.RedeemTicket(ticket)
+		if err != nil {
+			switch err {
+			case realtime.ErrInvalidTicket:
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+			case realtime.ErrNotConfigured:
+				ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"message": err.Error()})
+			default:
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "failed to redeem stream ticket: " + err.Error()})
+			}
+
+			return
+		}
 
 		ctx.Set("accountId", id)
+		ctx.Set("tokenExpiresAt", expiresAt)
 		ctx.Next()
 	}
 }
@@ -0,0 +1,61 @@
+package migration
+
+import (
+	"ares/model"
+	"ares/units"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// canonicalizeCollection stores the SI weight and distance of every set in
+// the exercises array found at the provided path
+func canonicalizeCollection(ctx context.Context, collection *mongo.Collection, path string) error {
+	cursor, err := collection.Find(ctx, bson.M{path: bson.M{"$exists": true}})
+	if err != nil {
+		return err
+	}
+
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var document struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			Exercises []model.Exercise   `bson:"exercises"`
+			Session   model.Session      `bson:"session"`
+		}
+
+		err = cursor.Decode(&document)
+		if err != nil {
+			return err
+		}
+
+		session := document.Session
+		if path == "exercises" {
+			session = model.Session{Exercises: document.Exercises}
+		}
+
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": document.ID}, bson.M{
+			"$set": bson.M{path: units.CanonicalizeSession(session).Exercises},
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// canonicalizeUnits backfills the SI weight and distance of sets logged
+// before they were stored on write, for both live and deleted sessions
+func canonicalizeUnits(ctx context.Context, db *mongo.Database) error {
+	err := canonicalizeCollection(ctx, db.Collection("exercise_sessions"), "exercises")
+	if err != nil {
+		return err
+	}
+
+	return canonicalizeCollection(ctx, db.Collection("exercise_sessions_deleted"), "session.exercises")
+}
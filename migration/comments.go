@@ -0,0 +1,68 @@
+package migration
+
+import (
+	"ares/model"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// threadComments sets the root post and depth of comments saved before
+// threaded replies. Replies are resolved a level at a time, as their depth is
+// only known once their parent has been threaded
+func threadComments(ctx context.Context, db *mongo.Database) error {
+	comments := db.Collection("comment")
+
+	_, err := comments.UpdateMany(ctx, bson.M{"type": model.POST, "root": bson.M{"$exists": false}}, bson.A{
+		bson.M{"$set": bson.M{"root": "$post", "depth": 0}},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for depth := 1; depth < model.MaxCommentDepth*4; depth++ {
+		cursor, err := comments.Find(ctx, bson.M{"type": model.COMMENT, "root": bson.M{"$exists": false}})
+		if err != nil {
+			return err
+		}
+
+		var replies []model.Comment
+		err = cursor.All(ctx, &replies)
+		if err != nil || len(replies) == 0 {
+			return err
+		}
+
+		threaded := 0
+
+		for _, reply := range replies {
+			var parent model.Comment
+			err = comments.FindOne(ctx, bson.M{"_id": reply.Post, "root": bson.M{"$exists": true}}).Decode(&parent)
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			_, err = comments.UpdateOne(ctx, bson.M{"_id": reply.ID}, bson.M{
+				"$set": bson.M{"root": parent.Root, "depth": parent.Depth + 1},
+			})
+
+			if err != nil {
+				return err
+			}
+
+			threaded++
+		}
+
+		// the remaining replies belong to deleted comments
+		if threaded == 0 {
+			return nil
+		}
+	}
+
+	return nil
+}
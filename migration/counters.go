@@ -0,0 +1,18 @@
+package migration
+
+import (
+	"ares/worker"
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// backfillCounters sets the like, comment and reply counters of existing
+// posts and comments, counts are served from them whether or not the
+// reconciliation task is enabled
+func backfillCounters(ctx context.Context, db *mongo.Database) error {
+	return worker.CounterReconciler{
+		MongoClient:  db.Client(),
+		DatabaseName: db.Name(),
+	}.Reconcile(ctx)
+}
@@ -0,0 +1,128 @@
+package migration
+
+import (
+	"ares/model"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// legacyExercise is the exercise shape used before exercises were split in
+// to sets, where every exercise held a single 'values' document
+type legacyExercise struct {
+	ExerciseName       string                     `bson:"exerciseName,omitempty"`
+	AddedAt            time.Time                  `bson:"addedAt,omitempty"`
+	Values             *model.ExerciseSet         `bson:"values,omitempty"`
+	Sets               []model.ExerciseSet        `bson:"sets,omitempty"`
+	Type               model.ExerciseType         `bson:"type,omitempty"`
+	AdditionalExercise []legacyAdditionalExercise `bson:"additionalExercises,omitempty"`
+}
+
+type legacyAdditionalExercise struct {
+	ExerciseName string                       `bson:"exerciseName,omitempty"`
+	AddedAt      time.Time                    `bson:"addedAt,omitempty"`
+	Values       *model.ExerciseSet           `bson:"values,omitempty"`
+	Sets         []model.ExerciseSet          `bson:"sets,omitempty"`
+	Type         model.AdditionalExerciseType `bson:"type,omitempty"`
+}
+
+// legacySets converts a legacy 'values' document in to a single completed
+// set, keeping any sets that were already present
+func legacySets(values *model.ExerciseSet, sets []model.ExerciseSet, addedAt time.Time) []model.ExerciseSet {
+	if values == nil {
+		return sets
+	}
+
+	set := *values
+	set.Completed = true
+	set.CompletedAt = addedAt
+
+	return append([]model.ExerciseSet{set}, sets...)
+}
+
+// convertLegacyExercises converts legacy exercises to the multi-set shape
+func convertLegacyExercises(legacy []legacyExercise) []model.Exercise {
+	exercises := make([]model.Exercise, 0, len(legacy))
+
+	for _, exercise := range legacy {
+		var additionalExercises []model.AdditionalExercise
+		for _, additional := range exercise.AdditionalExercise {
+			additionalExercises = append(additionalExercises, model.AdditionalExercise{
+				ExerciseName: additional.ExerciseName,
+				AddedAt:      additional.AddedAt,
+				Sets:         legacySets(additional.Values, additional.Sets, additional.AddedAt),
+				Type:         additional.Type,
+			})
+		}
+
+		exercises = append(exercises, model.Exercise{
+			ExerciseName:       exercise.ExerciseName,
+			AddedAt:            exercise.AddedAt,
+			Sets:               legacySets(exercise.Values, exercise.Sets, exercise.AddedAt),
+			Type:               exercise.Type,
+			AdditionalExercise: additionalExercises,
+		})
+	}
+
+	return exercises
+}
+
+// convertCollection rewrites the exercises array found at the provided path
+// for every document in the collection still holding legacy values
+func convertCollection(ctx context.Context, collection *mongo.Collection, path string) error {
+	filter := bson.M{"$or": bson.A{
+		bson.M{path + ".values": bson.M{"$exists": true}},
+		bson.M{path + ".additionalExercises.values": bson.M{"$exists": true}},
+	}}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var document struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			Exercises []legacyExercise   `bson:"exercises"`
+			Session   struct {
+				Exercises []legacyExercise `bson:"exercises"`
+			} `bson:"session"`
+		}
+
+		err = cursor.Decode(&document)
+		if err != nil {
+			return err
+		}
+
+		legacy := document.Exercises
+		if path != "exercises" {
+			legacy = document.Session.Exercises
+		}
+
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": document.ID}, bson.M{
+			"$set": bson.M{path: convertLegacyExercises(legacy)},
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// convertExerciseSets moves the single 'values' document of every exercise
+// in to a list of sets, for both live and deleted sessions
+func convertExerciseSets(ctx context.Context, db *mongo.Database) error {
+	err := convertCollection(ctx, db.Collection("exercise_sessions"), "exercises")
+	if err != nil {
+		return err
+	}
+
+	return convertCollection(ctx, db.Collection("exercise_sessions_deleted"), "session.exercises")
+}
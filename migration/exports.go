@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"ares/model"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// uniquePendingExports allows a single pending export per account. Exports
+// run in the process that accepted them, so every job still pending when the
+// migration runs was interrupted and is failed before the index is built
+func uniquePendingExports(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("export_job").UpdateMany(ctx, bson.M{"status": model.EXPORT_PENDING}, bson.M{"$set": bson.M{
+		"status":      model.EXPORT_FAILED,
+		"error":       "export was interrupted",
+		"completedAt": time.Now(),
+	}})
+
+	if err != nil {
+		return err
+	}
+
+	return createIndex("export_job", bson.D{
+		{Key: "account", Value: 1},
+	}, options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": model.EXPORT_PENDING}))(ctx, db)
+}
@@ -0,0 +1,22 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// createIndex returns a migration function creating a single index on the
+// provided collection. Creating an index that already exists is a no-op
+func createIndex(collectionName string, keys bson.D, opts *options.IndexOptions) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collectionName).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    keys,
+			Options: opts,
+		})
+
+		return err
+	}
+}
@@ -0,0 +1,62 @@
+package migration
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// dedupeLikes keeps the earliest like of every account on an item and removes
+// the rest, so a unique index can be built over the likes
+func dedupeLikes(ctx context.Context, db *mongo.Database) error {
+	likes := db.Collection("like")
+
+	cursor, err := likes.Aggregate(ctx, bson.A{
+		bson.M{"$sort": bson.M{"likedAt": 1}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"post": "$post", "type": "$type", "author": "$author"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}},
+		bson.M{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	var duplicates []struct {
+		IDs bson.A `bson:"ids"`
+	}
+
+	err = cursor.All(ctx, &duplicates)
+	if err != nil {
+		return err
+	}
+
+	for _, duplicate := range duplicates {
+		_, err = likes.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": duplicate.IDs[1:]}})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// uniqueLikes removes duplicate likes before making them impossible with a
+// unique index over the liked item and the account
+func uniqueLikes(ctx context.Context, db *mongo.Database) error {
+	err := dedupeLikes(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	return createIndex("like", bson.D{
+		{Key: "post", Value: 1},
+		{Key: "type", Value: 1},
+		{Key: "author", Value: 1},
+	}, options.Index().SetUnique(true))(ctx, db)
+}
@@ -0,0 +1,161 @@
+package migration
+
+import (
+	"ares/model"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migration is a single, named change to existing documents in the database.
+//
+// Migrations are applied in the order they are registered and are recorded
+// once they succeed. Up functions should be safe to run more than once in
+// case an instance stops before the record is saved
+type Migration struct {
+	Name string
+	Up   func(ctx context.Context, db *mongo.Database) error
+}
+
+// Record is stored in the migration collection for every applied migration
+type Record struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	AppliedAt time.Time          `json:"appliedAt" bson:"appliedAt"`
+}
+
+var migrations = []Migration{
+	{Name: "0001_exercise_sets", Up: convertExerciseSets},
+	{Name: "0002_personal_record_index", Up: createIndex("personal_record", bson.D{
+		{Key: "account", Value: 1},
+		{Key: "exerciseName", Value: 1},
+		{Key: "type", Value: 1},
+		{Key: "bracket", Value: 1},
+	}, options.Index().SetUnique(true))},
+	{Name: "0003_canonical_units", Up: canonicalizeUnits},
+	{Name: "0004_active_enrollment_index", Up: createIndex("program_enrollment", bson.D{
+		{Key: "account", Value: 1},
+		{Key: "program", Value: 1},
+	}, options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"status": model.ENROLLMENT_ACTIVE}))},
+	{Name: "0005_coach_link_index", Up: createIndex("coach_link", bson.D{
+		{Key: "coach", Value: 1},
+		{Key: "athlete", Value: 1},
+	}, options.Index().SetUnique(true))},
+	{Name: "0006_session_import_key_index", Up: createIndex("exercise_sessions", bson.D{
+		{Key: "author", Value: 1},
+		{Key: "importKey", Value: 1},
+	}, options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"importKey": bson.M{"$exists": true}}))},
+	{Name: "0007_route_session_index", Up: createIndex("route", bson.D{
+		{Key: "session", Value: 1},
+	}, options.Index().SetUnique(true))},
+	{Name: "0008_route_path_index", Up: createIndex("route", bson.D{
+		{Key: "path", Value: "2dsphere"},
+	}, nil)},
+	{Name: "0009_diet_entry_account_index", Up: createIndex("diet_entry", bson.D{
+		{Key: "account", Value: 1},
+		{Key: "addedAt", Value: 1},
+	}, nil)},
+	{Name: "0010_food_name_index", Up: createIndex("food", bson.D{
+		{Key: "verified", Value: -1},
+		{Key: "name", Value: 1},
+	}, nil)},
+	{Name: "0011_nutrition_target_account_index", Up: createIndex("nutrition_target", bson.D{
+		{Key: "account", Value: 1},
+	}, options.Index().SetUnique(true))},
+	{Name: "0012_food_barcode_index", Up: createIndex("food", bson.D{
+		{Key: "barcode", Value: 1},
+	}, options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"barcode": bson.M{"$exists": true}}))},
+	{Name: "0013_recipe_author_index", Up: createIndex("recipe", bson.D{
+		{Key: "author", Value: 1},
+		{Key: "name", Value: 1},
+	}, nil)},
+	{Name: "0014_meal_plan_account_index", Up: createIndex("meal_plan", bson.D{
+		{Key: "account", Value: 1},
+		{Key: "startDate", Value: -1},
+	}, nil)},
+	{Name: "0015_body_measurement_account_index", Up: createIndex("body_measurement", bson.D{
+		{Key: "account", Value: 1},
+		{Key: "measuredAt", Value: -1},
+	}, nil)},
+	{Name: "0016_notification_recipient_index", Up: createIndex("notification", bson.D{
+		{Key: "recipient", Value: 1},
+		{Key: "_id", Value: -1},
+	}, nil)},
+	{Name: "0017_enable_notifications", Up: enableNotifications},
+	{Name: "0018_device_token_unique_index", Up: createIndex("device_token", bson.D{
+		{Key: "token", Value: 1},
+	}, options.Index().SetUnique(true))},
+	{Name: "0019_device_token_account_index", Up: createIndex("device_token", bson.D{
+		{Key: "account", Value: 1},
+	}, nil)},
+	{Name: "0020_conversation_participants_index", Up: createIndex("conversation", bson.D{
+		{Key: "participants", Value: 1},
+		{Key: "updatedAt", Value: -1},
+	}, nil)},
+	{Name: "0021_message_conversation_index", Up: createIndex("message", bson.D{
+		{Key: "conversation", Value: 1},
+		{Key: "_id", Value: -1},
+	}, nil)},
+	{Name: "0022_block_unique_index", Up: createIndex("block", bson.D{
+		{Key: "blocker", Value: 1},
+		{Key: "blocked", Value: 1},
+	}, options.Index().SetUnique(true))},
+	{Name: "0023_thread_comments", Up: threadComments},
+	{Name: "0024_comment_thread_index", Up: createIndex("comment", bson.D{
+		{Key: "post", Value: 1},
+		{Key: "type", Value: 1},
+		{Key: "createdAt", Value: 1},
+	}, nil)},
+	{Name: "0025_like_unique_index", Up: uniqueLikes},
+	{Name: "0026_backfill_counters", Up: backfillCounters},
+	{Name: "0027_session_program_day_index", Up: createIndex("exercise_sessions", bson.D{
+		{Key: "enrollment", Value: 1},
+		{Key: "programWeek", Value: 1},
+		{Key: "programDay", Value: 1},
+	}, options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"programWeek": bson.M{"$exists": true}}))},
+	{Name: "0028_pending_export_unique_index", Up: uniquePendingExports},
+}
+
+// Run applies every registered migration that has not been recorded
+// in the provided database yet
+func Run(mongoClient *mongo.Client, databaseName string) error {
+	db := mongoClient.Database(databaseName)
+	collection := db.Collection("migration")
+
+	for _, migration := range migrations {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+
+		err := collection.FindOne(ctx, bson.M{"name": migration.Name}).Err()
+		if err == nil {
+			cancel()
+			continue
+		}
+
+		if err != mongo.ErrNoDocuments {
+			cancel()
+			return fmt.Errorf("failed to look up migration %s: %w", migration.Name, err)
+		}
+
+		err = migration.Up(ctx, db)
+		if err != nil {
+			cancel()
+			return fmt.Errorf("failed to apply migration %s: %w", migration.Name, err)
+		}
+
+		_, err = collection.InsertOne(ctx, Record{Name: migration.Name, AppliedAt: time.Now()})
+		cancel()
+
+		if err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", migration.Name, err)
+		}
+
+		fmt.Println("applied database migration: " + migration.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package migration
+
+import (
+	"ares/audit"
+	"ares/model"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// enableNotifications turns on every notification for accounts that have none
+// turned on and never saved their preferences. Nothing generated notifications
+// before the inbox existed, so these accounts never chose to turn them off.
+// The preferences document is stored on every write of an account, so saving
+// preferences is only told apart by the audit entry it leaves, accounts with
+// one are left alone in case every notification was turned off on purpose
+func enableNotifications(ctx context.Context, db *mongo.Database) error {
+	const path = "preferences.notificationPreferences."
+
+	updated, err := db.Collection("audit").Distinct(ctx, "initiator", bson.M{"eventName": audit.UPDATE_ACCOUNT})
+	if err != nil {
+		return err
+	}
+
+	if updated == nil {
+		updated = bson.A{}
+	}
+
+	toggles := []string{
+		"notifyNewFollower",
+		"notifyNewLike",
+		"notifyNewComment",
+		"notifyNewMessage",
+		"notifyNewAssignedSession",
+		"notifyNewAssignedMeal",
+	}
+
+	filter := bson.M{"_id": bson.M{"$nin": updated}}
+	for _, toggle := range toggles {
+		filter[path+toggle] = bson.M{"$ne": true}
+	}
+
+	_, err = db.Collection("account").UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{"preferences.notificationPreferences": model.AllNotifications()},
+	})
+
+	return err
+}
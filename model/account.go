@@ -27,6 +27,8 @@ type Profile struct {
 	Bio      string `json:"bio,omitempty" bson:"bio,omitempty"`
 }
 
+// Biometrics of an account. Weight and height are in the measurement system
+// the account prefers, kilograms and centimeters or pounds and inches
 type Biometrics struct {
 	Birthday time.Time `json:"birthday,omitempty" bson:"birthday,omitempty"`
 	Sex      string    `json:"sex,omitempty" bson:"sex,omitempty"`
@@ -34,14 +36,30 @@ type Biometrics struct {
 	Height   float32   `json:"height,omitempty" bson:"height,omitempty"`
 }
 
+// NotificationPreferences toggle the notifications an account receives in its
+// inbox. New accounts start with every notification turned on
 type NotificationPreferences struct {
 	NotifyNewFollower        bool `json:"notifyNewFollower,omitempty" bson:"notifyNewFollower,omitempty"`
 	NotifyNewLike            bool `json:"notifyNewLike,omitempty" bson:"notifyNewLike,omitempty"`
+	NotifyNewComment         bool `json:"notifyNewComment,omitempty" bson:"notifyNewComment,omitempty"`
 	NotifyNewMessage         bool `json:"notifyNewMessage,omitempty" bson:"notifyNewMessage,omitempty"`
-	NotifyNewAssignedSession bool `json:"notifyNewAssignedSession,omitempty" bson:"notifyNewAssignedSession"`
+	NotifyNewAssignedSession bool `json:"notifyNewAssignedSession,omitempty" bson:"notifyNewAssignedSession,omitempty"`
 	NotifyNewAssignedMeal    bool `json:"notifyNewAssignedMeal,omitempty" bson:"notifyNewAssignedMeal,omitempty"`
 }
 
+// AllNotifications returns notification preferences with every notification
+// turned on
+func AllNotifications() NotificationPreferences {
+	return NotificationPreferences{
+		NotifyNewFollower:        true,
+		NotifyNewLike:            true,
+		NotifyNewComment:         true,
+		NotifyNewMessage:         true,
+		NotifyNewAssignedSession: true,
+		NotifyNewAssignedMeal:    true,
+	}
+}
+
 type PrivacyPreferences struct {
 	ProfilePrivacy PrivacyLevel `json:"profilePrivacy,omitempty" bson:"profilePrivacy,omitempty"`
 	MessagePrivacy PrivacyLevel `json:"messagePrivacy,omitempty" bson:"messagePrivacy,omitempty"`
@@ -52,10 +70,15 @@ type AccountPreferences struct {
 	FollowRequestEnabled bool `json:"followRequestEnabled,omitempty" bson:"followRequestEnabled,omitempty"`
 }
 
+type UnitPreferences struct {
+	MeasurementSystem MeasurementSystem `json:"measurementSystem,omitempty" bson:"measurementSystem,omitempty" binding:"required"`
+}
+
 type Preferences struct {
 	Account       AccountPreferences      `json:"accountPreferences,omitempty" bson:"accountPreferences,omitempty"`
 	Privacy       PrivacyPreferences      `json:"privacyPreferences,omitempty" bson:"privacyPreferences,omitempty"`
 	Notifications NotificationPreferences `json:"notificationPreferences,omitempty" bson:"notificationPreferences,omitempty"`
+	Units         UnitPreferences         `json:"unitPreferences,omitempty" bson:"unitPreferences,omitempty"`
 }
 
 type PrivacyLevel string
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Block keeps the blocked account from messaging the blocker. Blocking
+// removes the follows between both accounts
+type Block struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Blocker   primitive.ObjectID `json:"blocker" bson:"blocker" binding:"required"`
+	Blocked   primitive.ObjectID `json:"blocked" bson:"blocked" binding:"required"`
+	BlockedAt time.Time          `json:"blockedAt" bson:"blockedAt" binding:"required"`
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BodyMeasurement is a weigh-in or set of body measurements taken at a point
+// in time. Weight is stored in kilograms and lengths in centimeters, body fat
+// is a percentage. Measurements that weren't taken are left at zero. Photos
+// are the keys of files uploaded through the file upload endpoint
+type BodyMeasurement struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Account    primitive.ObjectID `json:"account" bson:"account" binding:"required"`
+	MeasuredAt time.Time          `json:"measuredAt" bson:"measuredAt" binding:"required"`
+	Weight     float64            `json:"weight,omitempty" bson:"weight,omitempty"`
+	BodyFat    float64            `json:"bodyFat,omitempty" bson:"bodyFat,omitempty"`
+	Neck       float64            `json:"neck,omitempty" bson:"neck,omitempty"`
+	Chest      float64            `json:"chest,omitempty" bson:"chest,omitempty"`
+	Waist      float64            `json:"waist,omitempty" bson:"waist,omitempty"`
+	Hips       float64            `json:"hips,omitempty" bson:"hips,omitempty"`
+	Arms       float64            `json:"arms,omitempty" bson:"arms,omitempty"`
+	Thighs     float64            `json:"thighs,omitempty" bson:"thighs,omitempty"`
+	Calves     float64            `json:"calves,omitempty" bson:"calves,omitempty"`
+	Photos     []string           `json:"photos,omitempty" bson:"photos,omitempty"`
+	PhotoUrls  []string           `json:"photoUrls,omitempty" bson:"-"`
+	Note       string             `json:"note,omitempty" bson:"note,omitempty"`
+}
+
+type BodyMetric string
+
+const (
+	BODY_WEIGHT BodyMetric = "weight"
+	BODY_FAT    BodyMetric = "bodyFat"
+	BODY_NECK   BodyMetric = "neck"
+	BODY_CHEST  BodyMetric = "chest"
+	BODY_WAIST  BodyMetric = "waist"
+	BODY_HIPS   BodyMetric = "hips"
+	BODY_ARMS   BodyMetric = "arms"
+	BODY_THIGHS BodyMetric = "thighs"
+	BODY_CALVES BodyMetric = "calves"
+)
+
+// Value returns the measurement of the provided metric and whether the metric
+// is known
+func (measurement BodyMeasurement) Value(metric BodyMetric) (float64, bool) {
+	switch metric {
+	case BODY_WEIGHT:
+		return measurement.Weight, true
+	case BODY_FAT:
+		return measurement.BodyFat, true
+	case BODY_NECK:
+		return measurement.Neck, true
+	case BODY_CHEST:
+		return measurement.Chest, true
+	case BODY_WAIST:
+		return measurement.Waist, true
+	case BODY_HIPS:
+		return measurement.Hips, true
+	case BODY_ARMS:
+		return measurement.Arms, true
+	case BODY_THIGHS:
+		return measurement.Thighs, true
+	case BODY_CALVES:
+		return measurement.Calves, true
+	}
+
+	return 0, false
+}
+
+// BodyTrendPoint is the average measurement of a single day along with the
+// moving average over the trend window ending that day
+type BodyTrendPoint struct {
+	Date          time.Time `json:"date"`
+	Value         float64   `json:"value"`
+	MovingAverage float64   `json:"movingAverage"`
+}
+
+// BodyTrendWeek is the average measurement of an ISO week and the change from
+// the previous week with measurements, scaled to a single week
+type BodyTrendWeek struct {
+	Week    time.Time `json:"week"`
+	Average float64   `json:"average"`
+	Change  float64   `json:"change"`
+}
+
+// BodyTrend is the trend of a single metric over a range of days. The rate is
+// the change per week of the line best fitting the daily values
+type BodyTrend struct {
+	Metric      BodyMetric       `json:"metric"`
+	Window      int              `json:"window"`
+	Points      []BodyTrendPoint `json:"points"`
+	Weeks       []BodyTrendWeek  `json:"weeks"`
+	RatePerWeek float64          `json:"ratePerWeek"`
+}
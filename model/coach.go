@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CoachLink connects a coach to an athlete. Links are created by the coach
+// as PENDING invites and become ACCEPTED once the athlete accepts them
+type CoachLink struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Coach      primitive.ObjectID `json:"coach" bson:"coach" binding:"required"`
+	Athlete    primitive.ObjectID `json:"athlete" bson:"athlete" binding:"required"`
+	Status     CoachLinkStatus    `json:"status" bson:"status" binding:"required"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+	AcceptedAt time.Time          `json:"acceptedAt,omitempty" bson:"acceptedAt,omitempty"`
+}
+
+type CoachLinkStatus string
+
+const (
+	COACH_LINK_PENDING  CoachLinkStatus = "PENDING"
+	COACH_LINK_ACCEPTED CoachLinkStatus = "ACCEPTED"
+)
+
+// SessionComment is a comment left on a session by the athlete or one of
+// their coaches
+type SessionComment struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Session   primitive.ObjectID `json:"session" bson:"session" binding:"required"`
+	Author    primitive.ObjectID `json:"author" bson:"author" binding:"required"`
+	Text      string             `json:"text" bson:"text" binding:"required"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+}
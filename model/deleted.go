@@ -6,6 +6,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// DeletedAccount is kept until RemovalAt. Documents deleted along with the
+// account are kept in the _deleted version of their collections with Cascade
+// set to the account ID, so they can be restored with it
 type DeletedAccount struct {
 	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	Account   Account            `json:"account" bson:"account" binding:"required"`
@@ -16,28 +19,96 @@ type DeletedSession struct {
 	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	Session   Session            `json:"session" bson:"session" binding:"required"`
 	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
 }
 
 type DeletedPost struct {
 	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	Post      Post               `json:"post" bson:"post" binding:"required"`
 	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
 }
 
 type DeletedComment struct {
 	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	Comment   Comment            `json:"comment" bson:"comment" binding:"required"`
 	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
 }
 
 type DeletedLocation struct {
-	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id"`
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	Location  Location           `json:"location" bson:"location" binding:"required"`
 	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
 }
 
 type DeletedBlogPost struct {
-	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id"`
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	Blog      BlogPost           `json:"blog" bson:"blog" binding:"required"`
 	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
+}
+
+type DeletedWorkoutTemplate struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Template  WorkoutTemplate    `json:"template" bson:"template" binding:"required"`
+	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
+}
+
+type DeletedProgram struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Program   Program            `json:"program" bson:"program" binding:"required"`
+	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
+}
+
+type DeletedFile struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	File      File               `json:"file" bson:"file" binding:"required"`
+	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
+}
+
+type DeletedExportJob struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Job       ExportJob          `json:"job" bson:"job" binding:"required"`
+	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
+}
+
+type DeletedFood struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Food      Food               `json:"food" bson:"food" binding:"required"`
+	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
+}
+
+type DeletedDietEntry struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Entry     DietEntry          `json:"entry" bson:"entry" binding:"required"`
+	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
+}
+
+type DeletedRecipe struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Recipe    Recipe             `json:"recipe" bson:"recipe" binding:"required"`
+	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
+}
+
+type DeletedMealPlan struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Plan      MealPlan           `json:"plan" bson:"plan" binding:"required"`
+	RemovalAt time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade   primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
+}
+
+type DeletedBodyMeasurement struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Measurement BodyMeasurement    `json:"measurement" bson:"measurement" binding:"required"`
+	RemovalAt   time.Time          `json:"removalAt" bson:"removalAt" binding:"required"`
+	Cascade     primitive.ObjectID `json:"cascade,omitempty" bson:"cascade,omitempty"`
 }
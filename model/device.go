@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeviceToken is a push notification token registered by an app install.
+// Tokens registered while logged in are tied to the login session, a hash of
+// its refresh token, so they stop receiving pushes on logout
+type DeviceToken struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Account    primitive.ObjectID `json:"account" bson:"account" binding:"required"`
+	Session    string             `json:"-" bson:"session,omitempty"`
+	Platform   DevicePlatform     `json:"platform" bson:"platform" binding:"required"`
+	Provider   string             `json:"provider" bson:"provider" binding:"required"`
+	Token      string             `json:"token" bson:"token" binding:"required"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+	LastSeenAt time.Time          `json:"lastSeenAt" bson:"lastSeenAt" binding:"required"`
+}
+
+type DevicePlatform string
+
+const (
+	IOS     DevicePlatform = "IOS"
+	ANDROID DevicePlatform = "ANDROID"
+	WEB     DevicePlatform = "WEB"
+)
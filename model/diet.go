@@ -6,32 +6,49 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// DietEntry is the actual database entry stored in the collection
+// DietEntry is the actual database entry stored in the collection. The food
+// is copied in to the entry when it is logged, so later edits to the catalog
+// don't change what was eaten. Entries logged from a recipe hold a serving of
+// the recipe as their food along with the recipe ID
 type DietEntry struct {
-	ID      primitive.ObjectID `json:"id" bson:"_id"`
-	AddedAt time.Time          `json:"addedAt" bson:"addedAt" binding:"required"`
-	Food    Food               `json:"food" bson:"food" binding:"required"`
+	ID       primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Account  primitive.ObjectID `json:"account" bson:"account"`
+	Recipe   primitive.ObjectID `json:"recipe,omitempty" bson:"recipe,omitempty"`
+	MealPlan primitive.ObjectID `json:"mealPlan,omitempty" bson:"mealPlan,omitempty"`
+	AddedAt  time.Time          `json:"addedAt" bson:"addedAt" binding:"required"`
+	Servings float64            `json:"servings" bson:"servings"`
+	Food     Food               `json:"food" bson:"food" binding:"required"`
 }
 
 // Food is both stored in the database as its own entry, but also applied to
-// DietEntry documents
+// DietEntry documents. Foods created without the author food permission are
+// unverified until reviewed
+//
+// Nutrients are per measurement. Calories are kcal, macros are grams, vitamin
+// A is micrograms and every other nutrient is milligrams
 type Food struct {
 	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
 	Name          string             `json:"name" bson:"name" binding:"required"`
+	Barcode       string             `json:"barcode,omitempty" bson:"barcode,omitempty"`
+	Source        string             `json:"source,omitempty" bson:"source,omitempty"`
+	Verified      bool               `json:"verified" bson:"verified"`
+	Author        primitive.ObjectID `json:"author,omitempty" bson:"author,omitempty"`
 	Measurement   FoodMeasureable    `json:"measurement" bson:"measurement" binding:"required"`
 	Calories      uint16             `json:"calories,omitempty" bson:"calories,omitempty"`
-	Protein       uint16             `json:"protein,omitempty" bson:"calories,omitempty"`
+	Protein       uint16             `json:"protein,omitempty" bson:"protein,omitempty"`
 	Carbohydrates uint16             `json:"carbohydrates,omitempty" bson:"carbohydrates,omitempty"`
 	Fiber         uint16             `json:"fiber,omitempty" bson:"fiber,omitempty"`
 	Sugar         uint16             `json:"sugar,omitempty" bson:"sugar,omitempty"`
 	Fat           uint16             `json:"fat,omitempty" bson:"fat,omitempty"`
 	Cholesterol   uint16             `json:"cholesterol,omitempty" bson:"cholesterol,omitempty"`
-	Sodium        uint16             `json:"sodium,omitempty" bson:"calories,omitempty"`
+	Sodium        uint16             `json:"sodium,omitempty" bson:"sodium,omitempty"`
 	Potassium     uint16             `json:"potassium,omitempty" bson:"potassium,omitempty"`
 	VitaminA      uint16             `json:"vitaminA,omitempty" bson:"vitaminA,omitempty"`
 	VitaminC      uint16             `json:"vitaminc,omitempty" bson:"vitaminc,omitempty"`
 	Calcium       uint16             `json:"calcium,omitempty" bson:"calcium,omitempty"`
 	Iron          uint16             `json:"iron,omitempty" bson:"iron,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt,omitempty" bson:"createdAt,omitempty"`
+	UpdatedAt     time.Time          `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
 }
 
 // FoodMeasureable is a nested-document stored within the Food struct which tracks the measurement
@@ -44,6 +61,17 @@ type FoodMeasureable struct {
 	Size        uint16          `json:"foodMeasurementSize" bson:"foodMeasurementSize" binding:"required"`
 }
 
+// Macros are the nutrient totals of one or more diet entries, scaled by the
+// servings eaten
+type Macros struct {
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fiber         float64 `json:"fiber"`
+	Sugar         float64 `json:"sugar"`
+	Fat           float64 `json:"fat"`
+}
+
 // DietWindow is a 'grouped' visualization object used to make it easier for the client to render
 // data when the user is looking at a diet screen
 type DietWindow struct {
@@ -51,11 +79,20 @@ type DietWindow struct {
 	Hour       uint8       `json:"hour"`
 	TimePeriod TimePeriod  `json:"timePeriod"`
 	Entries    []DietEntry `json:"entries"`
+	Totals     Macros      `json:"totals"`
+}
+
+// DietDay holds every diet window of a single day along with the totals of
+// the whole day
+type DietDay struct {
+	Date    time.Time    `json:"date"`
+	Windows []DietWindow `json:"windows"`
+	Totals  Macros       `json:"totals"`
 }
 
 type TimePeriod string
 
 const (
-	AM string = "AM"
-	PM string = "PM"
+	AM TimePeriod = "AM"
+	PM TimePeriod = "PM"
 )
@@ -13,44 +13,66 @@ type Session struct {
 	Status      SessionStatus      `json:"status,omitempty" bson:"status,omitempty" binding:"required"`
 	Timestamp   time.Time          `json:"timestamp,omitempty" bson:"timestamp,omitempty" time-format:"" binding:"required"`
 	Exercises   []Exercise         `json:"exercises,omitempty" bson:"exercises,omitempty" binding:"required"`
+	Template    primitive.ObjectID `json:"template,omitempty" bson:"template,omitempty"`
+	Enrollment  primitive.ObjectID `json:"enrollment,omitempty" bson:"enrollment,omitempty"`
+	ProgramWeek int                `json:"programWeek,omitempty" bson:"programWeek,omitempty"`
+	ProgramDay  uint8              `json:"programDay,omitempty" bson:"programDay,omitempty"`
+	AssignedBy  primitive.ObjectID `json:"assignedBy,omitempty" bson:"assignedBy,omitempty"`
+	Route       primitive.ObjectID `json:"route,omitempty" bson:"route,omitempty"`
+	ImportKey   string             `json:"-" bson:"importKey,omitempty"`
+	Version     int64              `json:"version" bson:"version"`
 }
 
 type Exercise struct {
 	ExerciseName       string               `json:"exerciseName,omitempty" bson:"exerciseName,omitempty" binding:"required"`
 	AddedAt            time.Time            `json:"addedAt,omitempty" bson:"addedAt,omitempty" binding:"required"`
-	Values             ExerciseValue        `json:"values,omitempty" bson:"values,omitempty" binding:"required"`
+	Sets               []ExerciseSet        `json:"sets,omitempty" bson:"sets,omitempty"`
 	Type               ExerciseType         `json:"type,omitempty" bson:"type,omitempty" binding:"required"`
+	Rest               uint64               `json:"rest,omitempty" bson:"rest,omitempty"`
 	AdditionalExercise []AdditionalExercise `json:"additionalExercises,omitempty" bson:"additionalExercises,omitempty"`
 }
 
 type AdditionalExercise struct {
 	ExerciseName string                 `json:"exerciseName" bson:"exerciseName,omitempty" binding:"required"`
 	AddedAt      time.Time              `json:"addedAt,omitempty" bson:"addedAt,omitempty" binding:"required"`
-	Values       ExerciseValue          `json:"values,omitempty" bson:"values,omitempty" binding:"required"`
+	Sets         []ExerciseSet          `json:"sets,omitempty" bson:"sets,omitempty"`
 	Type         AdditionalExerciseType `json:"type,omitempty" bson:"type,omitempty" binding:"required"`
 }
 
-type ExerciseValue struct {
-	Reps     uint8                 `json:"reps,omitempty" bson:"reps,omitempty"`
-	Weight   ExerciseValueWeight   `json:"weight,omitempty" bson:"weight,omitempty"`
-	Distance ExerciseValueDistance `json:"distance,omitempty" bson:"distance,omitempty"`
-	Time     ExerciseValueTime     `json:"time,omitempty" bson:"time,omitempty"`
+// ExerciseSet is a single set of an exercise. Only the values relevant to the
+// exercise type are expected to be populated, e.g. a WEIGHTED_REPS set only
+// tracks reps and weight
+type ExerciseSet struct {
+	Reps        uint8                 `json:"reps,omitempty" bson:"reps,omitempty"`
+	Weight      ExerciseValueWeight   `json:"weight,omitempty" bson:"weight,omitempty"`
+	Distance    ExerciseValueDistance `json:"distance,omitempty" bson:"distance,omitempty"`
+	Time        ExerciseValueTime     `json:"time,omitempty" bson:"time,omitempty"`
+	RPE         float32               `json:"rpe,omitempty" bson:"rpe,omitempty"`
+	Warmup      bool                  `json:"warmup,omitempty" bson:"warmup,omitempty"`
+	Completed   bool                  `json:"completed,omitempty" bson:"completed,omitempty"`
+	CompletedAt time.Time             `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
 }
 
+// ExerciseValueWeight is a weight as it was logged. Kilograms is the same
+// weight in kilograms, set by the server whenever the weight is written
 type ExerciseValueWeight struct {
-	Value               float32           `json:"weightValue,omitempty" bson:"weightValue,omitempty" binding:"required"`
-	Measurement         MeasurementSystem `json:"weightMeasurementSystem,omitempty" bson:"weightMeasurementSystem,omitempty" binding:"required"`
+	Value               float32           `json:"weightValue,omitempty" bson:"weightValue,omitempty"`
+	Measurement         MeasurementSystem `json:"weightMeasurementSystem,omitempty" bson:"weightMeasurementSystem,omitempty"`
+	Kilograms           float64           `json:"kilograms,omitempty" bson:"kilograms,omitempty"`
 	PlateCounterEnabled bool              `json:"plateCounterEnabled,omitempty" bson:"plateCounterEnabled,omitempty"`
 }
 
+// ExerciseValueDistance is a distance as it was logged. Meters is the same
+// distance in meters, set by the server whenever the distance is written
 type ExerciseValueDistance struct {
-	Value       uint32              `json:"distanceValue,omitempty" bson:"distanceValue,omitempty" binding:"required"`
-	Measurement DistanceMeasurement `json:"distanceMeasurementSystem,omitempty" bson:"distanceMeasurementSystem,omitempty" binding:"required"`
+	Value       uint32              `json:"distanceValue,omitempty" bson:"distanceValue,omitempty"`
+	Measurement DistanceMeasurement `json:"distanceMeasurementSystem,omitempty" bson:"distanceMeasurementSystem,omitempty"`
+	Meters      float64             `json:"meters,omitempty" bson:"meters,omitempty"`
 }
 
 type ExerciseValueTime struct {
-	Value            uint64 `json:"timeValue,omitempty" bson:"timeValue,omitempty" binding:"required"`
-	ShowMilliseconds bool   `json:"timeRenderMillis,omitempty" bson:"timeRenderMillis,omitempty" binding:"required"`
+	Value            uint64 `json:"timeValue,omitempty" bson:"timeValue,omitempty"`
+	ShowMilliseconds bool   `json:"timeRenderMillis,omitempty" bson:"timeRenderMillis,omitempty"`
 }
 
 type ExerciseType string
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExportJob tracks an export of all the data held about an account. The
+// archive is stored in the bucket under Key once the job is ready
+type ExportJob struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Account     primitive.ObjectID `json:"account" bson:"account" binding:"required"`
+	Status      ExportStatus       `json:"status" bson:"status" binding:"required"`
+	Key         string             `json:"-" bson:"key,omitempty"`
+	Error       string             `json:"error,omitempty" bson:"error,omitempty"`
+	RequestedAt time.Time          `json:"requestedAt" bson:"requestedAt" binding:"required"`
+	CompletedAt time.Time          `json:"completedAt,omitempty" bson:"completedAt,omitempty"`
+}
+
+type ExportStatus string
+
+const (
+	EXPORT_PENDING ExportStatus = "PENDING"
+	EXPORT_READY   ExportStatus = "READY"
+	EXPORT_FAILED  ExportStatus = "FAILED"
+)
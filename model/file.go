@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// File is an object uploaded to the bucket by an account
+type File struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Owner      primitive.ObjectID `json:"owner" bson:"owner" binding:"required"`
+	Key        string             `json:"key" bson:"key" binding:"required"`
+	Filename   string             `json:"filename,omitempty" bson:"filename,omitempty"`
+	Size       int64              `json:"size,omitempty" bson:"size,omitempty"`
+	UploadedAt time.Time          `json:"uploadedAt" bson:"uploadedAt" binding:"required"`
+}
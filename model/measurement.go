@@ -19,10 +19,10 @@ const (
 type FoodMeasurement string
 
 const (
-	OUNCE    string = "OUNCE"
-	GRAM     string = "GRAM"
-	POUND    string = "POUND"
-	KILOGRAM string = "KILOGRAM"
-	CUP      string = "CUP"
-	SERVING  string = "SERVING"
+	OUNCE    FoodMeasurement = "OUNCE"
+	GRAM     FoodMeasurement = "GRAM"
+	POUND    FoodMeasurement = "POUND"
+	KILOGRAM FoodMeasurement = "KILOGRAM"
+	CUP      FoodMeasurement = "CUP"
+	SERVING  FoodMeasurement = "SERVING"
 )
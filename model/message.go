@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxConversationParticipants caps the size of group conversations
+const MaxConversationParticipants = 10
+
+// Conversation is a direct conversation between two accounts or a named group
+// conversation. Reads holds the last message each participant has read,
+// keyed by the hex of their account id, and Muted the participants that
+// receive no notifications for new messages
+type Conversation struct {
+	ID           primitive.ObjectID     `json:"id,omitempty" bson:"_id,omitempty"`
+	Participants []primitive.ObjectID   `json:"participants" bson:"participants" binding:"required"`
+	Creator      primitive.ObjectID     `json:"creator" bson:"creator" binding:"required"`
+	Group        bool                   `json:"group" bson:"group"`
+	Name         string                 `json:"name,omitempty" bson:"name,omitempty"`
+	Muted        []primitive.ObjectID   `json:"muted,omitempty" bson:"muted,omitempty"`
+	Reads        map[string]ReadReceipt `json:"reads,omitempty" bson:"reads,omitempty"`
+	LastMessage  *Message               `json:"lastMessage,omitempty" bson:"lastMessage,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt" bson:"createdAt" binding:"required"`
+	UpdatedAt    time.Time              `json:"updatedAt" bson:"updatedAt" binding:"required"`
+}
+
+// ReadReceipt records the newest message a participant has read
+type ReadReceipt struct {
+	Message primitive.ObjectID `json:"message" bson:"message"`
+	ReadAt  time.Time          `json:"readAt" bson:"readAt"`
+}
+
+// Message is sent to a conversation. Attachments are keys of files uploaded
+// by the author, AttachmentUrls are only filled in when the message is read
+type Message struct {
+	ID             primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Conversation   primitive.ObjectID `json:"conversation" bson:"conversation" binding:"required"`
+	Author         primitive.ObjectID `json:"author" bson:"author" binding:"required"`
+	Text           string             `json:"text,omitempty" bson:"text,omitempty"`
+	Attachments    []string           `json:"attachments,omitempty" bson:"attachments,omitempty"`
+	AttachmentUrls []string           `json:"attachmentUrls,omitempty" bson:"-"`
+	CreatedAt      time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+}
+
+// IsParticipant returns true if the account is part of the conversation
+func (conversation Conversation) IsParticipant(accountId primitive.ObjectID) bool {
+	for _, participant := range conversation.Participants {
+		if participant == accountId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsMuted returns true if the account muted the conversation
+func (conversation Conversation) IsMuted(accountId primitive.ObjectID) bool {
+	for _, muted := range conversation.Muted {
+		if muted == accountId {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NutritionTarget is the daily calorie and macro goal of an account. Targets
+// derived from the account biometrics keep the activity level and adjustment
+// used, so they can be recalculated when the biometrics change
+type NutritionTarget struct {
+	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Account       primitive.ObjectID `json:"account" bson:"account"`
+	Calories      float64            `json:"calories" bson:"calories"`
+	Protein       float64            `json:"protein,omitempty" bson:"protein,omitempty"`
+	Carbohydrates float64            `json:"carbohydrates,omitempty" bson:"carbohydrates,omitempty"`
+	Fat           float64            `json:"fat,omitempty" bson:"fat,omitempty"`
+	Fiber         float64            `json:"fiber,omitempty" bson:"fiber,omitempty"`
+	Derived       bool               `json:"derived" bson:"derived"`
+	ActivityLevel ActivityLevel      `json:"activityLevel,omitempty" bson:"activityLevel,omitempty"`
+	Adjustment    float64            `json:"adjustment,omitempty" bson:"adjustment,omitempty"`
+	UpdatedAt     time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+// Macros returns the target as macro totals, untargeted nutrients are zero
+func (target NutritionTarget) Macros() Macros {
+	return Macros{
+		Calories:      target.Calories,
+		Protein:       target.Protein,
+		Carbohydrates: target.Carbohydrates,
+		Fat:           target.Fat,
+		Fiber:         target.Fiber,
+	}
+}
+
+// ActivityLevel scales the resting energy expenditure of an account to its
+// total daily energy expenditure
+type ActivityLevel string
+
+const (
+	SEDENTARY         ActivityLevel = "SEDENTARY"
+	LIGHTLY_ACTIVE    ActivityLevel = "LIGHTLY_ACTIVE"
+	MODERATELY_ACTIVE ActivityLevel = "MODERATELY_ACTIVE"
+	VERY_ACTIVE       ActivityLevel = "VERY_ACTIVE"
+	EXTRA_ACTIVE      ActivityLevel = "EXTRA_ACTIVE"
+)
+
+// NutritionSummary compares the macros logged over one or more days against
+// the target for the same number of days. Remaining is negative for nutrients
+// logged over the target and zero for untargeted nutrients
+type NutritionSummary struct {
+	From      time.Time          `json:"from"`
+	To        time.Time          `json:"to"`
+	Days      int                `json:"days"`
+	Logged    Macros             `json:"logged"`
+	Target    Macros             `json:"target"`
+	Remaining Macros             `json:"remaining"`
+	Daily     []NutritionSummary `json:"daily,omitempty"`
+}
@@ -5,17 +5,21 @@ import (
 	"time"
 )
 
+// Post is shared by an account. LikeCount and CommentCount are kept in step
+// with the likes and comments on the post and can't be set by clients
 type Post struct {
-	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	Author    primitive.ObjectID `json:"author" bson:"author" binding:"required"`
-	Location  primitive.ObjectID `json:"location,omitempty" bson:"location,omitempty"`
-	Session   primitive.ObjectID `json:"session,omitempty" bson:"session,omitempty"`
-	Text      string             `json:"text,omitempty" bson:"text,omitempty"`
-	Content   []ContentItem      `json:"content,omitempty" bson:"content,omitempty"`
-	CreatedAt time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
-	EditedAt  time.Time          `json:"editedAt,omitempty" bson:"editedAt,omitempty"`
-	Tags      []string           `json:"tags,omitempty" bson:"tags,omitempty"`
-	Privacy   PrivacyLevel       `json:"privacy,omitempty" bson:"privacy,omitempty"`
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Author       primitive.ObjectID `json:"author" bson:"author" binding:"required"`
+	Location     primitive.ObjectID `json:"location,omitempty" bson:"location,omitempty"`
+	Session      primitive.ObjectID `json:"session,omitempty" bson:"session,omitempty"`
+	Text         string             `json:"text,omitempty" bson:"text,omitempty"`
+	Content      []ContentItem      `json:"content,omitempty" bson:"content,omitempty"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+	EditedAt     time.Time          `json:"editedAt,omitempty" bson:"editedAt,omitempty"`
+	Tags         []string           `json:"tags,omitempty" bson:"tags,omitempty"`
+	Privacy      PrivacyLevel       `json:"privacy,omitempty" bson:"privacy,omitempty"`
+	LikeCount    int64              `json:"likeCount" bson:"likeCount"`
+	CommentCount int64              `json:"commentCount" bson:"commentCount"`
 }
 
 type ContentItem struct {
@@ -37,14 +41,26 @@ type Like struct {
 	LikedAt  time.Time          `json:"likedAt" bson:"likedAt" binding:"required"`
 }
 
+// MaxCommentDepth is the number of levels a comment thread can be nested,
+// comments on a post are at depth 0
+const MaxCommentDepth = 3
+
+// Comment is left on a post or, as a reply, on another comment. Post is the
+// document commented on, the parent comment of replies, and Root the post at
+// the top of the thread. LikeCount and ReplyCount are kept in step with the
+// likes and direct replies on the comment
 type Comment struct {
-	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
-	Post      primitive.ObjectID `json:"post" bson:"post" binding:"required"`
-	Author    primitive.ObjectID `json:"author" bson:"author" binding:"required"`
-	PostType  PostItemType       `json:"type" bson:"type" binding:"required"`
-	Text      string             `json:"text" bson:"text" binding:"required"`
-	CreatedAt time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
-	EditedAt  time.Time          `json:"editedAt,omitempty" bson:"editedAt,omitempty"`
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Post       primitive.ObjectID `json:"post" bson:"post" binding:"required"`
+	Root       primitive.ObjectID `json:"root,omitempty" bson:"root,omitempty"`
+	Depth      int                `json:"depth" bson:"depth"`
+	Author     primitive.ObjectID `json:"author" bson:"author" binding:"required"`
+	PostType   PostItemType       `json:"type" bson:"type" binding:"required"`
+	Text       string             `json:"text" bson:"text" binding:"required"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+	EditedAt   time.Time          `json:"editedAt,omitempty" bson:"editedAt,omitempty"`
+	LikeCount  int64              `json:"likeCount" bson:"likeCount"`
+	ReplyCount int64              `json:"replyCount" bson:"replyCount"`
 }
 
 type ContentType string
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Program is a multi-week training plan made of workout templates. Every
+// week holds the days sessions are scheduled on, relative to the date an
+// account enrolled
+type Program struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Author      primitive.ObjectID `json:"author" bson:"author" binding:"required"`
+	Name        string             `json:"name" bson:"name" binding:"required"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	Privacy     PrivacyLevel       `json:"privacy" bson:"privacy" binding:"required"`
+	Weeks       []ProgramWeek      `json:"weeks" bson:"weeks" binding:"required"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+	UpdatedAt   time.Time          `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
+
+type ProgramWeek struct {
+	Days []ProgramDay `json:"days" bson:"days" binding:"required,dive"`
+}
+
+// ProgramDay schedules a workout template on a day of the week, where day 1
+// is the weekday the account enrolled on. Loads override the targets of the
+// template exercises for this day
+type ProgramDay struct {
+	Day      uint8              `json:"day" bson:"day" binding:"required"`
+	Name     string             `json:"name,omitempty" bson:"name,omitempty"`
+	Template primitive.ObjectID `json:"template" bson:"template" binding:"required"`
+	Loads    []ProgramLoad      `json:"loads,omitempty" bson:"loads,omitempty" binding:"dive"`
+}
+
+// ProgramLoad overrides the targets of a single template exercise. Percentage
+// is a percentage of the estimated one rep max of the enrolled account, sets
+// and reps are left as they are in the template when zero
+type ProgramLoad struct {
+	Exercise   primitive.ObjectID `json:"exercise" bson:"exercise" binding:"required"`
+	Percentage float64            `json:"percentage,omitempty" bson:"percentage,omitempty"`
+	Sets       uint8              `json:"sets,omitempty" bson:"sets,omitempty"`
+	Reps       uint8              `json:"reps,omitempty" bson:"reps,omitempty"`
+}
+
+type ProgramEnrollment struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Account    primitive.ObjectID `json:"account" bson:"account" binding:"required"`
+	Program    primitive.ObjectID `json:"program" bson:"program" binding:"required"`
+	Status     EnrollmentStatus   `json:"status" bson:"status" binding:"required"`
+	StartDate  time.Time          `json:"startDate" bson:"startDate" binding:"required"`
+	EnrolledAt time.Time          `json:"enrolledAt" bson:"enrolledAt" binding:"required"`
+	EndedAt    time.Time          `json:"endedAt,omitempty" bson:"endedAt,omitempty"`
+}
+
+type EnrollmentStatus string
+
+const (
+	ENROLLMENT_ACTIVE    EnrollmentStatus = "ACTIVE"
+	ENROLLMENT_CANCELLED EnrollmentStatus = "CANCELLED"
+)
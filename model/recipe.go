@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Recipe is a dish made from foods in the catalog. The nutrients of a single
+// serving are calculated from the ingredients whenever the recipe is saved
+// and logged in diet entries as a food measured in servings
+type Recipe struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Author      primitive.ObjectID `json:"author" bson:"author" binding:"required"`
+	Name        string             `json:"name" bson:"name" binding:"required"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	Privacy     PrivacyLevel       `json:"privacy" bson:"privacy" binding:"required"`
+	Servings    uint16             `json:"servings" bson:"servings" binding:"required"`
+	Ingredients []Ingredient       `json:"ingredients" bson:"ingredients" binding:"required"`
+	PerServing  Food               `json:"perServing" bson:"perServing"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+	UpdatedAt   time.Time          `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
+
+// Ingredient references a food in the catalog along with the quantity used.
+// The quantity is in the unit of the ingredient, which defaults to the unit
+// the food is measured in. Weights can be given in any weight unit. The food
+// name and measurement are copied from the catalog when the recipe is saved
+type Ingredient struct {
+	Food        primitive.ObjectID `json:"food" bson:"food" binding:"required"`
+	Name        string             `json:"name,omitempty" bson:"name,omitempty"`
+	Quantity    float64            `json:"quantity" bson:"quantity" binding:"required"`
+	Unit        FoodMeasurement    `json:"unit,omitempty" bson:"unit,omitempty"`
+	Measurement FoodMeasureable    `json:"measurement,omitempty" bson:"measurement,omitempty"`
+}
+
+// MealPlan is a set of meals a coach assigns to an athlete, spread over the
+// days of the plan starting at StartDate
+type MealPlan struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Account     primitive.ObjectID `json:"account" bson:"account" binding:"required"`
+	AssignedBy  primitive.ObjectID `json:"assignedBy" bson:"assignedBy" binding:"required"`
+	Name        string             `json:"name" bson:"name" binding:"required"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	StartDate   time.Time          `json:"startDate" bson:"startDate" binding:"required"`
+	Meals       []PlannedMeal      `json:"meals" bson:"meals" binding:"required"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+}
+
+// PlannedMeal is a meal eaten on a day of a meal plan, counting from 1
+type PlannedMeal struct {
+	Day   uint16        `json:"day" bson:"day" binding:"required"`
+	Name  string        `json:"name" bson:"name" binding:"required"`
+	Items []PlannedItem `json:"items" bson:"items" binding:"required"`
+}
+
+// PlannedItem is either a food or a recipe eaten as part of a planned meal.
+// The name is copied from the food or recipe when the plan is assigned
+type PlannedItem struct {
+	Food     primitive.ObjectID `json:"food,omitempty" bson:"food,omitempty"`
+	Recipe   primitive.ObjectID `json:"recipe,omitempty" bson:"recipe,omitempty"`
+	Name     string             `json:"name,omitempty" bson:"name,omitempty"`
+	Servings float64            `json:"servings" bson:"servings" binding:"required"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PersonalRecord is the best result an account has logged for a single
+// exercise and record type.
+//
+// Weights are stored in kilograms, distances in meters and times in
+// milliseconds regardless of the units the sets were logged in.
+// Bracket holds the weight (MAX_REPS) or distance (FASTEST_TIME) the
+// record is held at, and is zero for every other record type
+type PersonalRecord struct {
+	ID            primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Account       primitive.ObjectID `json:"account" bson:"account" binding:"required"`
+	ExerciseName  string             `json:"exerciseName" bson:"exerciseName" binding:"required"`
+	Type          PersonalRecordType `json:"type" bson:"type" binding:"required"`
+	Bracket       float64            `json:"bracket" bson:"bracket"`
+	Value         float64            `json:"value" bson:"value" binding:"required"`
+	PreviousValue float64            `json:"previousValue,omitempty" bson:"-"`
+	Formula       OneRepMaxFormula   `json:"formula,omitempty" bson:"formula,omitempty"`
+	Reps          uint8              `json:"reps,omitempty" bson:"reps,omitempty"`
+	Weight        float64            `json:"weight,omitempty" bson:"weight,omitempty"`
+	Distance      float64            `json:"distance,omitempty" bson:"distance,omitempty"`
+	Time          uint64             `json:"time,omitempty" bson:"time,omitempty"`
+	Session       primitive.ObjectID `json:"session" bson:"session" binding:"required"`
+	AchievedAt    time.Time          `json:"achievedAt" bson:"achievedAt" binding:"required"`
+}
+
+type PersonalRecordType string
+type OneRepMaxFormula string
+
+const (
+	MAX_WEIGHT            PersonalRecordType = "MAX_WEIGHT"
+	ESTIMATED_ONE_REP_MAX PersonalRecordType = "ESTIMATED_ONE_REP_MAX"
+	MAX_REPS              PersonalRecordType = "MAX_REPS"
+	FASTEST_TIME          PersonalRecordType = "FASTEST_TIME"
+	LONGEST_DISTANCE      PersonalRecordType = "LONGEST_DISTANCE"
+)
+
+const (
+	EPLEY   OneRepMaxFormula = "EPLEY"
+	BRZYCKI OneRepMaxFormula = "BRZYCKI"
+)
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Route is the recorded path of an imported distance session. Distance and
+// elevation gain are in meters, duration in milliseconds and pace in seconds
+// per kilometer. Path is nil for activities recorded without a GPS fix, the
+// 2dsphere index rejects an empty line
+type Route struct {
+	ID               primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Author           primitive.ObjectID `json:"author" bson:"author" binding:"required"`
+	Session          primitive.ObjectID `json:"session" bson:"session" binding:"required"`
+	Location         primitive.ObjectID `json:"location,omitempty" bson:"location,omitempty"`
+	Source           string             `json:"source" bson:"source"`
+	Path             *LineString        `json:"path,omitempty" bson:"path,omitempty"`
+	Distance         float64            `json:"distance" bson:"distance"`
+	Duration         uint64             `json:"duration" bson:"duration"`
+	Pace             float64            `json:"pace,omitempty" bson:"pace,omitempty"`
+	ElevationGain    float64            `json:"elevationGain" bson:"elevationGain"`
+	AverageHeartRate uint8              `json:"averageHeartRate,omitempty" bson:"averageHeartRate,omitempty"`
+	MaxHeartRate     uint8              `json:"maxHeartRate,omitempty" bson:"maxHeartRate,omitempty"`
+	HeartRate        []HeartRateSample  `json:"heartRate,omitempty" bson:"heartRate,omitempty"`
+	StartedAt        time.Time          `json:"startedAt" bson:"startedAt"`
+	CreatedAt        time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// LineString is a GeoJSON line, coordinates are [longitude, latitude] pairs
+type LineString struct {
+	Type        string      `json:"type" bson:"type"`
+	Coordinates [][]float64 `json:"coordinates" bson:"coordinates"`
+}
+
+type HeartRateSample struct {
+	Time time.Time `json:"time" bson:"time"`
+	BPM  uint8     `json:"bpm" bson:"bpm"`
+}
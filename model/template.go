@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WorkoutTemplate is a reusable, ordered list of exercises that sessions can
+// be started from. Privacy decides who besides the author can view and start
+// the template
+type WorkoutTemplate struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Author      primitive.ObjectID `json:"author" bson:"author" binding:"required"`
+	Name        string             `json:"name" bson:"name" binding:"required"`
+	Description string             `json:"description,omitempty" bson:"description,omitempty"`
+	Privacy     PrivacyLevel       `json:"privacy" bson:"privacy" binding:"required"`
+	Exercises   []TemplateExercise `json:"exercises" bson:"exercises" binding:"required"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+	UpdatedAt   time.Time          `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+}
+
+// TemplateExercise references an ExerciseInfo document along with the
+// targets pre-filled in to sessions started from the template. The exercise
+// name and type are copied from the exercise info when the template is saved.
+// Rest is the target rest between sets in milliseconds
+type TemplateExercise struct {
+	Exercise     primitive.ObjectID  `json:"exercise" bson:"exercise" binding:"required"`
+	ExerciseName string              `json:"exerciseName,omitempty" bson:"exerciseName,omitempty"`
+	Type         ExerciseType        `json:"type,omitempty" bson:"type,omitempty"`
+	Sets         uint8               `json:"sets" bson:"sets" binding:"required"`
+	Reps         uint8               `json:"reps,omitempty" bson:"reps,omitempty"`
+	Weight       ExerciseValueWeight `json:"weight,omitempty" bson:"weight,omitempty"`
+	Rest         uint64              `json:"rest,omitempty" bson:"rest,omitempty"`
+}
@@ -0,0 +1,116 @@
+package notification
+
+import (
+	"ares/database"
+	"ares/model"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type EmitParams struct {
+	Recipient   primitive.ObjectID `json:"recipient" binding:"required"`
+	Actor       primitive.ObjectID `json:"actor" binding:"required"`
+	Type        Type               `json:"type" binding:"required"`
+	Subject     primitive.ObjectID `json:"subject,omitempty"`
+	Text        string             `json:"text,omitempty"`
+	MongoClient *mongo.Client
+}
+
+// Dispatcher delivers a saved notification outside of the inbox, e.g. as a
+// push notification. Dispatchers are called after the notification is stored
+type Dispatcher func(notification Notification, recipient model.Account) error
+
+var (
+	dispatchersMu sync.RWMutex
+	dispatchers   = map[string]Dispatcher{}
+)
+
+// RegisterDispatcher adds a dispatcher under the provided name, replacing any
+// dispatcher already registered under it
+func RegisterDispatcher(name string, dispatcher Dispatcher) {
+	dispatchersMu.Lock()
+	defer dispatchersMu.Unlock()
+
+	dispatchers[name] = dispatcher
+}
+
+// Enabled returns true if the notification preferences allow notifications of
+// the provided type
+func Enabled(preferences model.NotificationPreferences, notificationType Type) bool {
+	switch notificationType {
+	case NEW_FOLLOWER, FOLLOW_REQUEST, FOLLOW_ACCEPTED:
+		return preferences.NotifyNewFollower
+	case NEW_LIKE:
+		return preferences.NotifyNewLike
+	case NEW_COMMENT:
+		return preferences.NotifyNewComment
+	case ASSIGNED_SESSION:
+		return preferences.NotifyNewAssignedSession
+	case ASSIGNED_MEAL:
+		return preferences.NotifyNewAssignedMeal
+	case NEW_MESSAGE:
+		return preferences.NotifyNewMessage
+	}
+
+	return false
+}
+
+// Emit saves a notification to the inbox of the recipient and hands it to
+// every registered dispatcher. Nothing is saved when the recipient is the
+// actor or the recipient has turned off notifications of the type
+func Emit(params EmitParams) error {
+	if params.Recipient.IsZero() || params.Recipient == params.Actor {
+		return nil
+	}
+
+	recipient, err := database.FindDocumentById[model.Account](database.QueryParams{
+		MongoClient:    params.MongoClient,
+		DatabaseName:   "prod",
+		CollectionName: "account",
+	}, params.Recipient.Hex())
+
+	if err != nil {
+		return err
+	}
+
+	if !Enabled(recipient.Preferences.Notifications, params.Type) {
+		return nil
+	}
+
+	notification := Notification{
+		Recipient: params.Recipient,
+		Actor:     params.Actor,
+		Type:      params.Type,
+		Subject:   params.Subject,
+		Text:      params.Text,
+		CreatedAt: time.Now(),
+	}
+
+	inserted, err := database.InsertOne(database.QueryParams{
+		MongoClient:    params.MongoClient,
+		DatabaseName:   "prod",
+		CollectionName: "notification",
+	}, notification)
+
+	if err != nil {
+		return err
+	}
+
+	notification.ID, _ = primitive.ObjectIDFromHex(inserted)
+
+	dispatchersMu.RLock()
+	defer dispatchersMu.RUnlock()
+
+	for name, dispatch := range dispatchers {
+		err = dispatch(notification, recipient)
+		if err != nil {
+			fmt.Println("failed to dispatch notification with "+name+": ", err)
+		}
+	}
+
+	return nil
+}
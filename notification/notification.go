@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification is an entry in the inbox of the recipient. The subject is the
+// document the notification is about, e.g. the liked post or the assigned
+// session, and is empty for follow notifications
+type Notification struct {
+	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Recipient primitive.ObjectID `json:"recipient" bson:"recipient" binding:"required"`
+	Actor     primitive.ObjectID `json:"actor" bson:"actor" binding:"required"`
+	Type      Type               `json:"type" bson:"type" binding:"required"`
+	Subject   primitive.ObjectID `json:"subject,omitempty" bson:"subject,omitempty"`
+	Text      string             `json:"text,omitempty" bson:"text,omitempty"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt" binding:"required"`
+	Read      bool               `json:"read" bson:"read"`
+	ReadAt    time.Time          `json:"readAt,omitempty" bson:"readAt,omitempty"`
+}
+
+type Type string
+
+const (
+	NEW_FOLLOWER     Type = "new_follower"
+	FOLLOW_REQUEST   Type = "follow_request"
+	FOLLOW_ACCEPTED  Type = "follow_accepted"
+	NEW_LIKE         Type = "new_like"
+	NEW_COMMENT      Type = "new_comment"
+	ASSIGNED_SESSION Type = "assigned_session"
+	ASSIGNED_MEAL    Type = "assigned_meal"
+	NEW_MESSAGE      Type = "new_message"
+)
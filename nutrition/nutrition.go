@@ -0,0 +1,125 @@
+package nutrition
+
+import (
+	"ares/model"
+	"math"
+	"sort"
+	"time"
+)
+
+// round keeps totals to a single decimal so scaled servings don't return
+// values like 33.300000000000004
+func round(value float64) float64 {
+	return math.Round(value*10) / 10
+}
+
+// Scale returns the macros of a food eaten the provided number of servings
+func Scale(food model.Food, servings float64) model.Macros {
+	return model.Macros{
+		Calories:      round(float64(food.Calories) * servings),
+		Protein:       round(float64(food.Protein) * servings),
+		Carbohydrates: round(float64(food.Carbohydrates) * servings),
+		Fiber:         round(float64(food.Fiber) * servings),
+		Sugar:         round(float64(food.Sugar) * servings),
+		Fat:           round(float64(food.Fat) * servings),
+	}
+}
+
+// Add returns the sum of two macro totals
+func Add(a model.Macros, b model.Macros) model.Macros {
+	return model.Macros{
+		Calories:      round(a.Calories + b.Calories),
+		Protein:       round(a.Protein + b.Protein),
+		Carbohydrates: round(a.Carbohydrates + b.Carbohydrates),
+		Fiber:         round(a.Fiber + b.Fiber),
+		Sugar:         round(a.Sugar + b.Sugar),
+		Fat:           round(a.Fat + b.Fat),
+	}
+}
+
+// Total returns the macros of every provided entry combined
+func Total(entries []model.DietEntry) model.Macros {
+	var total model.Macros
+	for _, entry := range entries {
+		total = Add(total, Scale(entry.Food, entry.Servings))
+	}
+
+	return total
+}
+
+// clock returns the 12-hour clock hour and period of an hour of the day
+func clock(hour int) (uint8, model.TimePeriod) {
+	period := model.AM
+	if hour >= 12 {
+		period = model.PM
+	}
+
+	hour = hour % 12
+	if hour == 0 {
+		hour = 12
+	}
+
+	return uint8(hour), period
+}
+
+// Windows groups diet entries in to one window per hour of the day they were
+// added in, using the provided location. Windows are ordered from the
+// earliest hour and entries within a window by the time they were added
+func Windows(entries []model.DietEntry, location *time.Location) []model.DietWindow {
+	sorted := make([]model.DietEntry, len(entries))
+	copy(sorted, entries)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].AddedAt.Before(sorted[j].AddedAt)
+	})
+
+	var windows []model.DietWindow
+	var start time.Time
+
+	for _, entry := range sorted {
+		addedAt := entry.AddedAt.In(location)
+		hourStart := time.Date(addedAt.Year(), addedAt.Month(), addedAt.Day(), addedAt.Hour(), 0, 0, 0, location)
+
+		if len(windows) == 0 || !hourStart.Equal(start) {
+			hour, period := clock(addedAt.Hour())
+
+			start = hourStart
+			windows = append(windows, model.DietWindow{
+				Date:       hourStart,
+				Hour:       hour,
+				TimePeriod: period,
+			})
+		}
+
+		window := &windows[len(windows)-1]
+		window.Entries = append(window.Entries, entry)
+		window.Totals = Add(window.Totals, Scale(entry.Food, entry.Servings))
+	}
+
+	return windows
+}
+
+// Day returns the diet windows and totals of the day starting at the provided
+// date. Entries outside the day are ignored
+func Day(date time.Time, entries []model.DietEntry, location *time.Location) model.DietDay {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+	end := start.AddDate(0, 0, 1)
+
+	var included []model.DietEntry
+	for _, entry := range entries {
+		if !entry.AddedAt.Before(start) && entry.AddedAt.Before(end) {
+			included = append(included, entry)
+		}
+	}
+
+	windows := Windows(included, location)
+	if windows == nil {
+		windows = []model.DietWindow{}
+	}
+
+	return model.DietDay{
+		Date:    start,
+		Windows: windows,
+		Totals:  Total(included),
+	}
+}
@@ -0,0 +1,116 @@
+package nutrition
+
+import (
+	"ares/model"
+	"errors"
+	"math"
+	"strings"
+)
+
+// grams in one of each weight measurement, used to convert ingredient
+// quantities given in a different weight unit than the food
+var gramsPer = map[model.FoodMeasurement]float64{
+	model.GRAM:     1,
+	model.KILOGRAM: 1000,
+	model.OUNCE:    28.349523125,
+	model.POUND:    453.59237,
+}
+
+// Portions returns how many of the food measurement an ingredient uses, e.g.
+// 250 GRAM of a food measured per 100 GRAM is 2.5 portions
+func Portions(ingredient model.Ingredient, food model.Food) (float64, error) {
+	if food.Measurement.Size == 0 {
+		return 0, errors.New("food " + food.Name + " has no measurement size")
+	}
+
+	unit := ingredient.Unit
+	if unit == "" {
+		unit = food.Measurement.Measurement
+	}
+
+	quantity := ingredient.Quantity
+	if unit != food.Measurement.Measurement {
+		from, fromWeight := gramsPer[unit]
+		to, toWeight := gramsPer[food.Measurement.Measurement]
+
+		if !fromWeight || !toWeight {
+			return 0, errors.New("can't measure " + food.Name + " in " + strings.ToLower(string(unit)))
+		}
+
+		quantity = quantity * from / to
+	}
+
+	return quantity / float64(food.Measurement.Size), nil
+}
+
+// PerServing returns the nutrients of a single serving of a recipe as a food
+// measured in servings. Every ingredient food must be in the provided map
+func PerServing(recipe model.Recipe, foods map[string]model.Food) (model.Food, error) {
+	if recipe.Servings == 0 {
+		return model.Food{}, errors.New("recipe must make at least one serving")
+	}
+
+	var totals [13]float64
+
+	for _, ingredient := range recipe.Ingredients {
+		food, exists := foods[ingredient.Food.Hex()]
+		if !exists {
+			return model.Food{}, errors.New("food " + ingredient.Food.Hex() + " not found")
+		}
+
+		portions, err := Portions(ingredient, food)
+		if err != nil {
+			return model.Food{}, err
+		}
+
+		for i, value := range nutrientValues(food) {
+			totals[i] += float64(value) * portions
+		}
+	}
+
+	perServing := model.Food{
+		Name:        recipe.Name,
+		Verified:    true,
+		Measurement: model.FoodMeasureable{Measurement: model.SERVING, Size: 1},
+	}
+
+	fields := nutrientFields(&perServing)
+	for i, total := range totals {
+		*fields[i] = clamp(total / float64(recipe.Servings))
+	}
+
+	return perServing, nil
+}
+
+// nutrientValues returns every nutrient of a food in a fixed order
+func nutrientValues(food model.Food) [13]uint16 {
+	return [13]uint16{
+		food.Calories, food.Protein, food.Carbohydrates, food.Fiber, food.Sugar,
+		food.Fat, food.Cholesterol, food.Sodium, food.Potassium, food.VitaminA,
+		food.VitaminC, food.Calcium, food.Iron,
+	}
+}
+
+// nutrientFields returns every nutrient field of a food in the same order as
+// nutrientValues
+func nutrientFields(food *model.Food) [13]*uint16 {
+	return [13]*uint16{
+		&food.Calories, &food.Protein, &food.Carbohydrates, &food.Fiber, &food.Sugar,
+		&food.Fat, &food.Cholesterol, &food.Sodium, &food.Potassium, &food.VitaminA,
+		&food.VitaminC, &food.Calcium, &food.Iron,
+	}
+}
+
+// clamp rounds a nutrient in to the range of a food field
+func clamp(value float64) uint16 {
+	rounded := math.Round(value)
+	if rounded <= 0 {
+		return 0
+	}
+
+	if rounded > math.MaxUint16 {
+		return math.MaxUint16
+	}
+
+	return uint16(rounded)
+}
@@ -0,0 +1,199 @@
+package nutrition
+
+import (
+	"ares/model"
+	"ares/units"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	CentimetersPerInch = 2.54
+
+	// default split of calories between macros when a target is derived
+	ProteinShare      = 0.3
+	CarbohydrateShare = 0.4
+	FatShare          = 0.3
+
+	CaloriesPerGramProtein      = 4
+	CaloriesPerGramCarbohydrate = 4
+	CaloriesPerGramFat          = 9
+
+	// dietary guidelines recommend 14g of fiber per 1000 calories
+	FiberPerThousandCalories = 14
+
+	// calorie targets are never derived below this floor
+	MinimumCalories = 1200
+)
+
+// Multiplier returns the factor an activity level scales the basal metabolic
+// rate by
+func Multiplier(level model.ActivityLevel) (float64, error) {
+	switch level {
+	case model.SEDENTARY:
+		return 1.2, nil
+	case model.LIGHTLY_ACTIVE:
+		return 1.375, nil
+	case model.MODERATELY_ACTIVE:
+		return 1.55, nil
+	case model.VERY_ACTIVE:
+		return 1.725, nil
+	case model.EXTRA_ACTIVE:
+		return 1.9, nil
+	}
+
+	return 0, errors.New("unknown activity level " + string(level))
+}
+
+// Age returns the age in whole years on the provided date
+func Age(birthday time.Time, now time.Time) int {
+	age := now.Year() - birthday.Year()
+	if now.Month() < birthday.Month() || (now.Month() == birthday.Month() && now.Day() < birthday.Day()) {
+		age--
+	}
+
+	return age
+}
+
+// BMR returns the basal metabolic rate in calories per day using the
+// Mifflin-St Jeor equation, with weight in kilograms and height in centimeters
+func BMR(sex string, weight float64, height float64, age int) (float64, error) {
+	bmr := 10*weight + 6.25*height - 5*float64(age)
+
+	switch strings.ToLower(sex) {
+	case "male", "m":
+		return bmr + 5, nil
+	case "female", "f":
+		return bmr - 161, nil
+	}
+
+	return 0, errors.New("sex must be male or female to estimate a target")
+}
+
+// TDEE returns the total daily energy expenditure of the biometrics at the
+// activity level. Weight and height are read in the provided measurement
+// system, kilograms and centimeters for metric and pounds and inches for
+// imperial
+func TDEE(biometrics model.Biometrics, system model.MeasurementSystem, level model.ActivityLevel, now time.Time) (float64, error) {
+	if biometrics.Weight <= 0 || biometrics.Height <= 0 || biometrics.Birthday.IsZero() {
+		return 0, errors.New("weight, height and birthday are required to estimate a target")
+	}
+
+	multiplier, err := Multiplier(level)
+	if err != nil {
+		return 0, err
+	}
+
+	weight := units.ToKilograms(float64(biometrics.Weight), system)
+	height := float64(biometrics.Height)
+	if system == model.IMPERIAL {
+		height *= CentimetersPerInch
+	}
+
+	bmr, err := BMR(biometrics.Sex, weight, height, Age(biometrics.Birthday, now))
+	if err != nil {
+		return 0, err
+	}
+
+	return bmr * multiplier, nil
+}
+
+// Split divides a calorie target between protein, carbohydrates and fat using
+// the default shares, and sets the fiber target for the calories
+func Split(calories float64) model.Macros {
+	return model.Macros{
+		Calories:      math.Round(calories),
+		Protein:       math.Round(calories * ProteinShare / CaloriesPerGramProtein),
+		Carbohydrates: math.Round(calories * CarbohydrateShare / CaloriesPerGramCarbohydrate),
+		Fat:           math.Round(calories * FatShare / CaloriesPerGramFat),
+		Fiber:         math.Round(calories / 1000 * FiberPerThousandCalories),
+	}
+}
+
+// Derive returns a target for the account from its biometrics, the activity
+// level and a calorie adjustment applied to the TDEE, e.g. -500 for a deficit
+func Derive(account model.Account, level model.ActivityLevel, adjustment float64, now time.Time) (model.NutritionTarget, error) {
+	tdee, err := TDEE(account.Biometrics, account.Preferences.Units.MeasurementSystem, level, now)
+	if err != nil {
+		return model.NutritionTarget{}, err
+	}
+
+	calories := tdee + adjustment
+	if calories < MinimumCalories {
+		calories = MinimumCalories
+	}
+
+	macros := Split(calories)
+
+	return model.NutritionTarget{
+		Account:       account.ID,
+		Calories:      macros.Calories,
+		Protein:       macros.Protein,
+		Carbohydrates: macros.Carbohydrates,
+		Fat:           macros.Fat,
+		Fiber:         macros.Fiber,
+		Derived:       true,
+		ActivityLevel: level,
+		Adjustment:    adjustment,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// Scaled returns the macros multiplied by a number of days
+func Scaled(macros model.Macros, days int) model.Macros {
+	n := float64(days)
+
+	return model.Macros{
+		Calories:      round(macros.Calories * n),
+		Protein:       round(macros.Protein * n),
+		Carbohydrates: round(macros.Carbohydrates * n),
+		Fiber:         round(macros.Fiber * n),
+		Sugar:         round(macros.Sugar * n),
+		Fat:           round(macros.Fat * n),
+	}
+}
+
+// remaining returns what is left of a target after the logged amount, or zero
+// if the nutrient has no target
+func remaining(target float64, logged float64) float64 {
+	if target == 0 {
+		return 0
+	}
+
+	return round(target - logged)
+}
+
+// Summarize compares the entries logged in the days starting at from against
+// the daily target, with a daily breakdown when more than one day is included
+func Summarize(from time.Time, days int, entries []model.DietEntry, target model.Macros, location *time.Location) model.NutritionSummary {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+
+	summary := model.NutritionSummary{
+		From:   start,
+		To:     start.AddDate(0, 0, days),
+		Days:   days,
+		Target: Scaled(target, days),
+	}
+
+	for i := 0; i < days; i++ {
+		day := Day(start.AddDate(0, 0, i), entries, location)
+		summary.Logged = Add(summary.Logged, day.Totals)
+
+		if days > 1 {
+			summary.Daily = append(summary.Daily, Summarize(day.Date, 1, entries, target, location))
+		}
+	}
+
+	summary.Remaining = model.Macros{
+		Calories:      remaining(summary.Target.Calories, summary.Logged.Calories),
+		Protein:       remaining(summary.Target.Protein, summary.Logged.Protein),
+		Carbohydrates: remaining(summary.Target.Carbohydrates, summary.Logged.Carbohydrates),
+		Fiber:         remaining(summary.Target.Fiber, summary.Logged.Fiber),
+		Sugar:         remaining(summary.Target.Sugar, summary.Logged.Sugar),
+		Fat:           remaining(summary.Target.Fat, summary.Logged.Fat),
+	}
+
+	return summary
+}
@@ -0,0 +1,122 @@
+package programs
+
+import (
+	"ares/model"
+	"ares/units"
+	"math"
+	"sort"
+	"time"
+)
+
+// ScheduledDay is a program day resolved to the calendar date it falls on
+// for an enrollment. Week is one based
+type ScheduledDay struct {
+	Week int              `json:"week"`
+	Day  model.ProgramDay `json:"day"`
+	Date time.Time        `json:"date"`
+}
+
+// dayStart truncates a time to midnight in its own location
+func dayStart(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// DayKey identifies a day of a program by its one based week and its day
+type DayKey struct {
+	Week int
+	Day  uint8
+}
+
+// Next returns the first day of the program scheduled on or after the day
+// containing now that is not in started, or false if every scheduled day has
+// already passed or been started
+func Next(program model.Program, startDate time.Time, now time.Time, started map[DayKey]bool) (ScheduledDay, bool) {
+	start := dayStart(startDate)
+	today := dayStart(now.In(start.Location()))
+
+	for weekIndex, week := range program.Weeks {
+		days := make([]model.ProgramDay, len(week.Days))
+		copy(days, week.Days)
+
+		sort.Slice(days, func(i, j int) bool {
+			return days[i].Day < days[j].Day
+		})
+
+		for _, day := range days {
+			date := start.AddDate(0, 0, weekIndex*7+int(day.Day)-1)
+
+			if !date.Before(today) && !started[DayKey{Week: weekIndex + 1, Day: day.Day}] {
+				return ScheduledDay{Week: weekIndex + 1, Day: day, Date: date}, true
+			}
+		}
+	}
+
+	return ScheduledDay{}, false
+}
+
+// Load returns the weight for a percentage of a one rep max in kilograms,
+// rounded to the nearest 2.5kg or 5lb so that it can be loaded on a bar
+func Load(oneRepMax float64, percentage float64, system model.MeasurementSystem) model.ExerciseValueWeight {
+	if system == "" {
+		system = model.METRIC
+	}
+
+	increment := 2.5
+	if system == model.IMPERIAL {
+		increment = 5
+	}
+
+	value := units.FromKilograms(oneRepMax*percentage/100, system)
+
+	return units.CanonicalizeWeight(model.ExerciseValueWeight{
+		Value:       float32(math.Round(value/increment) * increment),
+		Measurement: system,
+	})
+}
+
+// ApplyLoads returns a copy of the template with the loads of the program day
+// applied to its exercises. One rep maxes are in kilograms keyed by exercise
+// name, exercises loaded by percentage without a one rep max keep the template
+// weight and are returned by name
+func ApplyLoads(
+	template model.WorkoutTemplate,
+	day model.ProgramDay,
+	oneRepMaxes map[string]float64,
+	system model.MeasurementSystem,
+) (model.WorkoutTemplate, []string) {
+	var missing []string
+
+	exercises := make([]model.TemplateExercise, len(template.Exercises))
+	copy(exercises, template.Exercises)
+
+	for i, exercise := range exercises {
+		for _, load := range day.Loads {
+			if load.Exercise != exercise.Exercise {
+				continue
+			}
+
+			if load.Sets != 0 {
+				exercises[i].Sets = load.Sets
+			}
+
+			if load.Reps != 0 {
+				exercises[i].Reps = load.Reps
+			}
+
+			if load.Percentage > 0 {
+				oneRepMax, exists := oneRepMaxes[exercise.ExerciseName]
+				if !exists {
+					missing = append(missing, exercise.ExerciseName)
+					continue
+				}
+
+				exercises[i].Weight = Load(oneRepMax, load.Percentage, system)
+			}
+		}
+	}
+
+	template.Exercises = exercises
+
+	return template, missing
+}
@@ -0,0 +1,47 @@
+package programs
+
+import (
+	"ares/model"
+	"testing"
+	"time"
+)
+
+func TestNextSkipsStartedDays(t *testing.T) {
+	program := model.Program{Weeks: []model.ProgramWeek{
+		{Days: []model.ProgramDay{{Day: 3}, {Day: 1}}},
+		{Days: []model.ProgramDay{{Day: 1}}},
+	}}
+
+	start := time.Date(2022, 6, 6, 0, 0, 0, 0, time.UTC)
+	now := start.Add(9 * time.Hour)
+
+	tests := []struct {
+		started map[DayKey]bool
+		week    int
+		day     uint8
+		date    time.Time
+		found   bool
+	}{
+		{nil, 1, 1, start, true},
+		{map[DayKey]bool{{Week: 1, Day: 1}: true}, 1, 3, start.AddDate(0, 0, 2), true},
+		{map[DayKey]bool{{Week: 1, Day: 1}: true, {Week: 1, Day: 3}: true}, 2, 1, start.AddDate(0, 0, 7), true},
+		{map[DayKey]bool{{Week: 1, Day: 1}: true, {Week: 1, Day: 3}: true, {Week: 2, Day: 1}: true}, 0, 0, time.Time{}, false},
+	}
+
+	for _, test := range tests {
+		scheduled, found := Next(program, start, now, test.started)
+		if found != test.found {
+			t.Errorf("found = %t, want %t", found, test.found)
+			continue
+		}
+
+		if !found {
+			continue
+		}
+
+		if scheduled.Week != test.week || scheduled.Day.Day != test.day || !scheduled.Date.Equal(test.date) {
+			t.Errorf("next = week %d day %d on %s, want week %d day %d on %s",
+				scheduled.Week, scheduled.Day.Day, scheduled.Date, test.week, test.day, test.date)
+		}
+	}
+}
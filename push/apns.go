@@ -0,0 +1,183 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	apnsProductionHost  = "https://api.push.apple.com"
+	apnsDevelopmentHost = "https://api.sandbox.push.apple.com"
+
+	// Apple rejects provider tokens older than an hour and refreshing them
+	// more than once every 20 minutes
+	apnsTokenLifetime = 40 * time.Minute
+)
+
+// APNsProvider sends pushes through the Apple Push Notification service using token
+// based authentication. Requests are made over HTTP/2, which the default
+// transport negotiates with Apple
+type APNsProvider struct {
+	KeyID      string
+	TeamID     string
+	Topic      string
+	Key        *ecdsa.PrivateKey
+	Production bool
+	Client     *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNs returns an APNs provider signing its tokens with the .p8 key
+// downloaded from the Apple developer account. The topic is the bundle id of
+// the app
+func NewAPNs(keyID string, teamID string, topic string, key []byte, production bool) (*APNsProvider, error) {
+	privateKey, err := jwt.ParseECPrivateKeyFromPEM(key)
+	if err != nil {
+		return nil, errors.New("failed to parse apns key: " + err.Error())
+	}
+
+	return &APNsProvider{
+		KeyID:      keyID,
+		TeamID:     teamID,
+		Topic:      topic,
+		Key:        privateKey,
+		Production: production,
+		Client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (provider *APNsProvider) Name() string {
+	return APNS
+}
+
+// bearer returns the provider token, signing a new one once the current
+// token reaches its lifetime
+func (provider *APNsProvider) bearer(now time.Time) (string, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if provider.token != "" && now.Sub(provider.issuedAt) < apnsTokenLifetime {
+		return provider.token, nil
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Issuer:   provider.TeamID,
+		IssuedAt: jwt.NewNumericDate(now),
+	})
+	token.Header["kid"] = provider.KeyID
+
+	signed, err := token.SignedString(provider.Key)
+	if err != nil {
+		return "", err
+	}
+
+	provider.token = signed
+	provider.issuedAt = now
+
+	return signed, nil
+}
+
+// expire drops the provider token so the next request signs a new one
+func (provider *APNsProvider) expire() {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	provider.token = ""
+}
+
+func (provider *APNsProvider) Send(ctx context.Context, messages []Message) []Result {
+	return sendAll(ctx, messages, 8, provider.send)
+}
+
+func (provider *APNsProvider) send(ctx context.Context, message Message) Result {
+	result := Result{Token: message.Token}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": message.Title, "body": message.Body},
+			"sound": "default",
+		},
+	}
+
+	for key, value := range message.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	host := apnsDevelopmentHost
+	if provider.Production {
+		host = apnsProductionHost
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, host+"/3/device/"+message.Token, bytes.NewReader(body))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	bearer, err := provider.bearer(time.Now())
+	if err != nil {
+		result.Err = errors.New("failed to sign apns token: " + err.Error())
+		result.Retry = true
+		return result
+	}
+
+	request.Header.Set("authorization", "bearer "+bearer)
+	request.Header.Set("apns-topic", provider.Topic)
+	request.Header.Set("apns-push-type", "alert")
+	request.Header.Set("content-type", "application/json")
+
+	response, err := provider.Client.Do(request)
+	if err != nil {
+		result.Err = err
+		result.Retry = true
+		return result
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusOK {
+		return result
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+
+	_ = json.NewDecoder(response.Body).Decode(&failure)
+	result.Err = fmt.Errorf("apns returned %d: %s", response.StatusCode, failure.Reason)
+
+	switch {
+	case response.StatusCode == http.StatusGone,
+		failure.Reason == "BadDeviceToken",
+		failure.Reason == "Unregistered",
+		failure.Reason == "DeviceTokenNotForTopic":
+		result.Invalid = true
+	case failure.Reason == "ExpiredProviderToken", failure.Reason == "InvalidProviderToken":
+		provider.expire()
+		result.Retry = true
+	case response.StatusCode == http.StatusTooManyRequests, response.StatusCode >= 500:
+		result.Retry = true
+	}
+
+	return result
+}
@@ -0,0 +1,99 @@
+package push
+
+import (
+	"ares/database"
+	"ares/model"
+	"ares/notification"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// content returns the title and body shown on the device for a notification
+func content(n notification.Notification, actor string) (string, string) {
+	switch n.Type {
+	case notification.NEW_FOLLOWER:
+		return "New follower", actor + " started following you"
+	case notification.FOLLOW_REQUEST:
+		return "Follow request", actor + " wants to follow you"
+	case notification.FOLLOW_ACCEPTED:
+		return "Follow request accepted", actor + " accepted your follow request"
+	case notification.NEW_LIKE:
+		return "New like", actor + " liked your post"
+	case notification.NEW_COMMENT:
+		if n.Text != "" {
+			return actor + " commented", n.Text
+		}
+
+		return "New comment", actor + " commented on your post"
+	case notification.ASSIGNED_SESSION:
+		return "New session", actor + " assigned you a session"
+	case notification.ASSIGNED_MEAL:
+		return "New meal plan", actor + " assigned you a meal plan"
+	case notification.NEW_MESSAGE:
+		if n.Text != "" {
+			return actor, n.Text
+		}
+
+		return "New message", actor + " sent you an attachment"
+	}
+
+	return "Training Club", "You have a new notification"
+}
+
+// Dispatcher returns a notification dispatcher queueing a push to every
+// device token registered by the recipient
+func Dispatcher(queue Queue, mongoClient *mongo.Client, databaseName string) notification.Dispatcher {
+	return func(n notification.Notification, recipient model.Account) error {
+		tokens, err := database.FindManyDocumentsByFilter[model.DeviceToken](database.QueryParams{
+			MongoClient:    mongoClient,
+			DatabaseName:   databaseName,
+			CollectionName: "device_token",
+		}, bson.M{"account": recipient.ID})
+
+		if err != nil {
+			return err
+		}
+
+		if len(tokens) == 0 {
+			return nil
+		}
+
+		actorName := "Someone"
+
+		actor, err := database.FindDocumentById[model.Account](database.QueryParams{
+			MongoClient:    mongoClient,
+			DatabaseName:   databaseName,
+			CollectionName: "account",
+		}, n.Actor.Hex())
+
+		if err == nil && actor.Username != "" {
+			actorName = actor.Username
+		}
+
+		title, body := content(n, actorName)
+
+		data := map[string]string{
+			"notification": n.ID.Hex(),
+			"type":         string(n.Type),
+		}
+
+		if !n.Subject.IsZero() {
+			data["subject"] = n.Subject.Hex()
+		}
+
+		messages := make([]Message, len(tokens))
+
+		for i, token := range tokens {
+			messages[i] = Message{
+				Token:    token.Token,
+				Provider: token.Provider,
+				Title:    title,
+				Body:     body,
+				Data:     data,
+			}
+		}
+
+		return queue.Enqueue(messages...)
+	}
+}
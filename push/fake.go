@@ -0,0 +1,77 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Fake accepts the messages it is asked to send without delivering them, for
+// local development and tests. Tokens marked invalid are reported as no
+// longer registered
+type Fake struct {
+	ProviderName string
+	// Record keeps every sent message for Sent. Meant for tests, a long
+	// running server would hold on to every message
+	Record bool
+	// Log prints a line for every sent message, leaving out the token and
+	// the content
+	Log bool
+
+	mu      sync.Mutex
+	sent    []Message
+	invalid map[string]bool
+}
+
+// NewFake returns a fake provider standing in for the named provider
+func NewFake(name string, record bool, log bool) *Fake {
+	return &Fake{ProviderName: name, Record: record, Log: log, invalid: map[string]bool{}}
+}
+
+func (provider *Fake) Name() string {
+	return provider.ProviderName
+}
+
+// Invalidate marks a token as no longer registered
+func (provider *Fake) Invalidate(token string) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	provider.invalid[token] = true
+}
+
+// Sent returns every message sent so far, nothing unless Record is set
+func (provider *Fake) Sent() []Message {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	return append([]Message{}, provider.sent...)
+}
+
+func (provider *Fake) Send(ctx context.Context, messages []Message) []Result {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	results := make([]Result, len(messages))
+
+	for i, message := range messages {
+		results[i].Token = message.Token
+
+		if provider.invalid[message.Token] {
+			results[i].Err = errors.New("token is not registered")
+			results[i].Invalid = true
+			continue
+		}
+
+		if provider.Record {
+			provider.sent = append(provider.sent, message)
+		}
+
+		if provider.Log {
+			fmt.Printf("push via %s: %s notification\n", provider.ProviderName, message.Data["type"])
+		}
+	}
+
+	return results
+}
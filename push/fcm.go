@@ -0,0 +1,228 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+// FCMProvider sends pushes through the Firebase Cloud Messaging HTTP v1 API,
+// authenticating as a service account
+type FCMProvider struct {
+	ProjectID   string
+	ClientEmail string
+	TokenURL    string
+	Key         *rsa.PrivateKey
+	Client      *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCM returns an FCM provider for the service account credentials file
+// downloaded from the Firebase console
+func NewFCM(credentials []byte) (*FCMProvider, error) {
+	var account struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+
+	err := json.Unmarshal(credentials, &account)
+	if err != nil {
+		return nil, errors.New("failed to parse fcm credentials: " + err.Error())
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, errors.New("failed to parse fcm key: " + err.Error())
+	}
+
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	return &FCMProvider{
+		ProjectID:   account.ProjectID,
+		ClientEmail: account.ClientEmail,
+		TokenURL:    account.TokenURI,
+		Key:         privateKey,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (provider *FCMProvider) Name() string {
+	return FCM
+}
+
+// token returns an OAuth access token for the service account, exchanging a
+// signed assertion for a new one shortly before the current token expires
+func (provider *FCMProvider) token(ctx context.Context) (string, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	now := time.Now()
+	if provider.accessToken != "" && now.Before(provider.expiresAt) {
+		return provider.accessToken, nil
+	}
+
+	assertion := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   provider.ClientEmail,
+		"scope": fcmScope,
+		"aud":   provider.TokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+
+	signed, err := assertion.SignedString(provider.Key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {signed},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("content-type", "application/x-www-form-urlencoded")
+
+	response, err := provider.Client.Do(request)
+	if err != nil {
+		return "", err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token exchange returned %d", response.StatusCode)
+	}
+
+	var grant struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+
+	err = json.NewDecoder(response.Body).Decode(&grant)
+	if err != nil {
+		return "", err
+	}
+
+	provider.accessToken = grant.AccessToken
+	provider.expiresAt = now.Add(time.Duration(grant.ExpiresIn)*time.Second - time.Minute)
+
+	return provider.accessToken, nil
+}
+
+// expire drops the access token so the next request exchanges a new one
+func (provider *FCMProvider) expire() {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	provider.accessToken = ""
+}
+
+// Send sends every message on its own request, the v1 API has no batch
+// endpoint
+func (provider *FCMProvider) Send(ctx context.Context, messages []Message) []Result {
+	return sendAll(ctx, messages, 8, provider.send)
+}
+
+func (provider *FCMProvider) send(ctx context.Context, message Message) Result {
+	result := Result{Token: message.Token}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        message.Token,
+			"notification": map[string]string{"title": message.Title, "body": message.Body},
+			"data":         message.Data,
+		},
+	})
+
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	accessToken, err := provider.token(ctx)
+	if err != nil {
+		result.Err = errors.New("failed to authenticate with fcm: " + err.Error())
+		result.Retry = true
+		return result
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(fcmEndpoint, provider.ProjectID), bytes.NewReader(body))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	request.Header.Set("authorization", "Bearer "+accessToken)
+	request.Header.Set("content-type", "application/json")
+
+	response, err := provider.Client.Do(request)
+	if err != nil {
+		result.Err = err
+		result.Retry = true
+		return result
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusOK {
+		return result
+	}
+
+	var failure struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+
+	_ = json.NewDecoder(response.Body).Decode(&failure)
+	result.Err = fmt.Errorf("fcm returned %d: %s", response.StatusCode, failure.Error.Message)
+
+	errorCode := failure.Error.Status
+	for _, detail := range failure.Error.Details {
+		if detail.ErrorCode != "" {
+			errorCode = detail.ErrorCode
+		}
+	}
+
+	switch {
+	case response.StatusCode == http.StatusNotFound, errorCode == "UNREGISTERED":
+		result.Invalid = true
+	case response.StatusCode == http.StatusUnauthorized:
+		provider.expire()
+		result.Retry = true
+	case response.StatusCode == http.StatusTooManyRequests, response.StatusCode >= 500:
+		result.Retry = true
+	}
+
+	return result
+}
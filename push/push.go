@@ -0,0 +1,77 @@
+package push
+
+import (
+	"ares/model"
+	"context"
+	"sync"
+)
+
+const (
+	APNS = "apns"
+	FCM  = "fcm"
+)
+
+// Message is a single push to a device token. Data is delivered to the app
+// alongside the visible title and body
+type Message struct {
+	Token    string            `json:"token"`
+	Provider string            `json:"provider"`
+	Title    string            `json:"title"`
+	Body     string            `json:"body"`
+	Data     map[string]string `json:"data,omitempty"`
+}
+
+// Result is the outcome of sending a message. Invalid tokens are no longer
+// registered with the provider and should be removed, failed messages that
+// can be retried are marked as such
+type Result struct {
+	Token   string
+	Err     error
+	Invalid bool
+	Retry   bool
+}
+
+// Provider sends messages through a push service. Send returns a result for
+// every message, in the order the messages were provided
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, messages []Message) []Result
+}
+
+// ProviderFor returns the push provider tokens of the platform are sent with
+func ProviderFor(platform model.DevicePlatform) (string, bool) {
+	switch platform {
+	case model.IOS:
+		return APNS, true
+	case model.ANDROID, model.WEB:
+		return FCM, true
+	}
+
+	return "", false
+}
+
+// sendAll sends every message with send, at most concurrency at a time
+func sendAll(ctx context.Context, messages []Message, concurrency int, send func(ctx context.Context, message Message) Result) []Result {
+	results := make([]Result, len(messages))
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for i, message := range messages {
+		slots <- struct{}{}
+		wg.Add(1)
+
+		go func(i int, message Message) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			results[i] = send(ctx, message)
+		}(i, message)
+	}
+
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,139 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/google/uuid"
+)
+
+const (
+	queueKey = "push:queue"
+	retryKey = "push:retry"
+)
+
+// Job is a message waiting in the queue along with the number of times it has
+// been attempted
+type Job struct {
+	ID       string  `json:"id"`
+	Message  Message `json:"message"`
+	Attempts int     `json:"attempts"`
+}
+
+// Queue holds messages waiting to be delivered in Redis, so pushes survive a
+// restart and every instance of the server can share the delivery work.
+// Messages ready to be sent are kept in a list and messages waiting to be
+// retried in a sorted set, scored by when they are due
+type Queue struct {
+	RedisClient *redis.Client
+}
+
+// Enqueue adds messages to the queue
+func (queue Queue) Enqueue(messages ...Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, len(messages))
+
+	for i, message := range messages {
+		job, err := json.Marshal(Job{ID: uuid.NewString(), Message: message})
+		if err != nil {
+			return err
+		}
+
+		values[i] = job
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	return queue.RedisClient.LPush(ctx, queueKey, values...).Err()
+}
+
+// Pop removes and returns up to count jobs from the queue, oldest first
+func (queue Queue) Pop(count int) ([]Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	values, err := queue.RedisClient.RPopCount(ctx, queueKey, count).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]Job, 0, len(values))
+
+	for _, value := range values {
+		var job Job
+
+		// a job that cannot be decoded can never be delivered, drop it rather
+		// than blocking the queue
+		if json.Unmarshal([]byte(value), &job) != nil {
+			continue
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// Retry schedules a job to be put back on the queue at the provided time
+func (queue Queue) Retry(job Job, at time.Time) error {
+	value, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	return queue.RedisClient.ZAdd(ctx, retryKey, redis.Z{Score: float64(at.Unix()), Member: value}).Err()
+}
+
+// PromoteDue moves the jobs due to be retried by now back on the queue and
+// returns how many were moved. A job is only moved by the instance that
+// removed it from the retry set, so concurrent workers never duplicate one
+func (queue Queue) PromoteDue(now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	due, err := queue.RedisClient.ZRangeByScore(ctx, retryKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+
+	if err != nil {
+		return 0, err
+	}
+
+	promoted := 0
+
+	for _, value := range due {
+		removed, err := queue.RedisClient.ZRem(ctx, retryKey, value).Result()
+		if err != nil {
+			return promoted, err
+		}
+
+		if removed == 0 {
+			continue
+		}
+
+		err = queue.RedisClient.LPush(ctx, queueKey, value).Err()
+		if err != nil {
+			return promoted, err
+		}
+
+		promoted++
+	}
+
+	return promoted, nil
+}
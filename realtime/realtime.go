@@ -0,0 +1,117 @@
+package realtime
+
+import (
+	"ares/model"
+	"ares/notification"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event is pushed to every open stream of an account. Data holds the document
+// the event is about, e.g. the new message or notification
+type Event struct {
+	Type      EventType   `json:"type"`
+	Data      interface{} `json:"data,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+type EventType string
+
+const (
+	MESSAGE         EventType = "message"
+	NOTIFICATION    EventType = "notification"
+	LIKE            EventType = "like"
+	COMMENT         EventType = "comment"
+	SESSION_COMMENT EventType = "session_comment"
+)
+
+var ErrNotConfigured = errors.New("realtime events are not configured")
+
+// events are relayed through Redis pub/sub so a stream served by one
+// instance receives events published by any other
+var (
+	clientMu    sync.RWMutex
+	redisClient *redis.Client
+)
+
+// Configure sets the Redis client events are published and received through.
+// Publishing is a no-op until a client is configured
+func Configure(client *redis.Client) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+
+	redisClient = client
+}
+
+func client() *redis.Client {
+	clientMu.RLock()
+	defer clientMu.RUnlock()
+
+	return redisClient
+}
+
+func channel(accountId primitive.ObjectID) string {
+	return "realtime:" + accountId.Hex()
+}
+
+// Publish sends an event to every open stream of the recipient. Events are
+// not stored, recipients without an open stream never receive them
+func Publish(recipient primitive.ObjectID, eventType EventType, data interface{}) error {
+	rdb := client()
+	if rdb == nil || recipient.IsZero() {
+		return nil
+	}
+
+	payload, err := json.Marshal(Event{Type: eventType, Data: data, CreatedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	return rdb.Publish(ctx, channel(recipient), payload).Err()
+}
+
+// Subscription receives the events published to an account until closed.
+// The payload of every message is a JSON encoded Event
+type Subscription struct {
+	pubsub *redis.PubSub
+	Events <-chan *redis.Message
+}
+
+// Subscribe opens a subscription to the events of an account
+func Subscribe(ctx context.Context, accountId primitive.ObjectID) (*Subscription, error) {
+	rdb := client()
+	if rdb == nil {
+		return nil, ErrNotConfigured
+	}
+
+	pubsub := rdb.Subscribe(ctx, channel(accountId))
+
+	// wait for the subscription to be confirmed so no event published after
+	// Subscribe returns is missed
+	_, err := pubsub.Receive(ctx)
+	if err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	return &Subscription{pubsub: pubsub, Events: pubsub.Channel()}, nil
+}
+
+func (subscription *Subscription) Close() error {
+	return subscription.pubsub.Close()
+}
+
+// NotificationDispatcher is a notification dispatcher publishing every new
+// notification to the open streams of its recipient
+func NotificationDispatcher(n notification.Notification, recipient model.Account) error {
+	return Publish(recipient.ID, NOTIFICATION, n)
+}
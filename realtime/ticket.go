@@ -0,0 +1,86 @@
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+)
+
+// TicketTTL is how long a stream ticket can be redeemed after it is issued
+const TicketTTL = 30 * time.Second
+
+var ErrInvalidTicket = errors.New("stream ticket is invalid or was already used")
+
+func ticketKey(ticket string) string {
+	return "realtime:ticket:" + ticket
+}
+
+// IssueTicket returns a single use ticket opening a stream of the account.
+// Browsers can't set headers on WebSocket and EventSource requests, a ticket
+// is sent in the query string instead of the access token so the token never
+// ends up in request logs. Streams opened with the ticket close at expiresAt,
+// the expiry of the access token it was issued for
+func IssueTicket(accountId string, expiresAt time.Time) (string, error) {
+	rdb := client()
+	if rdb == nil {
+		return "", ErrNotConfigured
+	}
+
+	buf := make([]byte, 32)
+
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	ticket := hex.EncodeToString(buf)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	err = rdb.Set(ctx, ticketKey(ticket), accountId+" "+strconv.FormatInt(expiresAt.Unix(), 10), TicketTTL).Err()
+	if err != nil {
+		return "", err
+	}
+
+	return ticket, nil
+}
+
+// RedeemTicket returns the account and expiry a ticket was issued for. The
+// ticket is removed, so it can't be redeemed again
+func RedeemTicket(ticket string) (string, time.Time, error) {
+	rdb := client()
+	if rdb == nil {
+		return "", time.Time{}, ErrNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	value, err := rdb.GetDel(ctx, ticketKey(ticket)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", time.Time{}, ErrInvalidTicket
+		}
+
+		return "", time.Time{}, err
+	}
+
+	accountId, expiry, found := strings.Cut(value, " ")
+	if !found {
+		return "", time.Time{}, ErrInvalidTicket
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", time.Time{}, ErrInvalidTicket
+	}
+
+	return accountId, time.Unix(expiresAt, 0), nil
+}
@@ -0,0 +1,35 @@
+package records
+
+import "ares/model"
+
+// brzyckiRepLimit is the rep count at which the Brzycki formula stops being
+// reliable, sets above it are estimated with the Epley formula instead
+const brzyckiRepLimit = 10
+
+// Epley estimates a one rep max using the Epley formula
+func Epley(weight float64, reps uint8) float64 {
+	if reps <= 1 {
+		return weight
+	}
+
+	return weight * (1 + float64(reps)/30)
+}
+
+// Brzycki estimates a one rep max using the Brzycki formula
+func Brzycki(weight float64, reps uint8) float64 {
+	if reps <= 1 {
+		return weight
+	}
+
+	return weight * 36 / (37 - float64(reps))
+}
+
+// EstimateOneRepMax estimates a one rep max for the provided set, using
+// Brzycki for low rep sets where it is more accurate and Epley otherwise
+func EstimateOneRepMax(weight float64, reps uint8) (float64, model.OneRepMaxFormula) {
+	if reps <= brzyckiRepLimit {
+		return Brzycki(weight, reps), model.BRZYCKI
+	}
+
+	return Epley(weight, reps), model.EPLEY
+}
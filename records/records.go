@@ -0,0 +1,266 @@
+package records
+
+import (
+	"ares/database"
+	"ares/model"
+	"ares/units"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type candidateKey struct {
+	exerciseName string
+	recordType   model.PersonalRecordType
+	bracket      float64
+}
+
+// isBetter returns true if value beats the current best for the record type,
+// times are the only record type where lower is better
+func isBetter(recordType model.PersonalRecordType, value float64, best float64) bool {
+	if recordType == model.FASTEST_TIME {
+		return value < best
+	}
+
+	return value > best
+}
+
+// countedSets returns the sets that should be considered for records.
+//
+// Warmups never count. Sessions logged after the fact usually do not flag
+// individual sets as completed, so if no set in the session is flagged every
+// working set counts, otherwise only completed sets do
+func countedSets(sets []model.ExerciseSet, requireCompleted bool) []model.ExerciseSet {
+	var counted []model.ExerciseSet
+
+	for _, set := range sets {
+		if set.Warmup || (requireCompleted && !set.Completed) {
+			continue
+		}
+
+		counted = append(counted, set)
+	}
+
+	return counted
+}
+
+// hasCompletedSets returns true if any set in the session is flagged as completed
+func hasCompletedSets(session model.Session) bool {
+	for _, exercise := range session.Exercises {
+		for _, set := range exercise.Sets {
+			if set.Completed {
+				return true
+			}
+		}
+
+		for _, additional := range exercise.AdditionalExercise {
+			for _, set := range additional.Sets {
+				if set.Completed {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// Detect returns the best result of every record type for every exercise
+// in the provided session. These are only candidates, they still need to
+// be compared against the records already held by the session author
+func Detect(session model.Session) []model.PersonalRecord {
+	best := map[candidateKey]model.PersonalRecord{}
+	var order []candidateKey
+
+	offer := func(record model.PersonalRecord) {
+		key := candidateKey{record.ExerciseName, record.Type, record.Bracket}
+		current, exists := best[key]
+
+		if !exists {
+			order = append(order, key)
+		}
+
+		if !exists || isBetter(record.Type, record.Value, current.Value) {
+			best[key] = record
+		}
+	}
+
+	requireCompleted := hasCompletedSets(session)
+
+	detectSets := func(exerciseName string, sets []model.ExerciseSet) {
+		for _, set := range countedSets(sets, requireCompleted) {
+			achievedAt := set.CompletedAt
+			if achievedAt.IsZero() {
+				achievedAt = session.Timestamp
+			}
+
+			base := model.PersonalRecord{
+				Account:      session.Author,
+				ExerciseName: exerciseName,
+				Session:      session.ID,
+				AchievedAt:   achievedAt,
+			}
+
+			weight := units.Kilograms(set.Weight)
+			distance := units.Meters(set.Distance)
+
+			if weight > 0 && set.Reps > 0 {
+				maxWeight := base
+				maxWeight.Type = model.MAX_WEIGHT
+				maxWeight.Value = weight
+				maxWeight.Weight = weight
+				maxWeight.Reps = set.Reps
+				offer(maxWeight)
+
+				estimated, formula := EstimateOneRepMax(weight, set.Reps)
+				oneRepMax := base
+				oneRepMax.Type = model.ESTIMATED_ONE_REP_MAX
+				oneRepMax.Value = units.Round(estimated)
+				oneRepMax.Formula = formula
+				oneRepMax.Weight = weight
+				oneRepMax.Reps = set.Reps
+				offer(oneRepMax)
+
+				maxReps := base
+				maxReps.Type = model.MAX_REPS
+				maxReps.Bracket = weight
+				maxReps.Value = float64(set.Reps)
+				maxReps.Weight = weight
+				maxReps.Reps = set.Reps
+				offer(maxReps)
+			}
+
+			if distance > 0 {
+				longest := base
+				longest.Type = model.LONGEST_DISTANCE
+				longest.Value = distance
+				longest.Distance = distance
+				longest.Time = set.Time.Value
+				offer(longest)
+
+				if set.Time.Value > 0 {
+					fastest := base
+					fastest.Type = model.FASTEST_TIME
+					fastest.Bracket = distance
+					fastest.Value = float64(set.Time.Value)
+					fastest.Distance = distance
+					fastest.Time = set.Time.Value
+					offer(fastest)
+				}
+			}
+		}
+	}
+
+	for _, exercise := range session.Exercises {
+		detectSets(exercise.ExerciseName, exercise.Sets)
+
+		for _, additional := range exercise.AdditionalExercise {
+			detectSets(additional.ExerciseName, additional.Sets)
+		}
+	}
+
+	records := make([]model.PersonalRecord, 0, len(order))
+	for _, key := range order {
+		records = append(records, best[key])
+	}
+
+	return records
+}
+
+// SaveCompletedSession detects the records set in a completed session and
+// stores every candidate that beats the record currently held by the author.
+//
+// Each candidate is written with a single conditional upsert, if the stored
+// record is already better the upsert collides with the unique record index
+// and the candidate is discarded. The new records are returned along with
+// the value they replaced
+func SaveCompletedSession(mongoClient *mongo.Client, databaseName string, session model.Session) ([]model.PersonalRecord, error) {
+	dbQueryParams := database.QueryParams{
+		MongoClient:    mongoClient,
+		DatabaseName:   databaseName,
+		CollectionName: "personal_record",
+	}
+
+	var saved []model.PersonalRecord
+
+	for _, candidate := range Detect(session) {
+		comparison := "$lt"
+		if candidate.Type == model.FASTEST_TIME {
+			comparison = "$gt"
+		}
+
+		filter := bson.M{
+			"account":      candidate.Account,
+			"exerciseName": candidate.ExerciseName,
+			"type":         candidate.Type,
+			"bracket":      candidate.Bracket,
+			"value":        bson.M{comparison: candidate.Value},
+		}
+
+		previous, err := database.FindOneAndUpsert[model.PersonalRecord](dbQueryParams, filter, bson.M{
+			"$set": bson.M{
+				"value":      candidate.Value,
+				"formula":    candidate.Formula,
+				"reps":       candidate.Reps,
+				"weight":     candidate.Weight,
+				"distance":   candidate.Distance,
+				"time":       candidate.Time,
+				"session":    candidate.Session,
+				"achievedAt": candidate.AchievedAt,
+			},
+		})
+
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+
+			if err != mongo.ErrNoDocuments {
+				return saved, err
+			}
+		}
+
+		candidate.PreviousValue = previous.Value
+		saved = append(saved, candidate)
+	}
+
+	return saved, nil
+}
+
+// describe returns a human readable summary of a record
+func describe(record model.PersonalRecord) string {
+	value := strconv.FormatFloat(record.Value, 'f', -1, 64)
+
+	switch record.Type {
+	case model.MAX_WEIGHT:
+		return record.ExerciseName + ": heaviest weight of " + value + "kg"
+	case model.ESTIMATED_ONE_REP_MAX:
+		return record.ExerciseName + ": estimated one rep max of " + value + "kg"
+	case model.MAX_REPS:
+		return record.ExerciseName + ": " + value + " reps at " + strconv.FormatFloat(record.Bracket, 'f', -1, 64) + "kg"
+	case model.FASTEST_TIME:
+		duration := time.Duration(record.Time) * time.Millisecond
+		return record.ExerciseName + ": " + strconv.FormatFloat(record.Bracket, 'f', -1, 64) + "m in " + duration.String()
+	case model.LONGEST_DISTANCE:
+		return record.ExerciseName + ": longest distance of " + value + "m"
+	}
+
+	return record.ExerciseName
+}
+
+// Announcement builds the text of a post announcing the provided records
+func Announcement(records []model.PersonalRecord) string {
+	text := fmt.Sprintf("New personal records (%d)!", len(records))
+	if len(records) == 1 {
+		text = "New personal record!"
+	}
+
+	for _, record := range records {
+		text += "\n" + describe(record)
+	}
+
+	return text
+}
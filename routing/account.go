@@ -34,6 +34,9 @@ func ApplyAccountRoutes(router *gin.Engine, redisClient *redis.Client, mongoClie
 		v1.POST("/recipe/standard", ctrl.CreateStandardAccount(false))
 		v1.POST("/recipe/standard/secure", ctrl.CreateStandardAccount(true))
 
+		// deletion revokes every token, owners undo it with their credentials
+		v1.POST("/restore/credentials", ctrl.RestoreAccountWithCredentials())
+
 		v1.POST("/recipe/apple")
 		v1.POST("/recipe/google")
 	}
@@ -47,12 +50,24 @@ func ApplyAccountRoutes(router *gin.Engine, redisClient *redis.Client, mongoClie
 		v1Authorized.GET("/profile/id/:value", ctrl.GetProfile("id"))
 		v1Authorized.GET("/profile/username/:value", ctrl.GetProfile("username"))
 
+		v1Authorized.POST("/restore/:id", ctrl.RestoreAccount())
+
 		v1Authorized.PUT("/lastseen", ctrl.SetAccountLastSeen())
 		v1Authorized.PUT("/preferences/notifications", ctrl.UpdateAccount("notifications"))
 		v1Authorized.PUT("/preferences/privacy", ctrl.UpdateAccount("privacy"))
 		v1Authorized.PUT("/preferences/profile", ctrl.UpdateAccount("profile"))
 		v1Authorized.PUT("/preferences/biometrics", ctrl.UpdateAccount("biometrics"))
+		v1Authorized.PUT("/preferences/units", ctrl.UpdateAccount("units"))
 
 		v1Authorized.DELETE("/", ctrl.DeleteAccount())
 	}
+
+	// a deleted account has no permissions to attach, so it can only be
+	// restored by its owner with a token that is still valid or with its
+	// credentials
+	v1Deleted := router.Group("/v1/account")
+	v1Deleted.Use(middleware.ValidateRequest())
+	{
+		v1Deleted.POST("/restore", ctrl.RestoreAccount())
+	}
 }
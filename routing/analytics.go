@@ -0,0 +1,35 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyAnalyticsRoutes(router *gin.Engine, mongoClient *mongo.Client) {
+	const DATABASE_NAME string = "prod"
+
+	ctrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "exercise_sessions",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	permissionHandler := middleware.PermissionMiddlewareHandler{
+		MongoClient:           mongoClient,
+		DatabaseName:          DATABASE_NAME,
+		RoleCollectionName:    "role",
+		AccountCollectionName: "account",
+	}
+
+	v1Authorized := router.Group("/v1/analytics")
+	v1Authorized.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
+	{
+		v1Authorized.GET("/account/:accountId/volume", ctrl.GetTrainingVolume())
+		v1Authorized.GET("/account/:accountId/muscle-group", ctrl.GetMuscleGroupVolume())
+		v1Authorized.GET("/account/:accountId/frequency", ctrl.GetTrainingFrequency())
+		v1Authorized.GET("/account/:accountId/progression", ctrl.GetExerciseProgression())
+	}
+}
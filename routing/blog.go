@@ -34,6 +34,7 @@ func ApplyBlogRoutes(router *gin.Engine, mongoClient *mongo.Client) {
 	v1Authorized.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
 	{
 		v1Authorized.POST("/", ctrl.CreateBlog())
+		v1Authorized.POST("/:id/restore", ctrl.RestoreBlog())
 
 		v1Authorized.PUT("/", ctrl.UpdateBlog())
 
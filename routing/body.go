@@ -0,0 +1,42 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyBodyRoutes(router *gin.Engine, mongoClient *mongo.Client, s3Client *s3.Client) {
+	const DATABASE_NAME string = "prod"
+
+	ctrl := controller.AresController{
+		DB:             mongoClient,
+		S3:             s3Client,
+		CollectionName: "body_measurement",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	permissionHandler := middleware.PermissionMiddlewareHandler{
+		MongoClient:           mongoClient,
+		DatabaseName:          DATABASE_NAME,
+		RoleCollectionName:    "role",
+		AccountCollectionName: "account",
+	}
+
+	v1Authorized := router.Group("/v1/body")
+	v1Authorized.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
+	{
+		v1Authorized.GET("/measurement", ctrl.GetBodyMeasurements())
+		v1Authorized.GET("/measurement/id/:measurementId", ctrl.GetBodyMeasurementById())
+		v1Authorized.GET("/trend/:metric", ctrl.GetBodyTrend())
+
+		v1Authorized.POST("/measurement", ctrl.CreateBodyMeasurement())
+
+		v1Authorized.PUT("/measurement", ctrl.UpdateBodyMeasurement())
+
+		v1Authorized.DELETE("/measurement/:measurementId", ctrl.DeleteBodyMeasurement())
+	}
+}
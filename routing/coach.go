@@ -0,0 +1,43 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyCoachRoutes(router *gin.Engine, mongoClient *mongo.Client) {
+	const DATABASE_NAME string = "prod"
+
+	ctrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "coach_link",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	permissionHandler := middleware.PermissionMiddlewareHandler{
+		MongoClient:           mongoClient,
+		DatabaseName:          DATABASE_NAME,
+		RoleCollectionName:    "role",
+		AccountCollectionName: "account",
+	}
+
+	v1Authorized := router.Group("/v1/coach")
+	v1Authorized.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
+	{
+		v1Authorized.GET("/athletes", ctrl.GetCoachLinks("coach"))
+		v1Authorized.GET("/coaches", ctrl.GetCoachLinks("athlete"))
+		v1Authorized.GET("/athlete/:athleteId/session", ctrl.GetAthleteSessions())
+		v1Authorized.GET("/athlete/:athleteId/meal-plan", ctrl.GetAthleteMealPlans())
+
+		v1Authorized.POST("/invite", ctrl.InviteAthlete())
+		v1Authorized.POST("/athlete/:athleteId/session", ctrl.AssignSession())
+		v1Authorized.POST("/athlete/:athleteId/meal-plan", ctrl.AssignMealPlan())
+
+		v1Authorized.PUT("/:linkId/accept", ctrl.AcceptCoachInvite())
+
+		v1Authorized.DELETE("/:linkId", ctrl.RemoveCoachLink())
+	}
+}
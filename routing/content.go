@@ -60,7 +60,8 @@ func ApplyContentRoutes(router *gin.Engine, mongoClient *mongo.Client, s3Client
 		v1Authorized.GET("/comment/id/:id/likes", likeCtrl.GetLikeList("comment"))
 
 		// get isLiked
-		v1Authorized.GET("/post/id/:id/liked", likeCtrl.IsLiked())
+		v1Authorized.GET("/post/id/:id/liked", likeCtrl.IsLiked("post"))
+		v1Authorized.GET("/comment/id/:id/liked", likeCtrl.IsLiked("comment"))
 
 		// get like count
 		v1Authorized.GET("/post/id/:id/likes/count", likeCtrl.GetLikeCount("post"))
@@ -68,6 +69,7 @@ func ApplyContentRoutes(router *gin.Engine, mongoClient *mongo.Client, s3Client
 
 		// get comments (paginated)
 		v1Authorized.GET("/post/id/:id/comments", commentCtrl.GetCommentsByPostID())
+		v1Authorized.GET("/comment/id/:id/replies", commentCtrl.GetCommentReplies())
 
 		// get comment count
 		v1Authorized.GET("/post/id/:id/comments/count", commentCtrl.GetCommentCount("post"))
@@ -78,6 +80,10 @@ func ApplyContentRoutes(router *gin.Engine, mongoClient *mongo.Client, s3Client
 		v1Authorized.POST("/comment", commentCtrl.CreateComment())
 		v1Authorized.POST("/like", likeCtrl.AddLike())
 
+		// restore deleted content
+		v1Authorized.POST("/post/:id/restore", postCtrl.RestorePost())
+		v1Authorized.POST("/comment/:id/restore", commentCtrl.RestoreComment())
+
 		// update content
 		v1Authorized.PUT("/post", postCtrl.UpdatePost())
 		v1Authorized.PUT("/comment", commentCtrl.UpdateComment())
@@ -87,6 +93,7 @@ func ApplyContentRoutes(router *gin.Engine, mongoClient *mongo.Client, s3Client
 		v1Authorized.DELETE("/comment/:id", commentCtrl.DeleteComment())
 
 		// remove likes
-		v1Authorized.DELETE("/like/post/:id", likeCtrl.RemoveLike())
+		v1Authorized.DELETE("/like/post/:id", likeCtrl.RemoveLike("post"))
+		v1Authorized.DELETE("/like/comment/:id", likeCtrl.RemoveLike("comment"))
 	}
 }
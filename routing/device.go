@@ -0,0 +1,27 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyDeviceRoutes(router *gin.Engine, mongoClient *mongo.Client) {
+	const DATABASE_NAME string = "prod"
+
+	ctrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "device_token",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	v1Authorized := router.Group("/v1/device")
+	v1Authorized.Use(middleware.ValidateRequest())
+	{
+		v1Authorized.GET("/", ctrl.GetDeviceTokens())
+		v1Authorized.POST("/", ctrl.RegisterDeviceToken())
+		v1Authorized.DELETE("/:token", ctrl.DeleteDeviceToken())
+	}
+}
@@ -0,0 +1,68 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyDietRoutes(router *gin.Engine, mongoClient *mongo.Client) {
+	const DATABASE_NAME string = "prod"
+
+	foodCtrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "food",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	dietCtrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "diet_entry",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	targetCtrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "nutrition_target",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	permissionHandler := middleware.PermissionMiddlewareHandler{
+		MongoClient:           mongoClient,
+		DatabaseName:          DATABASE_NAME,
+		RoleCollectionName:    "role",
+		AccountCollectionName: "account",
+	}
+
+	v1Food := router.Group("/v1/food")
+	v1Food.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
+	{
+		v1Food.GET("/id/:foodId", foodCtrl.GetFoodById())
+		v1Food.GET("/barcode/:barcode", foodCtrl.GetFoodByBarcode())
+		v1Food.GET("/query", foodCtrl.QueryFood())
+
+		v1Food.POST("/", foodCtrl.CreateFood())
+
+		v1Food.PUT("/", foodCtrl.UpdateFood())
+
+		v1Food.DELETE("/:foodId", foodCtrl.DeleteFood())
+	}
+
+	v1Diet := router.Group("/v1/diet")
+	v1Diet.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
+	{
+		v1Diet.GET("/day/:date", dietCtrl.GetDietDay())
+		v1Diet.GET("/summary/day/:date", dietCtrl.GetNutritionSummary(1))
+		v1Diet.GET("/summary/week/:date", dietCtrl.GetNutritionSummary(7))
+		v1Diet.GET("/target", targetCtrl.GetNutritionTarget())
+
+		v1Diet.POST("/", dietCtrl.CreateDietEntry())
+
+		v1Diet.PUT("/", dietCtrl.UpdateDietEntry())
+		v1Diet.PUT("/target", targetCtrl.UpdateNutritionTarget())
+
+		v1Diet.DELETE("/:entryId", dietCtrl.DeleteDietEntry())
+	}
+}
@@ -34,10 +34,26 @@ func ApplyExerciseRoutes(router *gin.Engine, mongoClient *mongo.Client) {
 	{
 		v1Authorized.GET("/id/:value", ctrl.GetExerciseSessionByID())
 		v1Authorized.GET("/search", ctrl.GetExerciseSessionByQuery())
+		v1Authorized.GET("/:sessionId/comment", ctrl.GetSessionComments())
+		v1Authorized.GET("/:sessionId/route", ctrl.GetSessionRoute())
 
 		v1Authorized.POST("/", ctrl.CreateExerciseSession())
+		v1Authorized.POST("/import", ctrl.ImportSessionsFromCSV())
+		v1Authorized.POST("/import/activity", ctrl.ImportActivity())
+
+		// live logging for in-progress sessions
+		v1Authorized.POST("/:sessionId/exercise", ctrl.AppendSessionExercise())
+		v1Authorized.POST("/:sessionId/exercise/:exerciseIndex/set", ctrl.AppendExerciseSet())
+		v1Authorized.POST("/:sessionId/exercise/:exerciseIndex/additional", ctrl.AppendAdditionalExercise())
+		v1Authorized.POST("/:sessionId/comment", ctrl.CreateSessionComment())
+		v1Authorized.POST("/:sessionId/restore", ctrl.RestoreExerciseSession())
 
 		v1Authorized.PUT("/", ctrl.UpdateExerciseSession())
+		v1Authorized.PUT("/:sessionId/exercise/:exerciseIndex/set/:setIndex", ctrl.UpdateExerciseSet())
+		v1Authorized.PUT("/:sessionId/reorder", ctrl.ReorderSessionExercises())
+		v1Authorized.PUT("/:sessionId/start", ctrl.StartAssignedSession())
+		v1Authorized.PUT("/:sessionId/complete", ctrl.CompleteExerciseSession())
+		v1Authorized.PUT("/:sessionId/route/location", ctrl.LinkRouteLocation())
 
 		v1Authorized.DELETE("/:sessionId", ctrl.DeleteExerciseSession())
 	}
@@ -0,0 +1,32 @@
+package routing
+
+import (
+	"ares/config"
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyExportRoutes(router *gin.Engine, mongoClient *mongo.Client, s3Client *s3.Client) {
+	const DATABASE_NAME string = "prod"
+
+	conf := config.Get()
+
+	ctrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "export_job",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	v1Authorized := router.Group("/v1/export")
+	v1Authorized.Use(middleware.ValidateRequest())
+	{
+		v1Authorized.GET("/", ctrl.GetDataExports())
+		v1Authorized.GET("/:jobId", ctrl.GetDataExport(s3Client, conf.S3.Bucket))
+
+		v1Authorized.POST("/", ctrl.RequestDataExport(s3Client, conf.S3.Bucket))
+	}
+}
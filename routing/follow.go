@@ -24,9 +24,16 @@ func ApplyFollowRoutes(router *gin.Engine, mongoClient *mongo.Client) {
 		v1Authorized.GET("/following-list/:id", ctrl.GetConnectionList("following"))
 		v1Authorized.GET("/mutual/followers/:id", ctrl.GetMutualConnections("followed"))
 		v1Authorized.GET("/mutual/following/:id", ctrl.GetMutualConnections("following"))
+		v1Authorized.GET("/requests", ctrl.GetFollowRequests())
+		v1Authorized.GET("/blocked", ctrl.GetBlockedAccounts())
 
 		v1Authorized.POST("/follow/:followedId", ctrl.StartFollowing())
+		v1Authorized.POST("/block/:accountId", ctrl.BlockAccount())
+
+		v1Authorized.PUT("/requests/:followingId/accept", ctrl.AcceptFollowRequest())
 
 		v1Authorized.DELETE("/unfollow/:followedId", ctrl.StopFollowing())
+		v1Authorized.DELETE("/requests/:followingId", ctrl.RejectFollowRequest())
+		v1Authorized.DELETE("/block/:accountId", ctrl.UnblockAccount())
 	}
 }
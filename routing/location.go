@@ -30,6 +30,7 @@ func ApplyLocationRoutes(router *gin.Engine, mongoClient *mongo.Client) {
 		v1Authorized.GET("/search", ctrl.GetLocationsByQuery())
 
 		v1Authorized.POST("/", ctrl.CreateLocation())
+		v1Authorized.POST("/:id/restore", ctrl.RestoreLocation())
 
 		v1Authorized.PUT("/", ctrl.UpdateLocation())
 
@@ -0,0 +1,38 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyMessageRoutes(router *gin.Engine, mongoClient *mongo.Client, s3Client *s3.Client) {
+	const DATABASE_NAME string = "prod"
+
+	ctrl := controller.AresController{
+		DB:             mongoClient,
+		S3:             s3Client,
+		CollectionName: "conversation",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	v1Authorized := router.Group("/v1/conversation")
+	v1Authorized.Use(middleware.ValidateRequest())
+	{
+		v1Authorized.GET("/", ctrl.GetConversations())
+		v1Authorized.GET("/:conversationId", ctrl.GetConversationById())
+		v1Authorized.GET("/:conversationId/messages", ctrl.GetMessages())
+
+		v1Authorized.POST("/", ctrl.CreateConversation())
+		v1Authorized.POST("/:conversationId/messages", ctrl.SendMessage())
+
+		v1Authorized.PUT("/:conversationId/read", ctrl.MarkConversationRead())
+		v1Authorized.PUT("/:conversationId/mute", ctrl.MuteConversation(true))
+
+		v1Authorized.DELETE("/:conversationId/mute", ctrl.MuteConversation(false))
+		v1Authorized.DELETE("/:conversationId", ctrl.LeaveConversation())
+	}
+}
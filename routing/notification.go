@@ -0,0 +1,31 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyNotificationRoutes(router *gin.Engine, mongoClient *mongo.Client) {
+	const DATABASE_NAME string = "prod"
+
+	ctrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "notification",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	v1Authorized := router.Group("/v1/notification")
+	v1Authorized.Use(middleware.ValidateRequest())
+	{
+		v1Authorized.GET("/", ctrl.GetNotifications())
+		v1Authorized.GET("/unread/count", ctrl.GetUnreadNotificationCount())
+
+		v1Authorized.PUT("/read", ctrl.MarkNotificationsRead())
+		v1Authorized.PUT("/:notificationId/read", ctrl.MarkNotificationsRead())
+
+		v1Authorized.DELETE("/:notificationId", ctrl.DeleteNotification())
+	}
+}
@@ -0,0 +1,44 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyProgramRoutes(router *gin.Engine, mongoClient *mongo.Client) {
+	const DATABASE_NAME string = "prod"
+
+	ctrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "program",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	permissionHandler := middleware.PermissionMiddlewareHandler{
+		MongoClient:           mongoClient,
+		DatabaseName:          DATABASE_NAME,
+		RoleCollectionName:    "role",
+		AccountCollectionName: "account",
+	}
+
+	v1Authorized := router.Group("/v1/program")
+	v1Authorized.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
+	{
+		v1Authorized.GET("/id/:programId", ctrl.GetProgramById())
+		v1Authorized.GET("/account/:accountId", ctrl.GetProgramsByAccount())
+		v1Authorized.GET("/enrollment", ctrl.GetEnrollments())
+		v1Authorized.GET("/enrollment/:enrollmentId/today", ctrl.GetTodaysWorkout())
+
+		v1Authorized.POST("/", ctrl.CreateProgram())
+		v1Authorized.POST("/:programId/enroll", ctrl.EnrollInProgram())
+		v1Authorized.POST("/enrollment/:enrollmentId/start", ctrl.StartScheduledWorkout())
+
+		v1Authorized.PUT("/", ctrl.UpdateProgram())
+
+		v1Authorized.DELETE("/:programId", ctrl.DeleteProgram())
+		v1Authorized.DELETE("/enrollment/:enrollmentId", ctrl.CancelEnrollment())
+	}
+}
@@ -0,0 +1,33 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyRealtimeRoutes(router *gin.Engine, mongoClient *mongo.Client, redisClient *redis.Client) {
+	const DATABASE_NAME string = "prod"
+
+	ctrl := controller.AresController{
+		DB:           mongoClient,
+		RedisCache:   redisClient,
+		DatabaseName: DATABASE_NAME,
+	}
+
+	v1Authorized := router.Group("/v1/realtime")
+	v1Authorized.Use(middleware.ValidateRequest())
+	{
+		v1Authorized.POST("/ticket", ctrl.IssueStreamTicket())
+	}
+
+	v1Stream := router.Group("/v1/realtime")
+	v1Stream.Use(middleware.ValidateStreamRequest())
+	{
+		v1Stream.GET("/ws", ctrl.StreamEventsWebSocket())
+		v1Stream.GET("/events", ctrl.StreamEventsSSE())
+	}
+}
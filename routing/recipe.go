@@ -0,0 +1,56 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyRecipeRoutes(router *gin.Engine, mongoClient *mongo.Client) {
+	const DATABASE_NAME string = "prod"
+
+	recipeCtrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "recipe",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	planCtrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "meal_plan",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	permissionHandler := middleware.PermissionMiddlewareHandler{
+		MongoClient:           mongoClient,
+		DatabaseName:          DATABASE_NAME,
+		RoleCollectionName:    "role",
+		AccountCollectionName: "account",
+	}
+
+	v1Recipe := router.Group("/v1/recipe")
+	v1Recipe.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
+	{
+		v1Recipe.GET("/id/:recipeId", recipeCtrl.GetRecipeById())
+		v1Recipe.GET("/account/:accountId", recipeCtrl.GetRecipesByAccount())
+
+		v1Recipe.POST("/", recipeCtrl.CreateRecipe())
+
+		v1Recipe.PUT("/", recipeCtrl.UpdateRecipe())
+
+		v1Recipe.DELETE("/:recipeId", recipeCtrl.DeleteRecipe())
+	}
+
+	v1MealPlan := router.Group("/v1/meal-plan")
+	v1MealPlan.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
+	{
+		v1MealPlan.GET("/", planCtrl.GetMealPlans())
+		v1MealPlan.GET("/id/:planId", planCtrl.GetMealPlanById())
+
+		v1MealPlan.POST("/:planId/meal/:mealIndex/log", planCtrl.LogPlannedMeal())
+
+		v1MealPlan.DELETE("/:planId", planCtrl.DeleteMealPlan())
+	}
+}
@@ -0,0 +1,32 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyPersonalRecordRoutes(router *gin.Engine, mongoClient *mongo.Client) {
+	const DATABASE_NAME string = "prod"
+
+	ctrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "personal_record",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	permissionHandler := middleware.PermissionMiddlewareHandler{
+		MongoClient:           mongoClient,
+		DatabaseName:          DATABASE_NAME,
+		RoleCollectionName:    "role",
+		AccountCollectionName: "account",
+	}
+
+	v1Authorized := router.Group("/v1/personal-record")
+	v1Authorized.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
+	{
+		v1Authorized.GET("/account/:accountId", ctrl.GetPersonalRecordsByAccount())
+	}
+}
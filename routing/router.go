@@ -18,10 +18,23 @@ func ApplyRoutes(
 	ApplyAccountRoutes(engine, redisClient, mongoClient)
 	ApplyExerciseInfoRoutes(engine, mongoClient)
 	ApplyExerciseRoutes(engine, mongoClient)
+	ApplyWorkoutTemplateRoutes(engine, mongoClient)
+	ApplyProgramRoutes(engine, mongoClient)
+	ApplyCoachRoutes(engine, mongoClient)
+	ApplyPersonalRecordRoutes(engine, mongoClient)
+	ApplyDietRoutes(engine, mongoClient)
+	ApplyRecipeRoutes(engine, mongoClient)
+	ApplyBodyRoutes(engine, mongoClient, s3Client)
+	ApplyAnalyticsRoutes(engine, mongoClient)
 	ApplyFollowRoutes(engine, mongoClient)
+	ApplyNotificationRoutes(engine, mongoClient)
+	ApplyDeviceRoutes(engine, mongoClient)
+	ApplyMessageRoutes(engine, mongoClient, s3Client)
+	ApplyRealtimeRoutes(engine, mongoClient, redisClient)
 	ApplyContentRoutes(engine, mongoClient, s3Client)
 	ApplyLocationRoutes(engine, mongoClient)
 	ApplyFileUploadRoutes(engine, mongoClient, s3Client)
+	ApplyExportRoutes(engine, mongoClient, s3Client)
 	ApplyBlogRoutes(engine, mongoClient)
 	ApplyRoleRoutes(engine, mongoClient)
 	ApplyPermissionRoutes(engine, mongoClient)
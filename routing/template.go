@@ -0,0 +1,40 @@
+package routing
+
+import (
+	"ares/controller"
+	"ares/middleware"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func ApplyWorkoutTemplateRoutes(router *gin.Engine, mongoClient *mongo.Client) {
+	const DATABASE_NAME string = "prod"
+
+	ctrl := controller.AresController{
+		DB:             mongoClient,
+		CollectionName: "workout_template",
+		DatabaseName:   DATABASE_NAME,
+	}
+
+	permissionHandler := middleware.PermissionMiddlewareHandler{
+		MongoClient:           mongoClient,
+		DatabaseName:          DATABASE_NAME,
+		RoleCollectionName:    "role",
+		AccountCollectionName: "account",
+	}
+
+	v1Authorized := router.Group("/v1/workout-template")
+	v1Authorized.Use(middleware.ValidateRequest(), permissionHandler.AttachPermissions())
+	{
+		v1Authorized.GET("/id/:templateId", ctrl.GetWorkoutTemplateById())
+		v1Authorized.GET("/account/:accountId", ctrl.GetWorkoutTemplatesByAccount())
+
+		v1Authorized.POST("/", ctrl.CreateWorkoutTemplate())
+		v1Authorized.POST("/:templateId/start", ctrl.StartSessionFromTemplate())
+
+		v1Authorized.PUT("/", ctrl.UpdateWorkoutTemplate())
+
+		v1Authorized.DELETE("/:templateId", ctrl.DeleteWorkoutTemplate())
+	}
+}
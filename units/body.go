@@ -0,0 +1,87 @@
+package units
+
+import "ares/model"
+
+const CentimetersPerInch = 2.54
+
+// ToCentimeters converts a length in the provided system to centimeters,
+// imperial lengths are in inches
+func ToCentimeters(value float64, system model.MeasurementSystem) float64 {
+	if system == model.IMPERIAL {
+		return value * CentimetersPerInch
+	}
+
+	return value
+}
+
+// FromCentimeters converts a length in centimeters to the provided system
+func FromCentimeters(centimeters float64, system model.MeasurementSystem) float64 {
+	if system == model.IMPERIAL {
+		return centimeters / CentimetersPerInch
+	}
+
+	return centimeters
+}
+
+// bodyLengths returns the length measurements of a body measurement
+func bodyLengths(measurement *model.BodyMeasurement) []*float64 {
+	return []*float64{
+		&measurement.Neck,
+		&measurement.Chest,
+		&measurement.Waist,
+		&measurement.Hips,
+		&measurement.Arms,
+		&measurement.Thighs,
+		&measurement.Calves,
+	}
+}
+
+// CanonicalizeBodyMeasurement converts a body measurement logged in the
+// provided system to kilograms and centimeters for storage
+func CanonicalizeBodyMeasurement(measurement model.BodyMeasurement, system model.MeasurementSystem) model.BodyMeasurement {
+	measurement.Weight = Round(ToKilograms(measurement.Weight, system))
+
+	for _, length := range bodyLengths(&measurement) {
+		*length = Round(ToCentimeters(*length, system))
+	}
+
+	return measurement
+}
+
+// ConvertBodyMeasurement converts a stored body measurement to the provided
+// system for display, imperial measurements are returned in pounds and inches
+func ConvertBodyMeasurement(measurement model.BodyMeasurement, system model.MeasurementSystem) model.BodyMeasurement {
+	if system != model.IMPERIAL {
+		return measurement
+	}
+
+	measurement.Weight = Round(FromKilograms(measurement.Weight, system))
+
+	for _, length := range bodyLengths(&measurement) {
+		*length = Round(FromCentimeters(*length, system))
+	}
+
+	return measurement
+}
+
+// ConvertBodyMetric converts a stored value of the provided metric to the
+// provided system. Body fat is a percentage in either system
+func ConvertBodyMetric(value float64, metric model.BodyMetric, system model.MeasurementSystem) float64 {
+	switch metric {
+	case model.BODY_FAT:
+		return value
+	case model.BODY_WEIGHT:
+		return Round(FromKilograms(value, system))
+	}
+
+	return Round(FromCentimeters(value, system))
+}
+
+// ConvertBiometrics converts the weight and height of biometrics kept in one
+// measurement system to another
+func ConvertBiometrics(biometrics model.Biometrics, from model.MeasurementSystem, to model.MeasurementSystem) model.Biometrics {
+	biometrics.Weight = float32(Round(FromKilograms(ToKilograms(float64(biometrics.Weight), from), to)))
+	biometrics.Height = float32(Round(FromCentimeters(ToCentimeters(float64(biometrics.Height), from), to)))
+
+	return biometrics
+}
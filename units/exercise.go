@@ -0,0 +1,123 @@
+package units
+
+import (
+	"ares/model"
+	"math"
+)
+
+// Kilograms returns the weight in kilograms, preferring the canonical value
+// stored alongside the logged weight
+func Kilograms(weight model.ExerciseValueWeight) float64 {
+	if weight.Kilograms != 0 {
+		return weight.Kilograms
+	}
+
+	return Round(ToKilograms(float64(weight.Value), weight.Measurement))
+}
+
+// Meters returns the distance in meters, preferring the canonical value
+// stored alongside the logged distance
+func Meters(distance model.ExerciseValueDistance) float64 {
+	if distance.Meters != 0 {
+		return distance.Meters
+	}
+
+	return Round(ToMeters(float64(distance.Value), distance.Measurement))
+}
+
+// CanonicalizeWeight stores the weight in kilograms alongside the value it
+// was logged in
+func CanonicalizeWeight(weight model.ExerciseValueWeight) model.ExerciseValueWeight {
+	weight.Kilograms = Round(ToKilograms(float64(weight.Value), weight.Measurement))
+
+	return weight
+}
+
+// CanonicalizeSet stores the SI value of the weight and distance of a set
+// alongside the values the set was logged in
+func CanonicalizeSet(set model.ExerciseSet) model.ExerciseSet {
+	set.Weight = CanonicalizeWeight(set.Weight)
+	set.Distance.Meters = Round(ToMeters(float64(set.Distance.Value), set.Distance.Measurement))
+
+	return set
+}
+
+// ConvertSet converts the weight and distance of a set to the provided
+// system for display. Values already logged in the system are left as is.
+// Distances are converted to meters or feet, as the logged distance is a
+// whole number and larger units would lose precision
+func ConvertSet(set model.ExerciseSet, system model.MeasurementSystem) model.ExerciseSet {
+	if system == "" {
+		return set
+	}
+
+	weightSystem := set.Weight.Measurement
+	if weightSystem == "" {
+		weightSystem = model.METRIC
+	}
+
+	if set.Weight.Value != 0 && weightSystem != system {
+		set.Weight.Value = float32(Round(FromKilograms(Kilograms(set.Weight), system)))
+		set.Weight.Measurement = system
+	}
+
+	if set.Distance.Value != 0 && SystemOf(set.Distance.Measurement) != system {
+		measurement := model.METER
+		if system == model.IMPERIAL {
+			measurement = model.FEET
+		}
+
+		set.Distance.Value = uint32(math.Round(FromMeters(Meters(set.Distance), measurement)))
+		set.Distance.Measurement = measurement
+	}
+
+	return set
+}
+
+// mapSets applies fn to every set of every exercise in the session. The
+// exercises are copied so the provided session is left untouched
+func mapSets(session model.Session, fn func(set model.ExerciseSet) model.ExerciseSet) model.Session {
+	exercises := make([]model.Exercise, len(session.Exercises))
+
+	for i, exercise := range session.Exercises {
+		sets := make([]model.ExerciseSet, len(exercise.Sets))
+		for j, set := range exercise.Sets {
+			sets[j] = fn(set)
+		}
+
+		var additionalExercises []model.AdditionalExercise
+		for _, additional := range exercise.AdditionalExercise {
+			additionalSets := make([]model.ExerciseSet, len(additional.Sets))
+			for j, set := range additional.Sets {
+				additionalSets[j] = fn(set)
+			}
+
+			additional.Sets = additionalSets
+			additionalExercises = append(additionalExercises, additional)
+		}
+
+		exercise.Sets = sets
+		exercise.AdditionalExercise = additionalExercises
+		exercises[i] = exercise
+	}
+
+	session.Exercises = exercises
+
+	return session
+}
+
+// CanonicalizeSession canonicalizes every set in the session
+func CanonicalizeSession(session model.Session) model.Session {
+	return mapSets(session, CanonicalizeSet)
+}
+
+// ConvertSession converts every set in the session to the provided system
+func ConvertSession(session model.Session, system model.MeasurementSystem) model.Session {
+	if system == "" {
+		return session
+	}
+
+	return mapSets(session, func(set model.ExerciseSet) model.ExerciseSet {
+		return ConvertSet(set, system)
+	})
+}
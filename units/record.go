@@ -0,0 +1,38 @@
+package units
+
+import "ares/model"
+
+// ConvertPersonalRecord converts a stored personal record to the provided
+// system for display. Records are stored in kilograms and meters, imperial
+// records are returned in pounds and miles
+func ConvertPersonalRecord(record model.PersonalRecord, system model.MeasurementSystem) model.PersonalRecord {
+	if system != model.IMPERIAL {
+		return record
+	}
+
+	pounds := func(kilograms float64) float64 {
+		return Round(FromKilograms(kilograms, model.IMPERIAL))
+	}
+
+	miles := func(meters float64) float64 {
+		return Round(FromMeters(meters, model.MILE))
+	}
+
+	switch record.Type {
+	case model.MAX_WEIGHT, model.ESTIMATED_ONE_REP_MAX:
+		record.Value = pounds(record.Value)
+		record.PreviousValue = pounds(record.PreviousValue)
+	case model.MAX_REPS:
+		record.Bracket = pounds(record.Bracket)
+	case model.FASTEST_TIME:
+		record.Bracket = miles(record.Bracket)
+	case model.LONGEST_DISTANCE:
+		record.Value = miles(record.Value)
+		record.PreviousValue = miles(record.PreviousValue)
+	}
+
+	record.Weight = pounds(record.Weight)
+	record.Distance = miles(record.Distance)
+
+	return record
+}
@@ -0,0 +1,85 @@
+package units
+
+import (
+	"ares/model"
+	"errors"
+	"math"
+	"strings"
+)
+
+const (
+	KilogramsPerPound  = 0.45359237
+	MetersPerKilometer = 1000
+	MetersPerMile      = 1609.344
+	MetersPerFoot      = 0.3048
+)
+
+// ParseSystem parses a measurement system, ignoring case
+func ParseSystem(s string) (model.MeasurementSystem, error) {
+	system := model.MeasurementSystem(strings.ToUpper(s))
+
+	if system != model.METRIC && system != model.IMPERIAL {
+		return "", errors.New("unknown measurement system " + s)
+	}
+
+	return system, nil
+}
+
+// ToKilograms converts a weight in the provided system to kilograms, weights
+// without a measurement system are assumed to be metric
+func ToKilograms(value float64, system model.MeasurementSystem) float64 {
+	if system == model.IMPERIAL {
+		return value * KilogramsPerPound
+	}
+
+	return value
+}
+
+// FromKilograms converts a weight in kilograms to the provided system
+func FromKilograms(kilograms float64, system model.MeasurementSystem) float64 {
+	if system == model.IMPERIAL {
+		return kilograms / KilogramsPerPound
+	}
+
+	return kilograms
+}
+
+// metersPer returns the number of meters in one of the provided measurement,
+// distances without a measurement are assumed to be meters
+func metersPer(measurement model.DistanceMeasurement) float64 {
+	switch measurement {
+	case model.KILOMETER:
+		return MetersPerKilometer
+	case model.MILE:
+		return MetersPerMile
+	case model.FEET:
+		return MetersPerFoot
+	}
+
+	return 1
+}
+
+// ToMeters converts a distance in the provided measurement to meters
+func ToMeters(value float64, measurement model.DistanceMeasurement) float64 {
+	return value * metersPer(measurement)
+}
+
+// FromMeters converts a distance in meters to the provided measurement
+func FromMeters(meters float64, measurement model.DistanceMeasurement) float64 {
+	return meters / metersPer(measurement)
+}
+
+// SystemOf returns the measurement system a distance measurement belongs to
+func SystemOf(measurement model.DistanceMeasurement) model.MeasurementSystem {
+	if measurement == model.MILE || measurement == model.FEET {
+		return model.IMPERIAL
+	}
+
+	return model.METRIC
+}
+
+// Round rounds a converted value to two decimal places so that values
+// survive a round trip between systems without accumulating noise
+func Round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
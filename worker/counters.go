@@ -0,0 +1,190 @@
+package worker
+
+import (
+	"ares/model"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// counterBatchSize is the number of corrected documents written at once
+const counterBatchSize = 500
+
+// counter is a field of a post or comment counting the documents of another
+// collection left on it
+type counter struct {
+	field          string
+	collectionName string
+}
+
+// countedCollections lists the counters kept on every post and comment. Likes
+// and comments both point at the item they were left on with post and type
+var countedCollections = []struct {
+	collectionName string
+	itemType       model.PostItemType
+	counters       []counter
+}{
+	{"post", model.POST, []counter{{"likeCount", "like"}, {"commentCount", "comment"}}},
+	{"comment", model.COMMENT, []counter{{"likeCount", "like"}, {"replyCount", "comment"}}},
+}
+
+// CounterReconciler recomputes the like, comment and reply counters of posts
+// and comments from the likes and comments on them. The counters are updated
+// as likes and comments come and go, this corrects the drift left by updates
+// that failed and by documents removed along with an account
+type CounterReconciler struct {
+	MongoClient  *mongo.Client
+	DatabaseName string
+}
+
+// driftPipeline returns an aggregation finding the documents with counters
+// that differ from the actual counts, along with the difference of every
+// counter
+func driftPipeline(itemType model.PostItemType, counters []counter) bson.A {
+	pipeline := bson.A{}
+	drift := bson.M{}
+	drifted := bson.A{}
+
+	for _, c := range counters {
+		actual := "actual_" + c.field
+
+		pipeline = append(pipeline, bson.M{"$lookup": bson.M{
+			"from": c.collectionName,
+			"let":  bson.M{"id": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$post", "$$id"}},
+					bson.M{"$eq": bson.A{"$type", itemType}},
+				}}}},
+				bson.M{"$count": "count"},
+			},
+			"as": actual,
+		}})
+
+		drift[c.field] = bson.M{"$subtract": bson.A{
+			bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$" + actual + ".count", 0}}, 0}},
+			bson.M{"$ifNull": bson.A{"$" + c.field, 0}},
+		}}
+
+		drifted = append(drifted, bson.M{"$ne": bson.A{"$drift." + c.field, 0}})
+	}
+
+	return append(pipeline,
+		bson.M{"$project": bson.M{"drift": drift}},
+		bson.M{"$match": bson.M{"$expr": bson.M{"$or": drifted}}},
+	)
+}
+
+// reconcile corrects the counters of a single collection and returns the
+// number of corrected documents. The difference is added with $inc rather
+// than set, so likes and comments made while the counts are taken aren't
+// lost
+func (reconciler CounterReconciler) reconcile(ctx context.Context, collectionName string, itemType model.PostItemType, counters []counter) (int, error) {
+	collection := reconciler.MongoClient.Database(reconciler.DatabaseName).Collection(collectionName)
+
+	cursor, err := collection.Aggregate(ctx, driftPipeline(itemType, counters))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count drift of %s counters: %w", collectionName, err)
+	}
+
+	defer cursor.Close(ctx)
+
+	corrected := 0
+	var models []mongo.WriteModel
+
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+
+		result, err := collection.BulkWrite(ctx, models)
+		if result != nil {
+			corrected += int(result.ModifiedCount)
+		}
+
+		models = models[:0]
+
+		if err != nil {
+			return fmt.Errorf("failed to correct %s counters: %w", collectionName, err)
+		}
+
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var document struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Drift map[string]int64   `bson:"drift"`
+		}
+
+		err = cursor.Decode(&document)
+		if err != nil {
+			return corrected, fmt.Errorf("failed to decode %s counter drift: %w", collectionName, err)
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": document.ID}).
+			SetUpdate(bson.M{"$inc": document.Drift}))
+
+		if len(models) >= counterBatchSize {
+			err = flush()
+			if err != nil {
+				return corrected, err
+			}
+		}
+	}
+
+	if err = cursor.Err(); err != nil {
+		return corrected, fmt.Errorf("failed to count drift of %s counters: %w", collectionName, err)
+	}
+
+	return corrected, flush()
+}
+
+// Run reconciles the counters of posts and comments, giving up after 30
+// minutes
+func (reconciler CounterReconciler) Run(now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	return reconciler.Reconcile(ctx)
+}
+
+// Reconcile reconciles the counters of posts and comments within the provided
+// context. A failing collection does not stop the others from being
+// reconciled, the first failure is returned
+func (reconciler CounterReconciler) Reconcile(ctx context.Context) error {
+	var firstErr error
+
+	for _, counted := range countedCollections {
+		corrected, err := reconciler.reconcile(ctx, counted.collectionName, counted.itemType, counted.counters)
+		if corrected > 0 {
+			fmt.Printf("corrected counters of %d %s documents\n", corrected, counted.collectionName)
+		}
+
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+				continue
+			}
+
+			fmt.Println("failed to reconcile "+counted.collectionName+" counters: ", err)
+		}
+	}
+
+	return firstErr
+}
+
+// Task returns the reconciliation as a worker task run on the provided
+// interval
+func (reconciler CounterReconciler) Task(interval time.Duration) Task {
+	return Task{
+		Name:     "reconcile_counters",
+		Interval: interval,
+		Run:      reconciler.Run,
+	}
+}
@@ -0,0 +1,515 @@
+package worker
+
+import (
+	"ares/database"
+	"ares/model"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Purger hard-deletes documents from the _deleted collections once their
+// removal date has passed, along with the documents and bucket objects that
+// only exist because of them
+type Purger struct {
+	MongoClient  *mongo.Client
+	DatabaseName string
+	S3           *s3.Client
+	Bucket       string
+}
+
+func (purger Purger) params(collectionName string) database.QueryParams {
+	return database.QueryParams{
+		MongoClient:    purger.MongoClient,
+		DatabaseName:   purger.DatabaseName,
+		CollectionName: collectionName,
+	}
+}
+
+func (purger Purger) deleteMany(collectionName string, filter bson.M) error {
+	_, err := database.DeleteManyByFilter(purger.params(collectionName), filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete from %s: %w", collectionName, err)
+	}
+
+	return nil
+}
+
+func (purger Purger) unset(collectionName string, field string, id primitive.ObjectID) error {
+	_, err := database.UpdateManyByFilter(purger.params(collectionName), bson.M{field: id}, bson.M{"$unset": bson.M{field: ""}})
+	if err != nil {
+		return fmt.Errorf("failed to unset %s in %s: %w", field, collectionName, err)
+	}
+
+	return nil
+}
+
+// deleteObjects removes objects from the bucket. A missing object should not
+// keep the document from being purged, so failures are only logged
+func (purger Purger) deleteObjects(keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		err := database.DeleteObject(purger.S3, purger.Bucket, key)
+		if err != nil {
+			fmt.Println("failed to delete object "+key+": ", err)
+		}
+	}
+}
+
+// expired returns the documents of a _deleted collection that are due to be
+// removed
+func expired[K any](purger Purger, collectionName string, now time.Time) ([]K, error) {
+	documents, err := database.FindManyDocumentsByFilter[K](purger.params(collectionName+"_deleted"), bson.M{
+		"removalAt": bson.M{"$lte": now},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up expired %s: %w", collectionName, err)
+	}
+
+	return documents, nil
+}
+
+// remove deletes a document from a _deleted collection once its cascade has
+// been purged
+func (purger Purger) remove(collectionName string, id primitive.ObjectID) error {
+	return purger.deleteMany(collectionName+"_deleted", bson.M{"_id": id})
+}
+
+func (purger Purger) cascadeComment(comment model.Comment) error {
+	replies, err := database.FindManyDocumentsByFilter[model.Comment](purger.params("comment"), bson.M{
+		"post": comment.ID,
+		"type": model.COMMENT,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to look up replies: %w", err)
+	}
+
+	for _, reply := range replies {
+		err = purger.cascadeComment(reply)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = purger.deleteMany("comment", bson.M{"post": comment.ID, "type": model.COMMENT})
+	if err != nil {
+		return err
+	}
+
+	return purger.deleteMany("like", bson.M{"post": comment.ID, "type": model.COMMENT})
+}
+
+func (purger Purger) cascadePost(post model.Post) error {
+	for _, content := range post.Content {
+		purger.deleteObjects(content.Destination)
+	}
+
+	comments, err := database.FindManyDocumentsByFilter[model.Comment](purger.params("comment"), bson.M{
+		"post": post.ID,
+		"type": model.POST,
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to look up comments: %w", err)
+	}
+
+	for _, comment := range comments {
+		err = purger.cascadeComment(comment)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = purger.deleteMany("comment", bson.M{"post": post.ID, "type": model.POST})
+	if err != nil {
+		return err
+	}
+
+	return purger.deleteMany("like", bson.M{"post": post.ID, "type": model.POST})
+}
+
+func (purger Purger) cascadeSession(session model.Session) error {
+	err := purger.deleteMany("session_comment", bson.M{"session": session.ID})
+	if err != nil {
+		return err
+	}
+
+	err = purger.deleteMany("route", bson.M{"session": session.ID})
+	if err != nil {
+		return err
+	}
+
+	return purger.unset("post", "session", session.ID)
+}
+
+func (purger Purger) cascadeLocation(location model.Location) error {
+	err := purger.unset("post", "location", location.ID)
+	if err != nil {
+		return err
+	}
+
+	return purger.unset("route", "location", location.ID)
+}
+
+// cascadeAccount removes everything authored by an account. Locations are
+// shared with other accounts and audit entries are kept as a security record
+func (purger Purger) cascadeAccount(account model.Account) error {
+	posts, err := database.FindManyDocumentsByFilter[model.Post](purger.params("post"), bson.M{"author": account.ID})
+	if err != nil {
+		return fmt.Errorf("failed to look up posts: %w", err)
+	}
+
+	for _, post := range posts {
+		err = purger.cascadePost(post)
+		if err != nil {
+			return err
+		}
+	}
+
+	comments, err := database.FindManyDocumentsByFilter[model.Comment](purger.params("comment"), bson.M{"author": account.ID})
+	if err != nil {
+		return fmt.Errorf("failed to look up comments: %w", err)
+	}
+
+	for _, comment := range comments {
+		err = purger.cascadeComment(comment)
+		if err != nil {
+			return err
+		}
+	}
+
+	sessions, err := database.FindManyDocumentsByFilter[model.Session](purger.params("exercise_sessions"), bson.M{"author": account.ID})
+	if err != nil {
+		return fmt.Errorf("failed to look up sessions: %w", err)
+	}
+
+	for _, session := range sessions {
+		err = purger.cascadeSession(session)
+		if err != nil {
+			return err
+		}
+	}
+
+	files, err := database.FindManyDocumentsByFilter[model.File](purger.params("file"), bson.M{"owner": account.ID})
+	if err != nil {
+		return fmt.Errorf("failed to look up files: %w", err)
+	}
+
+	for _, file := range files {
+		purger.deleteObjects(file.Key)
+	}
+
+	exports, err := database.FindManyDocumentsByFilter[model.ExportJob](purger.params("export_job"), bson.M{"account": account.ID})
+	if err != nil {
+		return fmt.Errorf("failed to look up export jobs: %w", err)
+	}
+
+	for _, job := range exports {
+		purger.deleteObjects(job.Key)
+	}
+
+	filters := []struct {
+		collectionName string
+		filter         bson.M
+	}{
+		{"post", bson.M{"author": account.ID}},
+		{"comment", bson.M{"author": account.ID}},
+		{"like", bson.M{"author": account.ID}},
+		{"follow", bson.M{"$or": bson.A{bson.M{"followingId": account.ID}, bson.M{"followedId": account.ID}}}},
+		{"exercise_sessions", bson.M{"author": account.ID}},
+		{"route", bson.M{"author": account.ID}},
+		{"personal_record", bson.M{"account": account.ID}},
+		{"workout_template", bson.M{"author": account.ID}},
+		{"program", bson.M{"author": account.ID}},
+		{"program_enrollment", bson.M{"account": account.ID}},
+		{"coach_link", bson.M{"$or": bson.A{bson.M{"coach": account.ID}, bson.M{"athlete": account.ID}}}},
+		{"session_comment", bson.M{"author": account.ID}},
+		{"file", bson.M{"owner": account.ID}},
+		{"export_job", bson.M{"account": account.ID}},
+		{"diet_entry", bson.M{"account": account.ID}},
+		{"nutrition_target", bson.M{"account": account.ID}},
+		{"recipe", bson.M{"author": account.ID}},
+		{"meal_plan", bson.M{"account": account.ID}},
+		{"body_measurement", bson.M{"account": account.ID}},
+		{"notification", bson.M{"$or": bson.A{bson.M{"recipient": account.ID}, bson.M{"actor": account.ID}}}},
+		{"device_token", bson.M{"account": account.ID}},
+		{"message", bson.M{"author": account.ID}},
+		{"block", bson.M{"$or": bson.A{bson.M{"blocker": account.ID}, bson.M{"blocked": account.ID}}}},
+	}
+
+	for _, target := range filters {
+		err = purger.deleteMany(target.collectionName, target.filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	return purger.leaveConversations(account.ID)
+}
+
+// leaveConversations removes an account from its conversations, the other
+// participants keep the conversation without the messages of the account.
+// Conversations nobody is left in are removed along with their messages
+func (purger Purger) leaveConversations(accountId primitive.ObjectID) error {
+	_, err := database.UpdateManyByFilter(purger.params("conversation"), bson.M{"participants": accountId}, bson.M{
+		"$pull":  bson.M{"participants": accountId, "muted": accountId},
+		"$unset": bson.M{"reads." + accountId.Hex(): ""},
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to leave conversations: %w", err)
+	}
+
+	_, err = database.UpdateManyByFilter(purger.params("conversation"), bson.M{"lastMessage.author": accountId}, bson.M{
+		"$unset": bson.M{"lastMessage": ""},
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to unset last messages: %w", err)
+	}
+
+	empty, err := database.FindManyDocumentsByFilter[model.Conversation](purger.params("conversation"), bson.M{"participants": bson.M{"$size": 0}})
+	if err != nil {
+		return fmt.Errorf("failed to look up empty conversations: %w", err)
+	}
+
+	for _, conversation := range empty {
+		err = purger.deleteMany("message", bson.M{"conversation": conversation.ID})
+		if err == nil {
+			err = purger.deleteMany("conversation", bson.M{"_id": conversation.ID})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (purger Purger) purgeAccounts(now time.Time) (int, error) {
+	documents, err := expired[model.DeletedAccount](purger, "account", now)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, document := range documents {
+		err = purger.cascadeAccount(document.Account)
+		if err == nil {
+			err = purger.remove("account", document.ID)
+		}
+
+		if err != nil {
+			return i, fmt.Errorf("failed to purge account %s: %w", document.Account.ID.Hex(), err)
+		}
+	}
+
+	return len(documents), nil
+}
+
+func (purger Purger) purgePosts(now time.Time) (int, error) {
+	documents, err := expired[model.DeletedPost](purger, "post", now)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, document := range documents {
+		err = purger.cascadePost(document.Post)
+		if err == nil {
+			err = purger.remove("post", document.ID)
+		}
+
+		if err != nil {
+			return i, fmt.Errorf("failed to purge post %s: %w", document.Post.ID.Hex(), err)
+		}
+	}
+
+	return len(documents), nil
+}
+
+func (purger Purger) purgeComments(now time.Time) (int, error) {
+	documents, err := expired[model.DeletedComment](purger, "comment", now)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, document := range documents {
+		err = purger.cascadeComment(document.Comment)
+		if err == nil {
+			err = purger.remove("comment", document.ID)
+		}
+
+		if err != nil {
+			return i, fmt.Errorf("failed to purge comment %s: %w", document.Comment.ID.Hex(), err)
+		}
+	}
+
+	return len(documents), nil
+}
+
+func (purger Purger) purgeSessions(now time.Time) (int, error) {
+	documents, err := expired[model.DeletedSession](purger, "exercise_sessions", now)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, document := range documents {
+		err = purger.cascadeSession(document.Session)
+		if err == nil {
+			err = purger.remove("exercise_sessions", document.ID)
+		}
+
+		if err != nil {
+			return i, fmt.Errorf("failed to purge session %s: %w", document.Session.ID.Hex(), err)
+		}
+	}
+
+	return len(documents), nil
+}
+
+func (purger Purger) purgeLocations(now time.Time) (int, error) {
+	documents, err := expired[model.DeletedLocation](purger, "location", now)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, document := range documents {
+		err = purger.cascadeLocation(document.Location)
+		if err == nil {
+			err = purger.remove("location", document.ID)
+		}
+
+		if err != nil {
+			return i, fmt.Errorf("failed to purge location %s: %w", document.Location.ID.Hex(), err)
+		}
+	}
+
+	return len(documents), nil
+}
+
+func (purger Purger) purgeFiles(now time.Time) (int, error) {
+	documents, err := expired[model.DeletedFile](purger, "file", now)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, document := range documents {
+		purger.deleteObjects(document.File.Key)
+
+		err = purger.remove("file", document.ID)
+		if err != nil {
+			return i, fmt.Errorf("failed to purge file %s: %w", document.File.ID.Hex(), err)
+		}
+	}
+
+	return len(documents), nil
+}
+
+func (purger Purger) purgeExportJobs(now time.Time) (int, error) {
+	documents, err := expired[model.DeletedExportJob](purger, "export_job", now)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, document := range documents {
+		purger.deleteObjects(document.Job.Key)
+
+		err = purger.remove("export_job", document.ID)
+		if err != nil {
+			return i, fmt.Errorf("failed to purge export job %s: %w", document.Job.ID.Hex(), err)
+		}
+	}
+
+	return len(documents), nil
+}
+
+// purgeAll removes the expired documents of a _deleted collection that
+// nothing else depends on
+func (purger Purger) purgeAll(collectionName string) func(now time.Time) (int, error) {
+	return func(now time.Time) (int, error) {
+		result, err := database.DeleteManyByFilter(purger.params(collectionName+"_deleted"), bson.M{
+			"removalAt": bson.M{"$lte": now},
+		})
+
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete expired %s: %w", collectionName, err)
+		}
+
+		return int(result.DeletedCount), nil
+	}
+}
+
+// Run purges every _deleted collection. A failing collection does not stop
+// the others from being purged, the first failure is returned
+func (purger Purger) Run(now time.Time) error {
+	steps := []struct {
+		collectionName string
+		purge          func(now time.Time) (int, error)
+	}{
+		{"account", purger.purgeAccounts},
+		{"post", purger.purgePosts},
+		{"comment", purger.purgeComments},
+		{"exercise_sessions", purger.purgeSessions},
+		{"location", purger.purgeLocations},
+		{"blog", purger.purgeAll("blog")},
+		{"workout_template", purger.purgeAll("workout_template")},
+		{"program", purger.purgeAll("program")},
+		{"file", purger.purgeFiles},
+		{"export_job", purger.purgeExportJobs},
+		{"like", purger.purgeAll("like")},
+		{"follow", purger.purgeAll("follow")},
+		{"route", purger.purgeAll("route")},
+		{"personal_record", purger.purgeAll("personal_record")},
+		{"program_enrollment", purger.purgeAll("program_enrollment")},
+		{"coach_link", purger.purgeAll("coach_link")},
+		{"session_comment", purger.purgeAll("session_comment")},
+		{"food", purger.purgeAll("food")},
+		{"diet_entry", purger.purgeAll("diet_entry")},
+		{"nutrition_target", purger.purgeAll("nutrition_target")},
+		{"recipe", purger.purgeAll("recipe")},
+		{"meal_plan", purger.purgeAll("meal_plan")},
+		{"body_measurement", purger.purgeAll("body_measurement")},
+		{"notification", purger.purgeAll("notification")},
+		{"device_token", purger.purgeAll("device_token")},
+		{"message", purger.purgeAll("message")},
+		{"block", purger.purgeAll("block")},
+	}
+
+	var firstErr error
+
+	for _, step := range steps {
+		purged, err := step.purge(now)
+		if purged > 0 {
+			fmt.Printf("purged %d deleted %s documents\n", purged, step.collectionName)
+		}
+
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+				continue
+			}
+
+			fmt.Println("failed to purge deleted "+step.collectionName+" documents: ", err)
+		}
+	}
+
+	return firstErr
+}
+
+// Task returns the purge as a worker task run on the provided interval
+func (purger Purger) Task(interval time.Duration) Task {
+	return Task{
+		Name:     "purge_deleted",
+		Interval: interval,
+		Run:      purger.Run,
+	}
+}
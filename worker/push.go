@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"ares/database"
+	"ares/push"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PushDelivery drains the push queue, sending the queued messages in batches
+// through their provider. Tokens the provider no longer recognises are
+// removed and messages that failed for a transient reason are retried with a
+// growing delay until they run out of attempts
+type PushDelivery struct {
+	Queue        push.Queue
+	Providers    map[string]push.Provider
+	MongoClient  *mongo.Client
+	DatabaseName string
+	BatchSize    int
+	MaxAttempts  int
+}
+
+// backoff returns the delay before the next attempt of a job, doubling from
+// 30 seconds up to an hour
+func backoff(attempts int) time.Duration {
+	delay := 30 * time.Second
+
+	for i := 1; i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+
+	return delay
+}
+
+// deliver sends a batch of jobs bound for a single provider, returning the
+// tokens that should be removed
+func (delivery PushDelivery) deliver(provider push.Provider, jobs []push.Job, now time.Time) []string {
+	messages := make([]push.Message, len(jobs))
+	for i, job := range jobs {
+		messages[i] = job.Message
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	results := provider.Send(ctx, messages)
+
+	var invalid []string
+
+	for i, result := range results {
+		if result.Err == nil {
+			continue
+		}
+
+		if result.Invalid {
+			invalid = append(invalid, result.Token)
+			continue
+		}
+
+		job := jobs[i]
+		job.Attempts++
+
+		if !result.Retry || job.Attempts >= delivery.MaxAttempts {
+			fmt.Println("failed to deliver push with "+provider.Name()+": ", result.Err)
+			continue
+		}
+
+		err := delivery.Queue.Retry(job, now.Add(backoff(job.Attempts)))
+		if err != nil {
+			fmt.Println("failed to schedule push retry: ", err)
+		}
+	}
+
+	return invalid
+}
+
+// Run delivers every message on the queue
+func (delivery PushDelivery) Run(now time.Time) error {
+	_, err := delivery.Queue.PromoteDue(now)
+	if err != nil {
+		return fmt.Errorf("failed to promote push retries: %w", err)
+	}
+
+	for {
+		jobs, err := delivery.Queue.Pop(delivery.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to pop push jobs: %w", err)
+		}
+
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		byProvider := map[string][]push.Job{}
+		for _, job := range jobs {
+			byProvider[job.Message.Provider] = append(byProvider[job.Message.Provider], job)
+		}
+
+		var invalid []string
+
+		for name, batch := range byProvider {
+			provider, ok := delivery.Providers[name]
+			if !ok {
+				fmt.Printf("dropped %d pushes for unconfigured provider %s\n", len(batch), name)
+				continue
+			}
+
+			invalid = append(invalid, delivery.deliver(provider, batch, now)...)
+		}
+
+		if len(invalid) > 0 {
+			_, err = database.DeleteManyByFilter(database.QueryParams{
+				MongoClient:    delivery.MongoClient,
+				DatabaseName:   delivery.DatabaseName,
+				CollectionName: "device_token",
+			}, bson.M{"token": bson.M{"$in": invalid}})
+
+			if err != nil {
+				return fmt.Errorf("failed to prune device tokens: %w", err)
+			}
+		}
+
+		if len(jobs) < delivery.BatchSize {
+			return nil
+		}
+	}
+}
+
+// Task returns the push delivery as a worker task run on the provided interval
+func (delivery PushDelivery) Task(interval time.Duration) Task {
+	return Task{
+		Name:     "push_delivery",
+		Interval: interval,
+		Run:      delivery.Run,
+	}
+}
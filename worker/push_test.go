@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"ares/push"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		delay    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+	}
+
+	for _, test := range tests {
+		if delay := backoff(test.attempts); delay != test.delay {
+			t.Errorf("backoff(%d) = %s, want %s", test.attempts, delay, test.delay)
+		}
+	}
+}
+
+func TestDeliverReturnsInvalidTokens(t *testing.T) {
+	provider := push.NewFake(push.FCM, true, false)
+	provider.Invalidate("unregistered")
+
+	delivery := PushDelivery{MaxAttempts: 5}
+
+	jobs := []push.Job{
+		{ID: "1", Message: push.Message{Token: "registered", Provider: push.FCM, Title: "New like"}},
+		{ID: "2", Message: push.Message{Token: "unregistered", Provider: push.FCM, Title: "New like"}},
+	}
+
+	invalid := delivery.deliver(provider, jobs, time.Now())
+
+	if len(invalid) != 1 || invalid[0] != "unregistered" {
+		t.Errorf("invalid tokens = %v, want [unregistered]", invalid)
+	}
+
+	sent := provider.Sent()
+	if len(sent) != 1 || sent[0].Token != "registered" {
+		t.Errorf("sent = %v, want the message to the registered token", sent)
+	}
+}
+
+func TestFakeOnlyRecordsWhenAsked(t *testing.T) {
+	provider := push.NewFake(push.APNS, false, false)
+
+	results := provider.Send(nil, []push.Message{{Token: "registered", Provider: push.APNS}})
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("results = %v, want a single successful result", results)
+	}
+
+	if sent := provider.Sent(); len(sent) != 0 {
+		t.Errorf("sent = %v, want nothing recorded", sent)
+	}
+}
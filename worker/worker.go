@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"fmt"
+	"time"
+)
+
+// Task is a unit of background work run on a fixed interval
+type Task struct {
+	Name     string
+	Interval time.Duration
+	Run      func(now time.Time) error
+}
+
+// Start runs every task once and then on its interval, each in its own
+// goroutine. Failures are logged and the task is tried again on its next run
+func Start(tasks ...Task) {
+	for _, task := range tasks {
+		go schedule(task)
+	}
+}
+
+func schedule(task Task) {
+	ticker := time.NewTicker(task.Interval)
+	defer ticker.Stop()
+
+	run(task, time.Now())
+
+	for now := range ticker.C {
+		run(task, now)
+	}
+}
+
+// run executes a task, recovering from a panic so a single bad run does not
+// take the server down with it
+func run(task Task, now time.Time) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			fmt.Println("worker task "+task.Name+" panicked: ", recovered)
+		}
+	}()
+
+	err := task.Run(now)
+	if err != nil {
+		fmt.Println("worker task "+task.Name+" failed: ", err)
+	}
+}